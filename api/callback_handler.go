@@ -0,0 +1,45 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/phgermanov/notification-service/internal/channel"
+	"github.com/phgermanov/notification-service/internal/notification"
+)
+
+// DeliveryCallbackHandler handles the delivery status callbacks sent by the provider of a channel.
+func (h Handler) DeliveryCallbackHandler(c *gin.Context) {
+	channelName := c.Param("channel")
+	callbacks, err := h.notifier.CallbackHandler(channelName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Let the channel verify the callback was sent by its provider.
+	report, err := callbacks.HandleCallback(c.Request)
+	if errors.Is(err, channel.ErrInvalidCallbackSignature) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.notifier.RecordDelivery(channelName, report)
+	if errors.Is(err, notification.ErrNotificationNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("%s message %s of notification %s: %s", channelName, report.MessageID, report.NotificationID, report.Status)
+
+	c.Status(http.StatusNoContent)
+}
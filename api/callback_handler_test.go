@@ -0,0 +1,104 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+	"github.com/phgermanov/notification-service/internal/channel/channelfakes"
+	"github.com/phgermanov/notification-service/internal/notification"
+	"github.com/stretchr/testify/assert"
+)
+
+// callbackSender is a sender whose provider reports deliveries by callback.
+type callbackSender struct {
+	*channelfakes.FakeSender
+	*channelfakes.FakeCallbackHandler
+}
+
+// TestDeliveryCallbackHandler is a unit test for the DeliveryCallbackHandler function.
+func TestDeliveryCallbackHandler(t *testing.T) {
+	// Define test cases with callbacks and expected HTTP response statuses.
+	tests := []struct {
+		name             string
+		path             string
+		report           channel.DeliveryReport
+		callbackErr      error
+		expectedStatus   int
+		expectedRecorded bool
+	}{
+		{
+			name:             "Valid callback",
+			path:             "/callbacks/SMS",
+			report:           channel.DeliveryReport{MessageID: "SM1", Status: "delivered", Final: true},
+			expectedStatus:   http.StatusNoContent,
+			expectedRecorded: true,
+		},
+		{
+			name:           "Channel without callbacks",
+			path:           "/callbacks/Slack",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Unknown channel",
+			path:           "/callbacks/Unknown",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid signature",
+			path:           "/callbacks/SMS",
+			callbackErr:    channel.ErrInvalidCallbackSignature,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Malformed callback",
+			path:           "/callbacks/SMS",
+			callbackErr:    errors.New("invalid callback: missing MessageSid"),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Unknown notification",
+			path:           "/callbacks/SMS",
+			report:         channel.DeliveryReport{NotificationID: "unknown", MessageID: "SM1", Status: "delivered"},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	// Iterate through the test cases and run each test.
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a notifier with a channel taking callbacks and a notification sent on it.
+			notifier := notification.NewNotifier(0)
+			sms := callbackSender{new(channelfakes.FakeSender), new(channelfakes.FakeCallbackHandler)}
+			sms.GetNameReturns("SMS")
+			slack := new(channelfakes.FakeSender)
+			slack.GetNameReturns("Slack")
+			_ = notifier.AddChannelSender(sms)
+			_ = notifier.AddChannelSender(slack)
+			id, err := notifier.EnqueueNotifications([]notification.Notification{{Channel: "SMS", Message: channel.Message{Body: "Hello"}}})
+			assert.NoError(t, err)
+
+			if tt.report.NotificationID == "" {
+				tt.report.NotificationID = id
+			}
+			sms.HandleCallbackReturns(tt.report, tt.callbackErr)
+
+			// Send the callback to the handler.
+			req, err := http.NewRequest("POST", tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rr := httptest.NewRecorder()
+			router := SetupRouter(NewHandler(notifier))
+			router.ServeHTTP(rr, req)
+
+			// Assert the response status and the recorded delivery.
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			status, err := notifier.GetStatus(id)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedRecorded, len(status.Channels[0].Deliveries) == 1)
+		})
+	}
+}
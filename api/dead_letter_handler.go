@@ -0,0 +1,127 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/phgermanov/notification-service/internal/notification"
+)
+
+// ReplayDeadLettersRequest selects dead letters to replay and optionally a different
+// channel and a new deadline. Without a deadline the notifications are sent without one.
+type ReplayDeadLettersRequest struct {
+	IDs      []string   `json:"ids"`
+	Channel  string     `json:"channel"`
+	Deadline *time.Time `json:"deadline"`
+}
+
+// deadline returns the requested deadline, or zero without one.
+func (r ReplayDeadLettersRequest) deadline() time.Time {
+	if r.Deadline == nil {
+		return time.Time{}
+	}
+	return *r.Deadline
+}
+
+// ListDeadLettersHandler handles the HTTP request for listing dead letters.
+func (h Handler) ListDeadLettersHandler(c *gin.Context) {
+	letters, err := h.notifier.ListDeadLetters()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Respond with the list of dead letters in JSON format.
+	c.JSON(http.StatusOK, gin.H{"dead_letters": letters})
+}
+
+// GetDeadLetterHandler handles the HTTP request for inspecting a single dead letter.
+func (h Handler) GetDeadLetterHandler(c *gin.Context) {
+	letter, err := h.notifier.GetDeadLetter(c.Param("id"))
+	if err != nil {
+		respondDeadLetterError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, letter)
+}
+
+// DeleteDeadLetterHandler handles the HTTP request for discarding a single dead letter.
+func (h Handler) DeleteDeadLetterHandler(c *gin.Context) {
+	if err := h.notifier.DeleteDeadLetter(c.Param("id")); err != nil {
+		respondDeadLetterError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// PurgeDeadLettersHandler handles the HTTP request for discarding all dead letters.
+func (h Handler) PurgeDeadLettersHandler(c *gin.Context) {
+	count, err := h.notifier.PurgeDeadLetters()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"purged": count})
+}
+
+// ReplayDeadLetterHandler handles the HTTP request for replaying a single dead letter.
+// The optional JSON body may name a different channel to send the notification to.
+func (h Handler) ReplayDeadLetterHandler(c *gin.Context) {
+	var input ReplayDeadLettersRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if input.Deadline != nil && !input.Deadline.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "deadline must be in the future"})
+		return
+	}
+
+	replayed, err := h.notifier.ReplayDeadLetters([]string{c.Param("id")}, input.Channel, input.deadline())
+	if err != nil {
+		respondDeadLetterError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"replayed": replayed})
+}
+
+// ReplayDeadLettersHandler handles the HTTP request for replaying dead letters in bulk.
+// Without IDs in the JSON body all dead letters are replayed.
+func (h Handler) ReplayDeadLettersHandler(c *gin.Context) {
+	var input ReplayDeadLettersRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if input.Deadline != nil && !input.Deadline.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "deadline must be in the future"})
+		return
+	}
+
+	replayed, err := h.notifier.ReplayDeadLetters(input.IDs, input.Channel, input.deadline())
+	if err != nil {
+		respondDeadLetterError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"replayed": replayed})
+}
+
+// respondDeadLetterError maps dead letter errors to HTTP responses.
+func respondDeadLetterError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, notification.ErrDeadLetterNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, notification.ErrChannelNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/phgermanov/notification-service/internal/channel/channelfakes"
+	"github.com/phgermanov/notification-service/internal/notification"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDeadLetterHandlers is a unit test for the dead letter handlers.
+func TestDeadLetterHandlers(t *testing.T) {
+	// Define test cases with requests and expected HTTP response statuses.
+	passed := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           any
+		expectedStatus int
+		expectedLeft   int
+	}{
+		{
+			name:           "List dead letters",
+			method:         "GET",
+			path:           "/dead-letters",
+			expectedStatus: http.StatusOK,
+			expectedLeft:   2,
+		},
+		{
+			name:           "Get dead letter",
+			method:         "GET",
+			path:           "/dead-letters/1",
+			expectedStatus: http.StatusOK,
+			expectedLeft:   2,
+		},
+		{
+			name:           "Get unknown dead letter",
+			method:         "GET",
+			path:           "/dead-letters/3",
+			expectedStatus: http.StatusNotFound,
+			expectedLeft:   2,
+		},
+		{
+			name:           "Delete dead letter",
+			method:         "DELETE",
+			path:           "/dead-letters/1",
+			expectedStatus: http.StatusNoContent,
+			expectedLeft:   1,
+		},
+		{
+			name:           "Purge dead letters",
+			method:         "DELETE",
+			path:           "/dead-letters",
+			expectedStatus: http.StatusOK,
+			expectedLeft:   0,
+		},
+		{
+			name:           "Replay dead letter",
+			method:         "POST",
+			path:           "/dead-letters/1/replay",
+			expectedStatus: http.StatusOK,
+			expectedLeft:   1,
+		},
+		{
+			name:           "Replay dead letter to another channel",
+			method:         "POST",
+			path:           "/dead-letters/1/replay",
+			body:           ReplayDeadLettersRequest{Channel: "channel2"},
+			expectedStatus: http.StatusOK,
+			expectedLeft:   1,
+		},
+		{
+			name:           "Replay dead letter to unknown channel",
+			method:         "POST",
+			path:           "/dead-letters/1/replay",
+			body:           ReplayDeadLettersRequest{Channel: "unknown"},
+			expectedStatus: http.StatusBadRequest,
+			expectedLeft:   2,
+		},
+		{
+			name:           "Replay dead letter with a passed deadline",
+			method:         "POST",
+			path:           "/dead-letters/1/replay",
+			body:           ReplayDeadLettersRequest{Deadline: &passed},
+			expectedStatus: http.StatusBadRequest,
+			expectedLeft:   2,
+		},
+		{
+			name:           "Replay all dead letters",
+			method:         "POST",
+			path:           "/dead-letters/replay",
+			expectedStatus: http.StatusOK,
+			expectedLeft:   0,
+		},
+		{
+			name:           "Replay selected dead letters",
+			method:         "POST",
+			path:           "/dead-letters/replay",
+			body:           ReplayDeadLettersRequest{IDs: []string{"2"}},
+			expectedStatus: http.StatusOK,
+			expectedLeft:   1,
+		},
+	}
+
+	// Iterate through the test cases and run each test.
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a notifier holding two dead letters.
+			store := notification.NewMemoryDeadLetterStore()
+			_ = store.Add(notification.DeadLetter{ID: "1", Notification: notification.Notification{Channel: "channel1"}, FailedAt: time.Now()})
+			_ = store.Add(notification.DeadLetter{ID: "2", Notification: notification.Notification{Channel: "channel1"}, FailedAt: time.Now()})
+			notifier := notification.NewNotifier(0, notification.WithDeadLetterStore(store))
+			for _, name := range []string{"channel1", "channel2"} {
+				sender := new(channelfakes.FakeSender)
+				sender.GetNameReturns(name)
+				_ = notifier.AddChannelSender(sender)
+			}
+
+			// Create a new HTTP request with the optional JSON body.
+			var body []byte
+			if tt.body != nil {
+				body, _ = json.Marshal(tt.body)
+			}
+			req, err := http.NewRequest(tt.method, tt.path, bytes.NewBuffer(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Create a new recorder to capture the HTTP response.
+			rr := httptest.NewRecorder()
+
+			// Set up the router and send the HTTP request to the handler.
+			router := SetupRouter(NewHandler(notifier))
+			router.ServeHTTP(rr, req)
+
+			// Assert the response status and the remaining dead letters.
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			letters, err := notifier.ListDeadLetters()
+			assert.NoError(t, err)
+			assert.Len(t, letters, tt.expectedLeft)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/phgermanov/notification-service/internal/channel"
+)
+
+// Default and largest number of inbox items returned at once.
+const (
+	defaultInboxLimit = 20
+	maxInboxLimit     = 100
+)
+
+// inboxKeepAlive is how often an idle stream sends a comment, so that proxies do not
+// close the connection.
+var inboxKeepAlive = 30 * time.Second
+
+// ListInboxHandler handles the HTTP request for listing a user's in-app inbox. The
+// unread, archived, limit and offset query parameters select the page.
+func (h Handler) ListInboxHandler(c *gin.Context) {
+	filter, err := inboxFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.notifier.ListInbox(c.Param("user"), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// MarkInboxItemReadHandler handles the HTTP request for marking an inbox item as read.
+func (h Handler) MarkInboxItemReadHandler(c *gin.Context) {
+	item, err := h.notifier.MarkInboxItemRead(c.Param("user"), c.Param("id"), true)
+	respondInboxItem(c, item, err)
+}
+
+// MarkInboxItemUnreadHandler handles the HTTP request for marking an inbox item as unread.
+func (h Handler) MarkInboxItemUnreadHandler(c *gin.Context) {
+	item, err := h.notifier.MarkInboxItemRead(c.Param("user"), c.Param("id"), false)
+	respondInboxItem(c, item, err)
+}
+
+// ArchiveInboxItemHandler handles the HTTP request for archiving an inbox item.
+func (h Handler) ArchiveInboxItemHandler(c *gin.Context) {
+	item, err := h.notifier.ArchiveInboxItem(c.Param("user"), c.Param("id"), true)
+	respondInboxItem(c, item, err)
+}
+
+// UnarchiveInboxItemHandler handles the HTTP request for moving an inbox item back from the archive.
+func (h Handler) UnarchiveInboxItemHandler(c *gin.Context) {
+	item, err := h.notifier.ArchiveInboxItem(c.Param("user"), c.Param("id"), false)
+	respondInboxItem(c, item, err)
+}
+
+// InboxStreamHandler handles the HTTP request for streaming the changes to a user's inbox
+// as Server-Sent Events. Each event is named "created" or "updated" and carries the item.
+func (h Handler) InboxStreamHandler(c *gin.Context) {
+	events, cancel := h.notifier.SubscribeInbox(c.Param("user"))
+	defer cancel()
+
+	// Send the headers right away, so the client knows the stream is open.
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.WriteHeader(http.StatusOK)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(inboxKeepAlive)
+	defer keepAlive.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, open := <-events:
+			if !open {
+				return false
+			}
+			c.SSEvent(event.Type, event.Item)
+			return true
+		case <-keepAlive.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// inboxFilter parses the query parameters of an inbox listing.
+func inboxFilter(c *gin.Context) (channel.InboxFilter, error) {
+	filter := channel.InboxFilter{Limit: defaultInboxLimit}
+	var err error
+	if value := c.Query("unread"); value != "" {
+		if filter.UnreadOnly, err = strconv.ParseBool(value); err != nil {
+			return filter, errors.New("unread must be true or false")
+		}
+	}
+	if value := c.Query("archived"); value != "" {
+		if filter.Archived, err = strconv.ParseBool(value); err != nil {
+			return filter, errors.New("archived must be true or false")
+		}
+	}
+	if value := c.Query("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit < 1 || filter.Limit > maxInboxLimit {
+			return filter, errors.New("limit must be between 1 and " + strconv.Itoa(maxInboxLimit))
+		}
+	}
+	if value := c.Query("offset"); value != "" {
+		if filter.Offset, err = strconv.Atoi(value); err != nil || filter.Offset < 0 {
+			return filter, errors.New("offset must not be negative")
+		}
+	}
+	return filter, nil
+}
+
+// respondInboxItem responds with an updated inbox item or maps its error to an HTTP response.
+func respondInboxItem(c *gin.Context, item channel.InboxItem, err error) {
+	switch {
+	case errors.Is(err, channel.ErrInboxItemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, item)
+	}
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+	"github.com/phgermanov/notification-service/internal/notification"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInboxHandlers is a unit test for the in-app inbox handlers.
+func TestInboxHandlers(t *testing.T) {
+	// Define test cases with requests and expected HTTP responses.
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		expectedIDs    []string // IDs of the listed items.
+		expectedUnread int
+	}{
+		{
+			name:           "List inbox",
+			method:         "GET",
+			path:           "/users/alice/inbox",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"3", "2", "1"},
+			expectedUnread: 3,
+		},
+		{
+			name:           "List page of inbox",
+			method:         "GET",
+			path:           "/users/alice/inbox?limit=1&offset=1",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"2"},
+			expectedUnread: 3,
+		},
+		{
+			name:           "List inbox with invalid limit",
+			method:         "GET",
+			path:           "/users/alice/inbox?limit=1000",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "List inbox with invalid filter",
+			method:         "GET",
+			path:           "/users/alice/inbox?unread=maybe",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Mark item read",
+			method:         "POST",
+			path:           "/users/alice/inbox/2/read",
+			expectedStatus: http.StatusOK,
+			expectedUnread: 2,
+		},
+		{
+			name:           "Mark item unread",
+			method:         "POST",
+			path:           "/users/alice/inbox/2/unread",
+			expectedStatus: http.StatusOK,
+			expectedUnread: 3,
+		},
+		{
+			name:           "Archive item",
+			method:         "POST",
+			path:           "/users/alice/inbox/3/archive",
+			expectedStatus: http.StatusOK,
+			expectedUnread: 2,
+		},
+		{
+			name:           "Mark unknown item read",
+			method:         "POST",
+			path:           "/users/alice/inbox/4/read",
+			expectedStatus: http.StatusNotFound,
+			expectedUnread: 3,
+		},
+	}
+
+	// Iterate through the test cases and run each test.
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a notifier holding three unread items of the user.
+			notifier := notification.NewNotifier(0)
+			for _, id := range []string{"1", "2", "3"} {
+				require.NoError(t, notifier.Inbox().Add(channel.InboxItem{ID: id, UserID: "alice", Message: channel.Message{Body: "Hello"}}))
+			}
+
+			// Create a new HTTP request.
+			req, err := http.NewRequest(tt.method, tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Create a new recorder to capture the HTTP response.
+			rr := httptest.NewRecorder()
+
+			// Set up the router and send the HTTP request to the handler.
+			router := SetupRouter(NewHandler(notifier))
+			router.ServeHTTP(rr, req)
+
+			// Assert the response status, the listed items and the unread count.
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedIDs != nil {
+				var page channel.InboxPage
+				assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+				var ids []string
+				for _, item := range page.Items {
+					ids = append(ids, item.ID)
+				}
+				assert.Equal(t, tt.expectedIDs, ids)
+			}
+			if rr.Code != http.StatusBadRequest {
+				page, err := notifier.ListInbox("alice", channel.InboxFilter{})
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedUnread, page.Unread)
+			}
+		})
+	}
+}
+
+// TestInboxStreamHandler is a unit test for streaming inbox changes as Server-Sent Events.
+func TestInboxStreamHandler(t *testing.T) {
+	inboxKeepAlive = 50 * time.Millisecond
+	defer func() { inboxKeepAlive = 30 * time.Second }()
+
+	notifier := notification.NewNotifier(0)
+	server := httptest.NewServer(SetupRouter(NewHandler(notifier)))
+	defer server.Close()
+
+	// Open the stream of the user.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/users/alice/stream", nil)
+	require.NoError(t, err)
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// Send a notification to the user and another user through the InApp channel.
+	inApp := channel.NewInApp(notifier.Inbox())
+	ctxWithID := channel.WithNotificationID(context.Background(), "n-1")
+	require.NoError(t, inApp.Send(ctxWithID, channel.Message{Body: "Hello", Recipients: []string{"bob", "alice"}}))
+
+	// The stream carries the new item, then keep-alive comments.
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 4 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	assert.Equal(t, "event:created", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], `data:{"id":"n-1","user_id":"alice"`))
+	assert.Equal(t, ": keep-alive", lines[2])
+
+	// Closing the streams ends the response.
+	notifier.CloseInboxStreams()
+	for {
+		if _, err := reader.ReadString('\n'); err != nil {
+			break
+		}
+	}
+}
@@ -3,8 +3,11 @@ package api
 import (
 	"errors"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/phgermanov/notification-service/internal/channel"
 	"github.com/phgermanov/notification-service/internal/notification"
 )
 
@@ -15,8 +18,12 @@ var (
 
 // Notification represents a message to be sent to multiple channels.
 type SendNotificationRequest struct {
-	Channels []string `json:"channels" binding:"required"`
-	Message  string   `json:"message" binding:"required"`
+	Channels   []string        `json:"channels" binding:"required"`
+	Message    channel.Message `json:"message"`    // Either a plain string or a structured message.
+	Template   string          `json:"template"`   // Name of a template to render instead of Message.
+	Data       map[string]any  `json:"data"`       // Data the template is rendered with.
+	Recipients []string        `json:"recipients"` // Optional targets overriding those configured for the channels.
+	Deadline   *time.Time      `json:"deadline"`
 }
 
 // SendNotificationHandler handles the HTTP request for sending notifications.
@@ -30,18 +37,79 @@ func (h Handler) SendNotificationHandler(c *gin.Context) {
 		return
 	}
 
+	// Require either a message or a template, rejecting messages with malformed fields.
+	// The fields of templated messages are checked once rendered when they are enqueued.
+	if input.Template != "" {
+		if err := input.Message.Validate(); !errors.Is(err, channel.ErrEmptyMessage) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "message and template are mutually exclusive"})
+			return
+		}
+	} else if err := input.Message.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Reject empty recipients, which would otherwise only fail when sending.
+	for _, recipient := range input.Recipients {
+		if strings.TrimSpace(recipient) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "recipient is empty"})
+			return
+		}
+	}
+
+	// Reject notifications that could never be delivered in time.
+	if input.Deadline != nil && !input.Deadline.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "deadline must be in the future"})
+		return
+	}
+
 	// Enqueue the received notification for processing in the queue.
-	h.notifier.EnqueueNotifications(mapInputToNotification(input))
+	id, err := h.notifier.EnqueueNotifications(mapInputToNotification(input))
+	if errors.Is(err, notification.ErrInvalidNotification) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
 
-	// Respond with a success message indicating that the notification was accepted for processing.
-	c.JSON(http.StatusOK, gin.H{"message": "Notification accepted for processing"})
+	// Respond with the notification ID, which can be used to query its delivery status.
+	c.JSON(http.StatusOK, gin.H{"id": id, "message": "Notification accepted for processing"})
+}
+
+// GetNotificationStatusHandler handles the HTTP request for retrieving the delivery status of a notification.
+func (h Handler) GetNotificationStatusHandler(c *gin.Context) {
+	status, err := h.notifier.GetStatus(c.Param("id"))
+	if errors.Is(err, notification.ErrNotificationNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Respond with the per channel delivery status.
+	c.JSON(http.StatusOK, status)
 }
 
 func mapInputToNotification(input SendNotificationRequest) (notifications []notification.Notification) {
-	for _, channel := range input.Channels {
+	var deadline time.Time
+	if input.Deadline != nil {
+		deadline = *input.Deadline
+	}
+	message := input.Message
+	if len(input.Recipients) > 0 {
+		message.Recipients = append(append([]string(nil), message.Recipients...), input.Recipients...)
+	}
+	for _, channelName := range input.Channels {
 		notifications = append(notifications, notification.Notification{
-			Channel: channel,
-			Message: input.Message,
+			Channel:  channelName,
+			Message:  message,
+			Template: input.Template,
+			Data:     input.Data,
+			Deadline: deadline,
 		})
 	}
 	return
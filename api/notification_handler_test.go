@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/phgermanov/notification-service/internal/channel"
 	"github.com/phgermanov/notification-service/internal/notification"
 	"github.com/stretchr/testify/assert"
 )
@@ -17,21 +19,139 @@ func TestSendNotificationHandler(t *testing.T) {
 	tests := []struct {
 		name           string
 		input          SendNotificationRequest
+		rawBody        string
 		expectedStatus int
 	}{
 		{
 			name: "Valid Notification",
 			input: SendNotificationRequest{
 				Channels: []string{"channel1", "channel2"},
-				Message:  "Test message",
+				Message:  channel.Message{Body: "Test message"},
 			},
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:           "Plain string message",
+			rawBody:        `{"channels": ["channel1"], "message": "Test message"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Structured message",
+			input: SendNotificationRequest{
+				Channels: []string{"channel1"},
+				Message: channel.Message{
+					Title:    "Deploy finished",
+					Markdown: "*done*",
+					Severity: channel.SeverityInfo,
+					Links:    []channel.Link{{Title: "Build", URL: "https://example.com/build"}},
+				},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Unknown severity",
+			input: SendNotificationRequest{
+				Channels: []string{"channel1"},
+				Message:  channel.Message{Body: "Test message", Severity: "panic"},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Resolve without correlation key",
+			input: SendNotificationRequest{
+				Channels: []string{"channel1"},
+				Message:  channel.Message{Body: "Test message", Action: channel.ActionResolve},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Notification with recipients",
+			input: SendNotificationRequest{
+				Channels:   []string{"channel1"},
+				Message:    channel.Message{Body: "Test message"},
+				Recipients: []string{"#alerts", "alice@example.com"},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Empty recipient",
+			input: SendNotificationRequest{
+				Channels:   []string{"channel1"},
+				Message:    channel.Message{Body: "Test message"},
+				Recipients: []string{" "},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Unknown template",
+			input: SendNotificationRequest{
+				Channels: []string{"channel1"},
+				Template: "unknown",
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Message and template",
+			input: SendNotificationRequest{
+				Channels: []string{"channel1"},
+				Message:  channel.Message{Body: "Test message"},
+				Template: "alerts",
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Template resolving an incident",
+			input: SendNotificationRequest{
+				Channels: []string{"channel1"},
+				Message:  channel.Message{Action: channel.ActionResolve, CorrelationKey: "incident-1"},
+				Template: "alerts",
+				Data:     map[string]any{"host": "db-1"},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Template resolving without correlation key",
+			input: SendNotificationRequest{
+				Channels: []string{"channel1"},
+				Message:  channel.Message{Action: channel.ActionResolve},
+				Template: "alerts",
+				Data:     map[string]any{"host": "db-1"},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Template with unknown action",
+			input: SendNotificationRequest{
+				Channels: []string{"channel1"},
+				Message:  channel.Message{Action: "explode"},
+				Template: "alerts",
+				Data:     map[string]any{"host": "db-1"},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Notification with a deadline",
+			input: SendNotificationRequest{
+				Channels: []string{"channel1"},
+				Message:  channel.Message{Body: "Test message"},
+				Deadline: timePtr(time.Now().Add(time.Hour)),
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Deadline in the past",
+			input: SendNotificationRequest{
+				Channels: []string{"channel1"},
+				Message:  channel.Message{Body: "Test message"},
+				Deadline: timePtr(time.Now().Add(-time.Hour)),
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
 		{
 			name: "Invalid Notification",
 			input: SendNotificationRequest{
 				Channels: []string{},
-				Message:  "",
+				Message:  channel.Message{Body: ""},
 			},
 			expectedStatus: http.StatusBadRequest,
 		},
@@ -42,6 +162,9 @@ func TestSendNotificationHandler(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Marshal the input notification into JSON format for the request body.
 			reqBody, _ := json.Marshal(tt.input)
+			if tt.rawBody != "" {
+				reqBody = []byte(tt.rawBody)
+			}
 			req, err := http.NewRequest("POST", "/notifications", bytes.NewBuffer(reqBody))
 			if err != nil {
 				t.Fatal(err)
@@ -50,8 +173,11 @@ func TestSendNotificationHandler(t *testing.T) {
 			// Create a new recorder to capture the HTTP response.
 			rr := httptest.NewRecorder()
 
-			// Create a new instance of the API handler for testing.
-			handler := NewHandler(notification.NewNotifier(0))
+			// Create a new instance of the API handler for testing with a template.
+			notifier := notification.NewNotifier(0)
+			_, err = notifier.CreateTemplate(notification.Template{Name: "alerts", Default: notification.TemplateVariant{Body: "{{.host}} is down"}})
+			assert.NoError(t, err)
+			handler := NewHandler(notifier)
 
 			// Set up the router and send the HTTP request to the handler.
 			router := SetupRouter(handler)
@@ -62,3 +188,61 @@ func TestSendNotificationHandler(t *testing.T) {
 		})
 	}
 }
+
+// TestGetNotificationStatusHandler is a unit test for the GetNotificationStatusHandler function.
+func TestGetNotificationStatusHandler(t *testing.T) {
+	// Create a notifier holding one queued notification.
+	notifier := notification.NewNotifier(0)
+	id, err := notifier.EnqueueNotifications([]notification.Notification{{Channel: "channel1", Message: channel.Message{Body: "Test message"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Define test cases with notification IDs and expected HTTP response statuses.
+	tests := []struct {
+		name           string
+		id             string
+		expectedStatus int
+	}{
+		{
+			name:           "Existing notification",
+			id:             id,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Unknown notification",
+			id:             "unknown",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	// Iterate through the test cases and run each test.
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/notifications/"+tt.id, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Create a new recorder to capture the HTTP response.
+			rr := httptest.NewRecorder()
+
+			// Set up the router and send the HTTP request to the handler.
+			router := SetupRouter(NewHandler(notifier))
+			router.ServeHTTP(rr, req)
+
+			// Assert that the actual HTTP response status matches the expected status.
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var status notification.NotificationStatus
+				assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &status))
+				assert.Equal(t, id, status.ID)
+				assert.Equal(t, notification.StatusQueued, status.Channels[0].Status)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
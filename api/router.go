@@ -8,7 +8,37 @@ func SetupRouter(handler *Handler) *gin.Engine {
 	r := gin.Default()
 
 	r.POST("/notifications", handler.SendNotificationHandler)
+	r.GET("/notifications/:id", handler.GetNotificationStatusHandler)
 	r.GET("/channels", handler.GetChannels)
+	r.POST("/callbacks/:channel", handler.DeliveryCallbackHandler)
+
+	r.GET("/dead-letters", handler.ListDeadLettersHandler)
+	r.DELETE("/dead-letters", handler.PurgeDeadLettersHandler)
+	r.POST("/dead-letters/replay", handler.ReplayDeadLettersHandler)
+	r.GET("/dead-letters/:id", handler.GetDeadLetterHandler)
+	r.DELETE("/dead-letters/:id", handler.DeleteDeadLetterHandler)
+	r.POST("/dead-letters/:id/replay", handler.ReplayDeadLetterHandler)
+
+	r.GET("/templates", handler.ListTemplatesHandler)
+	r.POST("/templates", handler.CreateTemplateHandler)
+	r.GET("/templates/:name", handler.GetTemplateHandler)
+	r.PUT("/templates/:name", handler.UpdateTemplateHandler)
+	r.DELETE("/templates/:name", handler.DeleteTemplateHandler)
+	r.POST("/templates/:name/preview", handler.PreviewTemplateHandler)
+
+	r.GET("/invalid-tokens", handler.ListInvalidTokensHandler)
+	r.DELETE("/invalid-tokens/:channel/:token", handler.RestoreTokenHandler)
+
+	r.GET("/users/:user/push-subscriptions", handler.ListSubscriptionsHandler)
+	r.POST("/users/:user/push-subscriptions", handler.AddSubscriptionHandler)
+	r.DELETE("/users/:user/push-subscriptions", handler.RemoveSubscriptionHandler)
+
+	r.GET("/users/:user/inbox", handler.ListInboxHandler)
+	r.POST("/users/:user/inbox/:id/read", handler.MarkInboxItemReadHandler)
+	r.POST("/users/:user/inbox/:id/unread", handler.MarkInboxItemUnreadHandler)
+	r.POST("/users/:user/inbox/:id/archive", handler.ArchiveInboxItemHandler)
+	r.POST("/users/:user/inbox/:id/unarchive", handler.UnarchiveInboxItemHandler)
+	r.GET("/users/:user/stream", handler.InboxStreamHandler)
 
 	return r
 }
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/phgermanov/notification-service/internal/channel"
+)
+
+// PushSubscriptionRequest is a browser's Web Push subscription, as returned by
+// PushSubscription.toJSON().
+type PushSubscriptionRequest struct {
+	Endpoint string                       `json:"endpoint"`
+	Keys     channel.PushSubscriptionKeys `json:"keys"`
+}
+
+// ListSubscriptionsHandler handles the HTTP request for listing the Web Push subscriptions of a user.
+func (h Handler) ListSubscriptionsHandler(c *gin.Context) {
+	subscriptions, err := h.notifier.ListSubscriptions(c.Param("user"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Respond with the list of subscriptions in JSON format.
+	c.JSON(http.StatusOK, gin.H{"subscriptions": subscriptions})
+}
+
+// AddSubscriptionHandler handles the HTTP request for registering a Web Push subscription
+// of a user. Registering a subscription again replaces it.
+func (h Handler) AddSubscriptionHandler(c *gin.Context) {
+	var input PushSubscriptionRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription, err := h.notifier.AddSubscription(channel.PushSubscription{
+		UserID:   c.Param("user"),
+		Endpoint: input.Endpoint,
+		Keys:     input.Keys,
+	})
+	if err != nil {
+		respondSubscriptionError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, subscription)
+}
+
+// RemoveSubscriptionHandler handles the HTTP request for unregistering a Web Push
+// subscription of a user. The subscription's endpoint is given in the endpoint query parameter.
+func (h Handler) RemoveSubscriptionHandler(c *gin.Context) {
+	endpoint := c.Query("endpoint")
+	if endpoint == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing endpoint"})
+		return
+	}
+	if err := h.notifier.RemoveSubscription(c.Param("user"), endpoint); err != nil {
+		respondSubscriptionError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// respondSubscriptionError maps subscription errors to HTTP responses.
+func respondSubscriptionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, channel.ErrSubscriptionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, channel.ErrInvalidSubscription):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
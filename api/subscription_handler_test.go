@@ -0,0 +1,126 @@
+package api
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+	"github.com/phgermanov/notification-service/internal/notification"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSubscriptionHandlers is a unit test for the Web Push subscription handlers.
+func TestSubscriptionHandlers(t *testing.T) {
+	// Create the keys of a browser.
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keys := channel.PushSubscriptionKeys{
+		P256dh: base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		Auth:   base64.RawURLEncoding.EncodeToString(make([]byte, 16)),
+	}
+	endpoint := "https://push.example.com/send/1"
+
+	// Define test cases with requests and expected HTTP response statuses.
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           any
+		expectedStatus int
+		expectedLeft   int
+	}{
+		{
+			name:           "List subscriptions",
+			method:         "GET",
+			path:           "/users/alice/push-subscriptions",
+			expectedStatus: http.StatusOK,
+			expectedLeft:   1,
+		},
+		{
+			name:           "Add subscription",
+			method:         "POST",
+			path:           "/users/alice/push-subscriptions",
+			body:           PushSubscriptionRequest{Endpoint: "https://push.example.com/send/2", Keys: keys},
+			expectedStatus: http.StatusCreated,
+			expectedLeft:   2,
+		},
+		{
+			name:           "Add subscription again",
+			method:         "POST",
+			path:           "/users/alice/push-subscriptions",
+			body:           PushSubscriptionRequest{Endpoint: endpoint, Keys: keys},
+			expectedStatus: http.StatusCreated,
+			expectedLeft:   1,
+		},
+		{
+			name:           "Add subscription without keys",
+			method:         "POST",
+			path:           "/users/alice/push-subscriptions",
+			body:           PushSubscriptionRequest{Endpoint: "https://push.example.com/send/2"},
+			expectedStatus: http.StatusBadRequest,
+			expectedLeft:   1,
+		},
+		{
+			name:           "Remove subscription",
+			method:         "DELETE",
+			path:           "/users/alice/push-subscriptions?endpoint=" + url.QueryEscape(endpoint),
+			expectedStatus: http.StatusNoContent,
+			expectedLeft:   0,
+		},
+		{
+			name:           "Remove unknown subscription",
+			method:         "DELETE",
+			path:           "/users/alice/push-subscriptions?endpoint=" + url.QueryEscape("https://push.example.com/send/2"),
+			expectedStatus: http.StatusNotFound,
+			expectedLeft:   1,
+		},
+		{
+			name:           "Remove subscription without endpoint",
+			method:         "DELETE",
+			path:           "/users/alice/push-subscriptions",
+			expectedStatus: http.StatusBadRequest,
+			expectedLeft:   1,
+		},
+	}
+
+	// Iterate through the test cases and run each test.
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a notifier holding a subscription of the user.
+			notifier := notification.NewNotifier(0)
+			_, err := notifier.AddSubscription(channel.PushSubscription{UserID: "alice", Endpoint: endpoint, Keys: keys})
+			require.NoError(t, err)
+
+			// Create a new HTTP request with the optional JSON body.
+			var body []byte
+			if tt.body != nil {
+				body, _ = json.Marshal(tt.body)
+			}
+			req, err := http.NewRequest(tt.method, tt.path, bytes.NewBuffer(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Create a new recorder to capture the HTTP response.
+			rr := httptest.NewRecorder()
+
+			// Set up the router and send the HTTP request to the handler.
+			router := SetupRouter(NewHandler(notifier))
+			router.ServeHTTP(rr, req)
+
+			// Assert the response status and the remaining subscriptions.
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			subscriptions, err := notifier.ListSubscriptions("alice")
+			assert.NoError(t, err)
+			assert.Len(t, subscriptions, tt.expectedLeft)
+		})
+	}
+}
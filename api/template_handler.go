@@ -0,0 +1,112 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/phgermanov/notification-service/internal/notification"
+)
+
+// PreviewTemplateRequest holds the channel and data a template is previewed with.
+type PreviewTemplateRequest struct {
+	Channel string         `json:"channel"`
+	Data    map[string]any `json:"data"`
+}
+
+// ListTemplatesHandler handles the HTTP request for listing message templates.
+func (h Handler) ListTemplatesHandler(c *gin.Context) {
+	templates, err := h.notifier.ListTemplates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Respond with the list of templates in JSON format.
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+// GetTemplateHandler handles the HTTP request for retrieving a single message template.
+func (h Handler) GetTemplateHandler(c *gin.Context) {
+	template, err := h.notifier.GetTemplate(c.Param("name"))
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// CreateTemplateHandler handles the HTTP request for creating a message template.
+func (h Handler) CreateTemplateHandler(c *gin.Context) {
+	var input notification.Template
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := h.notifier.CreateTemplate(input)
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, template)
+}
+
+// UpdateTemplateHandler handles the HTTP request for replacing a message template.
+// The template name is taken from the path.
+func (h Handler) UpdateTemplateHandler(c *gin.Context) {
+	var input notification.Template
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.Name = c.Param("name")
+
+	template, err := h.notifier.UpdateTemplate(input)
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// DeleteTemplateHandler handles the HTTP request for deleting a message template.
+func (h Handler) DeleteTemplateHandler(c *gin.Context) {
+	if err := h.notifier.DeleteTemplate(c.Param("name")); err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// PreviewTemplateHandler handles the HTTP request for rendering a template without sending it.
+func (h Handler) PreviewTemplateHandler(c *gin.Context) {
+	var input PreviewTemplateRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	message, err := h.notifier.RenderTemplate(c.Param("name"), input.Channel, input.Data)
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, message)
+}
+
+// respondTemplateError maps template errors to HTTP responses.
+func respondTemplateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, notification.ErrTemplateNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, notification.ErrTemplateExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, notification.ErrInvalidTemplate):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/phgermanov/notification-service/internal/notification"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTemplateHandlers is a unit test for the template handlers.
+func TestTemplateHandlers(t *testing.T) {
+	alerts := notification.Template{
+		Name:    "alerts",
+		Default: notification.TemplateVariant{Title: "{{.host}} is down", Body: "{{.host}} stopped responding"},
+	}
+
+	// Define test cases with requests and expected HTTP response statuses.
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           any
+		expectedStatus int
+		expectedCount  int
+	}{
+		{
+			name:           "List templates",
+			method:         "GET",
+			path:           "/templates",
+			expectedStatus: http.StatusOK,
+			expectedCount:  1,
+		},
+		{
+			name:           "Get template",
+			method:         "GET",
+			path:           "/templates/alerts",
+			expectedStatus: http.StatusOK,
+			expectedCount:  1,
+		},
+		{
+			name:           "Get unknown template",
+			method:         "GET",
+			path:           "/templates/unknown",
+			expectedStatus: http.StatusNotFound,
+			expectedCount:  1,
+		},
+		{
+			name:           "Create template",
+			method:         "POST",
+			path:           "/templates",
+			body:           notification.Template{Name: "deploys", Default: notification.TemplateVariant{Body: "{{.service}} deployed"}},
+			expectedStatus: http.StatusCreated,
+			expectedCount:  2,
+		},
+		{
+			name:           "Create existing template",
+			method:         "POST",
+			path:           "/templates",
+			body:           alerts,
+			expectedStatus: http.StatusConflict,
+			expectedCount:  1,
+		},
+		{
+			name:           "Create invalid template",
+			method:         "POST",
+			path:           "/templates",
+			body:           notification.Template{Name: "broken", Default: notification.TemplateVariant{Body: "{{.service"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedCount:  1,
+		},
+		{
+			name:           "Update template",
+			method:         "PUT",
+			path:           "/templates/alerts",
+			body:           notification.Template{Default: notification.TemplateVariant{Body: "{{.host}} is down"}},
+			expectedStatus: http.StatusOK,
+			expectedCount:  1,
+		},
+		{
+			name:           "Update unknown template",
+			method:         "PUT",
+			path:           "/templates/unknown",
+			body:           notification.Template{Default: notification.TemplateVariant{Body: "text"}},
+			expectedStatus: http.StatusNotFound,
+			expectedCount:  1,
+		},
+		{
+			name:           "Delete template",
+			method:         "DELETE",
+			path:           "/templates/alerts",
+			expectedStatus: http.StatusNoContent,
+			expectedCount:  0,
+		},
+		{
+			name:           "Preview template",
+			method:         "POST",
+			path:           "/templates/alerts/preview",
+			body:           PreviewTemplateRequest{Channel: "Slack", Data: map[string]any{"host": "db-1"}},
+			expectedStatus: http.StatusOK,
+			expectedCount:  1,
+		},
+		{
+			name:           "Preview template with missing data",
+			method:         "POST",
+			path:           "/templates/alerts/preview",
+			body:           PreviewTemplateRequest{Channel: "Slack"},
+			expectedStatus: http.StatusBadRequest,
+			expectedCount:  1,
+		},
+	}
+
+	// Iterate through the test cases and run each test.
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a notifier holding one template.
+			notifier := notification.NewNotifier(0)
+			if _, err := notifier.CreateTemplate(alerts); err != nil {
+				t.Fatal(err)
+			}
+
+			// Create a new HTTP request with the optional JSON body.
+			var body []byte
+			if tt.body != nil {
+				body, _ = json.Marshal(tt.body)
+			}
+			req, err := http.NewRequest(tt.method, tt.path, bytes.NewBuffer(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Create a new recorder to capture the HTTP response.
+			rr := httptest.NewRecorder()
+
+			// Set up the router and send the HTTP request to the handler.
+			router := SetupRouter(NewHandler(notifier))
+			router.ServeHTTP(rr, req)
+
+			// Assert the response status and the number of templates.
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			templates, err := notifier.ListTemplates()
+			assert.NoError(t, err)
+			assert.Len(t, templates, tt.expectedCount)
+		})
+	}
+}
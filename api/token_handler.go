@@ -0,0 +1,35 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/phgermanov/notification-service/internal/notification"
+)
+
+// ListInvalidTokensHandler handles the HTTP request for listing the device tokens push
+// providers reported as no longer valid, for example to remove them from user profiles.
+func (h Handler) ListInvalidTokensHandler(c *gin.Context) {
+	tokens, err := h.notifier.ListInvalidTokens()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Respond with the list of invalid tokens in JSON format.
+	c.JSON(http.StatusOK, gin.H{"invalid_tokens": tokens})
+}
+
+// RestoreTokenHandler handles the HTTP request for sending to an invalidated device token again.
+func (h Handler) RestoreTokenHandler(c *gin.Context) {
+	err := h.notifier.RestoreToken(c.Param("channel"), c.Param("token"))
+	switch {
+	case errors.Is(err, notification.ErrTokenNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
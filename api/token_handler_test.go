@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+	"github.com/phgermanov/notification-service/internal/notification"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTokenHandlers is a unit test for the invalid device token handlers.
+func TestTokenHandlers(t *testing.T) {
+	// Define test cases with requests and expected HTTP response statuses.
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		expectedLeft   int
+	}{
+		{
+			name:           "List invalid tokens",
+			method:         "GET",
+			path:           "/invalid-tokens",
+			expectedStatus: http.StatusOK,
+			expectedLeft:   1,
+		},
+		{
+			name:           "Restore invalid token",
+			method:         "DELETE",
+			path:           "/invalid-tokens/FCM/device-1",
+			expectedStatus: http.StatusNoContent,
+			expectedLeft:   0,
+		},
+		{
+			name:           "Restore unknown token",
+			method:         "DELETE",
+			path:           "/invalid-tokens/FCM/device-2",
+			expectedStatus: http.StatusNotFound,
+			expectedLeft:   1,
+		},
+	}
+
+	// Iterate through the test cases and run each test.
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a notifier holding an invalidated token.
+			store := channel.NewMemoryTokenStore()
+			_ = store.Invalidate(channel.InvalidToken{Channel: "FCM", Token: "device-1", Reason: "UNREGISTERED"})
+			notifier := notification.NewNotifier(0, notification.WithTokenStore(store))
+
+			// Create a new HTTP request.
+			req, err := http.NewRequest(tt.method, tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Create a new recorder to capture the HTTP response.
+			rr := httptest.NewRecorder()
+
+			// Set up the router and send the HTTP request to the handler.
+			router := SetupRouter(NewHandler(notifier))
+			router.ServeHTTP(rr, req)
+
+			// Assert the response status and the remaining invalid tokens.
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			tokens, err := notifier.ListInvalidTokens()
+			assert.NoError(t, err)
+			assert.Len(t, tokens, tt.expectedLeft)
+		})
+	}
+}
@@ -1,8 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/phgermanov/notification-service/api"
 	"github.com/phgermanov/notification-service/config"
@@ -18,43 +24,180 @@ func main() {
 	notifier := initializeNotifier(appConfig)
 
 	// Initialize the API service
-	initializeAPI(notifier, appConfig)
+	server := initializeAPI(notifier, appConfig)
+
+	// Serve requests until the process receives SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v\n", err)
+		}
+	}()
+	<-ctx.Done()
+
+	// Shut down gracefully
+	shutdown(server, notifier, appConfig.ShutdownTimeout)
+}
+
+// shutdown stops the API server and drains the notifier within the given timeout
+func shutdown(server *http.Server, notifier *notification.Notifier, timeout time.Duration) {
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	log.Printf("shutting down, waiting up to %v for in-flight notifications", timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Stop accepting requests first so no new notifications are enqueued
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("error shutting down server: %v", err)
+	}
+	if err := notifier.Shutdown(ctx); err != nil {
+		log.Printf("error shutting down notifier: %v", err)
+	}
+	log.Println("shutdown complete")
 }
 
 // initializeNotifier sets up the notification service
 func initializeNotifier(config config.Settings) *notification.Notifier {
+	// Create the queue backend holding notifications waiting to be processed
+	options, err := initializeQueue(config)
+	if err != nil {
+		log.Fatalf("error initializing queue: %v", err)
+	}
+
+	// Add the configured retry policies
+	options = append(options, initializeRetryPolicies(config)...)
+
+	// Add the configured send timeouts
+	options = append(options, initializeSendTimeouts(config)...)
+
 	// Create a new notifier instance with the configured retry duration
-	notifier := notification.NewNotifier(config.RetryDuration)
+	notifier := notification.NewNotifier(config.RetryDuration, options...)
+
+	// Add the channels configured by the flat settings, including the InApp channel
+	// storing notifications in the users' inboxes, and the named channel instances
+	// configured in CHANNELS
+	addChannels(notifier, append(config.LegacyChannels(), config.Channels...))
 
 	// Start a specified number of worker goroutines for processing notifications
 	notifier.StartWorkers(5)
 
-	// Add a Slack channel sender to the notifier
-	err := notifier.AddChannelSender(channel.NewSlack(config.SlackWebhookURL, http.DefaultClient))
-	if err != nil {
-		log.Printf("error adding Slack channel: %v", err)
+	return notifier
+}
+
+// addChannels adds a channel sender for each named channel instance, built by the
+// channel registry from its type and settings
+func addChannels(notifier *notification.Notifier, channels []config.ChannelSettings) {
+	registry := channel.NewRegistry()
+	deps := channel.Dependencies{
+		Client:        http.DefaultClient,
+		Tokens:        notifier.TokenStore(),
+		Subscriptions: notifier.SubscriptionStore(),
+		Inbox:         notifier.Inbox(),
+	}
+	for _, instance := range channels {
+		sender, err := registry.Build(instance.Name, instance.Type, instance.Settings, deps)
+		if err == nil {
+			err = notifier.AddChannelSenderWithType(sender, instance.Type)
+		}
+		if err != nil {
+			log.Printf("error adding channel %s: %v", instance.Name, err)
+		}
 	}
+}
 
-	// Add an Email channel sender to the notifier
-	err = notifier.AddChannelSender(channel.NewEmail("example@gmail.com"))
-	if err != nil {
-		log.Printf("error adding Email channel: %v", err)
+// initializeQueue creates the queue, status, dead-letter, template, device token, subscription and inbox backends selected in the configuration
+func initializeQueue(config config.Settings) ([]notification.Option, error) {
+	switch config.QueueBackend {
+	case "", "memory":
+		return []notification.Option{
+			notification.WithQueue(notification.NewMemoryQueue(100)),
+			notification.WithDeadLetterStore(notification.NewMemoryDeadLetterStore()),
+			notification.WithTemplateStore(notification.NewMemoryTemplateStore()),
+			notification.WithTokenStore(channel.NewMemoryTokenStore()),
+			notification.WithSubscriptionStore(channel.NewMemorySubscriptionStore()),
+			notification.WithInboxStore(channel.NewMemoryInboxStore()),
+		}, nil
+	case "bolt":
+		queue, err := notification.NewBoltQueue(config.QueuePath)
+		if err != nil {
+			return nil, err
+		}
+		return []notification.Option{
+			notification.WithQueue(queue),
+			notification.WithStatusStore(queue.Statuses(10000)),
+			notification.WithDeadLetterStore(queue.DeadLetters()),
+			notification.WithTemplateStore(queue.Templates()),
+			notification.WithTokenStore(queue.Tokens()),
+			notification.WithSubscriptionStore(queue.Subscriptions()),
+			notification.WithInboxStore(queue.Inbox()),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown queue backend: %s", config.QueueBackend)
 	}
+}
 
-	return notifier
+// initializeSendTimeouts creates the send timeouts configured per channel
+func initializeSendTimeouts(config config.Settings) []notification.Option {
+	var options []notification.Option
+	if config.SendTimeout > 0 {
+		options = append(options, notification.WithDefaultSendTimeout(config.SendTimeout))
+	}
+	for channelName, timeout := range config.SendTimeouts {
+		options = append(options, notification.WithSendTimeout(channelName, timeout))
+	}
+	return options
 }
 
-// initializeAPI sets up the API service
-func initializeAPI(notifier *notification.Notifier, config config.Settings) {
+// initializeRetryPolicies creates the retry policies configured per channel
+func initializeRetryPolicies(config config.Settings) []notification.Option {
+	var options []notification.Option
+	for channelName, policy := range config.RetryPolicies {
+		// Settings left out fall back to the defaults
+		retryPolicy := notification.DefaultRetryPolicy(config.RetryDuration)
+		if policy.MaxAttempts > 0 {
+			retryPolicy.MaxAttempts = policy.MaxAttempts
+		}
+		if policy.InitialBackoff > 0 {
+			retryPolicy.InitialBackoff = policy.InitialBackoff
+		}
+		if policy.MaxBackoff > 0 {
+			retryPolicy.MaxBackoff = policy.MaxBackoff
+		}
+		if policy.Multiplier > 0 {
+			retryPolicy.Multiplier = policy.Multiplier
+		}
+		if policy.Jitter != nil {
+			retryPolicy.Jitter = *policy.Jitter
+		}
+		retryPolicy.MaxAge = policy.MaxAge
+
+		if channelName == "default" {
+			options = append(options, notification.WithDefaultRetryPolicy(retryPolicy))
+		} else {
+			options = append(options, notification.WithRetryPolicy(channelName, retryPolicy))
+		}
+	}
+	return options
+}
+
+// initializeAPI sets up the API server
+func initializeAPI(notifier *notification.Notifier, config config.Settings) *http.Server {
 	// Create a new API handler using the provided notifier
 	handler := api.NewHandler(notifier)
 
 	// Set up the API router
 	r := api.SetupRouter(handler)
 
-	// Start the API server on the configured port
-	port := config.Port
-	if err := r.Run(":" + port); err != nil {
-		log.Printf("Failed to start server: %v\n", err)
+	// Create the API server on the configured port
+	server := &http.Server{
+		Addr:    ":" + config.Port,
+		Handler: r,
 	}
+
+	// Close the inbox streams on shutdown, as they would otherwise keep their connections open
+	server.RegisterOnShutdown(notifier.CloseInboxStreams)
+	return server
 }
@@ -0,0 +1,138 @@
+package config
+
+// LegacyChannels returns the channels configured by the flat settings, such as
+// SLACK_WEBHOOK_URL, as channel instances named after their type. Each channel is
+// only included when the setting enabling it is set, except InApp, which is always
+// included.
+func (s Settings) LegacyChannels() []ChannelSettings {
+	var channels []ChannelSettings
+	add := func(enabled bool, name, channelType string, settings map[string]any) {
+		if enabled {
+			channels = append(channels, ChannelSettings{Name: name, Type: channelType, Settings: settings})
+		}
+	}
+
+	add(s.SlackWebhookURL != "" || s.SlackBotToken != "", "Slack", "slack", map[string]any{
+		"webhook_url": s.SlackWebhookURL,
+		"bot_token":   s.SlackBotToken,
+		"channel":     s.SlackChannel,
+		"username":    s.SlackUsername,
+		"icon_emoji":  s.SlackIconEmoji,
+		"icon_url":    s.SlackIconURL,
+	})
+	add(s.SMTPHost != "", "Email", "email", map[string]any{
+		"host":        s.SMTPHost,
+		"port":        s.SMTPPort,
+		"username":    s.SMTPUsername,
+		"password":    s.SMTPPassword,
+		"auth_method": s.SMTPAuth,
+		"tls_mode":    s.SMTPTLSMode,
+		"from":        s.EmailFrom,
+		"reply_to":    s.EmailReplyTo,
+		"to":          s.EmailTo,
+		"subject":     s.EmailSubject,
+	})
+	add(true, "InApp", "inapp", nil)
+	add(s.TeamsWebhookURL != "", "Teams", "teams", map[string]any{
+		"webhook_url": s.TeamsWebhookURL,
+	})
+	add(s.DiscordWebhookURL != "", "Discord", "discord", map[string]any{
+		"webhook_url": s.DiscordWebhookURL,
+		"username":    s.DiscordUsername,
+		"avatar_url":  s.DiscordAvatarURL,
+	})
+	add(s.WebhookURL != "", "Webhook", "webhook", map[string]any{
+		"url":           s.WebhookURL,
+		"method":        s.WebhookMethod,
+		"headers":       s.WebhookHeaders,
+		"body_template": s.WebhookBodyTemplate,
+		"secret":        s.WebhookSecret,
+	})
+	add(s.TwilioAccountSID != "", "SMS", "sms", map[string]any{
+		"account_sid":         s.TwilioAccountSID,
+		"auth_token":          s.TwilioAuthToken,
+		"base_url":            s.TwilioBaseURL,
+		"from":                s.SMSFrom,
+		"to":                  s.SMSTo,
+		"max_parts":           s.SMSMaxParts,
+		"status_callback_url": s.SMSStatusCallbackURL,
+	})
+	add(s.FCMCredentialsFile != "", "FCM", "fcm", map[string]any{
+		"credentials_file": s.FCMCredentialsFile,
+		"project_id":       s.FCMProjectID,
+	})
+	add(s.APNsKeyFile != "", "APNs", "apns", map[string]any{
+		"key_file":   s.APNsKeyFile,
+		"key_id":     s.APNsKeyID,
+		"team_id":    s.APNsTeamID,
+		"topic":      s.APNsTopic,
+		"production": s.APNsProduction,
+	})
+	add(s.VAPIDPrivateKey != "", "WebPush", "webpush", map[string]any{
+		"private_key": s.VAPIDPrivateKey,
+		"subject":     s.VAPIDSubject,
+		"ttl":         s.WebPushTTL,
+	})
+	add(s.PagerDutyRoutingKey != "", "PagerDuty", "pagerduty", map[string]any{
+		"routing_key": s.PagerDutyRoutingKey,
+		"source":      s.PagerDutySource,
+	})
+	add(s.OpsgenieAPIKey != "", "Opsgenie", "opsgenie", map[string]any{
+		"api_key":  s.OpsgenieAPIKey,
+		"base_url": s.OpsgenieBaseURL,
+		"source":   s.OpsgenieSource,
+	})
+	add(s.TelegramBotToken != "", "Telegram", "telegram", map[string]any{
+		"bot_token":  s.TelegramBotToken,
+		"chat_ids":   s.TelegramChatIDs,
+		"parse_mode": s.TelegramParseMode,
+	})
+	add(s.MattermostWebhookURL != "" || s.MattermostBotToken != "", "Mattermost", "mattermost", map[string]any{
+		"webhook_url": s.MattermostWebhookURL,
+		"server_url":  s.MattermostServerURL,
+		"bot_token":   s.MattermostBotToken,
+		"channel_id":  s.MattermostChannelID,
+		"username":    s.MattermostUsername,
+		"icon_url":    s.MattermostIconURL,
+	})
+	add(s.MatrixAccessToken != "", "Matrix", "matrix", map[string]any{
+		"homeserver_url": s.MatrixHomeserverURL,
+		"access_token":   s.MatrixAccessToken,
+		"room_id":        s.MatrixRoomID,
+		"notice":         s.MatrixNotice,
+	})
+	add(s.AMQPURL != "", "AMQP", "amqp", map[string]any{
+		"url":         s.AMQPURL,
+		"exchange":    s.AMQPExchange,
+		"routing_key": s.AMQPRoutingKey,
+		"mandatory":   s.AMQPMandatory,
+		"source":      s.EventSource,
+	})
+	add(s.NATSURL != "", "NATS", "nats", map[string]any{
+		"url":        s.NATSURL,
+		"subject":    s.NATSSubject,
+		"jet_stream": s.NATSJetStream,
+		"source":     s.EventSource,
+	})
+	add(len(s.KafkaBrokers) > 0, "Kafka", "kafka", map[string]any{
+		"brokers": s.KafkaBrokers,
+		"topic":   s.KafkaTopic,
+		"source":  s.EventSource,
+	})
+	add(s.FilePath != "", "File", "file", map[string]any{
+		"path":        s.FilePath,
+		"max_size":    s.FileMaxSizeMB << 20,
+		"max_age":     s.FileMaxAge,
+		"max_backups": s.FileMaxBackups,
+		"compress":    s.FileCompress,
+		"source":      s.EventSource,
+	})
+	add(s.SyslogNetwork != "", "Syslog", "syslog", map[string]any{
+		"network":     s.SyslogNetwork,
+		"address":     s.SyslogAddress,
+		"facility":    s.SyslogFacility,
+		"app_name":    s.SyslogAppName,
+		"tls_ca_file": s.SyslogTLSCAFile,
+	})
+	return channels
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettings_LegacyChannels(t *testing.T) {
+	// Without settings only the InApp channel is configured
+	assert.Equal(t, []ChannelSettings{{Name: "InApp", Type: "inapp"}}, Settings{}.LegacyChannels())
+
+	vapidKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	settings := Settings{
+		SlackWebhookURL:      "https://hooks.slack.com/services/T/B/X",
+		SMTPHost:             "smtp.example.com",
+		EmailTo:              []string{"ops@example.com"},
+		TeamsWebhookURL:      "https://example.webhook.office.com/hook",
+		DiscordWebhookURL:    "https://discord.com/api/webhooks/1/abc",
+		WebhookURL:           "https://example.com/hook",
+		WebhookHeaders:       map[string]string{"X-Team": "ops"},
+		TwilioAccountSID:     "AC123",
+		SMSTo:                []string{"+14155550100"},
+		FCMCredentialsFile:   filepath.Join(dir, "missing.json"),
+		APNsKeyFile:          filepath.Join(dir, "missing.p8"),
+		VAPIDPrivateKey:      base64.RawURLEncoding.EncodeToString(vapidKey.Bytes()),
+		VAPIDSubject:         "mailto:ops@example.com",
+		WebPushTTL:           time.Hour,
+		PagerDutyRoutingKey:  "routing-key",
+		OpsgenieAPIKey:       "api-key",
+		TelegramBotToken:     "bot-token",
+		MattermostWebhookURL: "https://mattermost.example.com/hooks/abc",
+		MatrixAccessToken:    "access-token",
+		MatrixNotice:         true,
+		AMQPURL:              "amqp://localhost",
+		NATSURL:              "nats://localhost",
+		NATSJetStream:        true,
+		KafkaBrokers:         []string{"localhost:9092"},
+		FilePath:             filepath.Join(dir, "notifications.log"),
+		FileMaxSizeMB:        1,
+		SyslogNetwork:        "udp",
+		SyslogAddress:        "localhost:514",
+	}
+
+	// Every flat setting is a setting of its channel type
+	registry := channel.NewRegistry()
+	deps := channel.Dependencies{Inbox: channel.NewInbox(channel.NewMemoryInboxStore())}
+	var names []string
+	for _, instance := range settings.LegacyChannels() {
+		names = append(names, instance.Name)
+		sender, err := registry.Build(instance.Name, instance.Type, instance.Settings, deps)
+		switch instance.Type {
+		case "fcm", "apns":
+			// Decoding succeeded, as the key file is read afterwards
+			assert.ErrorIs(t, err, os.ErrNotExist, instance.Name)
+		default:
+			if assert.NoError(t, err, instance.Name) {
+				assert.Equal(t, instance.Name, sender.GetName())
+			}
+			if closer, ok := sender.(io.Closer); ok {
+				closer.Close()
+			}
+		}
+	}
+	assert.Equal(t, []string{
+		"Slack", "Email", "InApp", "Teams", "Discord", "Webhook", "SMS", "FCM", "APNs", "WebPush", "PagerDuty",
+		"Opsgenie", "Telegram", "Mattermost", "Matrix", "AMQP", "NATS", "Kafka", "File", "Syslog",
+	}, names)
+}
@@ -13,6 +13,156 @@ type Settings struct {
 	Port            string        `mapstructure:"PORT"`
 	SlackWebhookURL string        `mapstructure:"SLACK_WEBHOOK_URL"`
 	RetryDuration   time.Duration `mapstructure:"RETRY_DURATION"`
+	ShutdownTimeout time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+
+	// How long a single send may take. SendTimeouts overrides it per channel name.
+	SendTimeout  time.Duration            `mapstructure:"SEND_TIMEOUT"`
+	SendTimeouts map[string]time.Duration `mapstructure:"SEND_TIMEOUTS"`
+
+	// Named channel instances, added next to the channels configured by the settings below.
+	Channels []ChannelSettings `mapstructure:"CHANNELS"`
+
+	// Retry policies keyed by channel name. The "default" entry applies to all other channels.
+	RetryPolicies map[string]RetryPolicy `mapstructure:"RETRY_POLICIES"`
+
+	// Slack Web API settings. When SlackBotToken is set messages are posted with
+	// chat.postMessage to SlackChannel instead of the webhook, enabling threads.
+	SlackBotToken  string `mapstructure:"SLACK_BOT_TOKEN"`
+	SlackChannel   string `mapstructure:"SLACK_CHANNEL"`
+	SlackUsername  string `mapstructure:"SLACK_USERNAME"`
+	SlackIconEmoji string `mapstructure:"SLACK_ICON_EMOJI"`
+	SlackIconURL   string `mapstructure:"SLACK_ICON_URL"`
+
+	// Microsoft Teams incoming webhook or Workflows URL. The channel is only added when it is set.
+	TeamsWebhookURL string `mapstructure:"TEAMS_WEBHOOK_URL"`
+
+	// Discord webhook settings. The channel is only added when DiscordWebhookURL is set.
+	DiscordWebhookURL string `mapstructure:"DISCORD_WEBHOOK_URL"`
+	DiscordUsername   string `mapstructure:"DISCORD_USERNAME"`
+	DiscordAvatarURL  string `mapstructure:"DISCORD_AVATAR_URL"`
+
+	// Generic webhook settings. The channel is only added when WebhookURL is set.
+	WebhookURL          string            `mapstructure:"WEBHOOK_URL"`
+	WebhookMethod       string            `mapstructure:"WEBHOOK_METHOD"`
+	WebhookHeaders      map[string]string `mapstructure:"WEBHOOK_HEADERS"`
+	WebhookBodyTemplate string            `mapstructure:"WEBHOOK_BODY_TEMPLATE"`
+	WebhookSecret       string            `mapstructure:"WEBHOOK_SECRET"`
+
+	// Twilio SMS settings. The channel is only added when TwilioAccountSID is set.
+	TwilioAccountSID     string   `mapstructure:"TWILIO_ACCOUNT_SID"`
+	TwilioAuthToken      string   `mapstructure:"TWILIO_AUTH_TOKEN"`
+	TwilioBaseURL        string   `mapstructure:"TWILIO_BASE_URL"`
+	SMSFrom              string   `mapstructure:"SMS_FROM"`
+	SMSTo                []string `mapstructure:"SMS_TO"`
+	SMSMaxParts          int      `mapstructure:"SMS_MAX_PARTS"`
+	SMSStatusCallbackURL string   `mapstructure:"SMS_STATUS_CALLBACK_URL"`
+
+	// Firebase Cloud Messaging settings. The channel is only added when FCMCredentialsFile is set.
+	FCMCredentialsFile string `mapstructure:"FCM_CREDENTIALS_FILE"`
+	FCMProjectID       string `mapstructure:"FCM_PROJECT_ID"`
+
+	// Apple Push Notification service settings. The channel is only added when APNsKeyFile is set.
+	APNsKeyFile    string `mapstructure:"APNS_KEY_FILE"`
+	APNsKeyID      string `mapstructure:"APNS_KEY_ID"`
+	APNsTeamID     string `mapstructure:"APNS_TEAM_ID"`
+	APNsTopic      string `mapstructure:"APNS_TOPIC"`
+	APNsProduction bool   `mapstructure:"APNS_PRODUCTION"`
+
+	// Web Push settings. The channel is only added when VAPIDPrivateKey is set.
+	VAPIDPrivateKey string        `mapstructure:"VAPID_PRIVATE_KEY"`
+	VAPIDSubject    string        `mapstructure:"VAPID_SUBJECT"`
+	WebPushTTL      time.Duration `mapstructure:"WEBPUSH_TTL"`
+
+	// PagerDuty settings. The channel is only added when PagerDutyRoutingKey is set.
+	PagerDutyRoutingKey string `mapstructure:"PAGERDUTY_ROUTING_KEY"`
+	PagerDutySource     string `mapstructure:"PAGERDUTY_SOURCE"`
+
+	// Opsgenie settings. The channel is only added when OpsgenieAPIKey is set.
+	OpsgenieAPIKey  string `mapstructure:"OPSGENIE_API_KEY"`
+	OpsgenieBaseURL string `mapstructure:"OPSGENIE_BASE_URL"`
+	OpsgenieSource  string `mapstructure:"OPSGENIE_SOURCE"`
+
+	// Telegram settings. The channel is only added when TelegramBotToken is set.
+	TelegramBotToken  string   `mapstructure:"TELEGRAM_BOT_TOKEN"`
+	TelegramChatIDs   []string `mapstructure:"TELEGRAM_CHAT_IDS"`
+	TelegramParseMode string   `mapstructure:"TELEGRAM_PARSE_MODE"`
+
+	// Mattermost settings. The channel is only added when MattermostWebhookURL or
+	// MattermostBotToken is set.
+	MattermostWebhookURL string `mapstructure:"MATTERMOST_WEBHOOK_URL"`
+	MattermostServerURL  string `mapstructure:"MATTERMOST_SERVER_URL"`
+	MattermostBotToken   string `mapstructure:"MATTERMOST_BOT_TOKEN"`
+	MattermostChannelID  string `mapstructure:"MATTERMOST_CHANNEL_ID"`
+	MattermostUsername   string `mapstructure:"MATTERMOST_USERNAME"`
+	MattermostIconURL    string `mapstructure:"MATTERMOST_ICON_URL"`
+
+	// Matrix settings. The channel is only added when MatrixAccessToken is set.
+	MatrixHomeserverURL string `mapstructure:"MATRIX_HOMESERVER_URL"`
+	MatrixAccessToken   string `mapstructure:"MATRIX_ACCESS_TOKEN"`
+	MatrixRoomID        string `mapstructure:"MATRIX_ROOM_ID"`
+	MatrixNotice        bool   `mapstructure:"MATRIX_NOTICE"`
+
+	// Message broker settings. Each channel is only added when its URL or brokers are
+	// set. EventSource is the source of the published CloudEvents.
+	AMQPURL        string   `mapstructure:"AMQP_URL"`
+	AMQPExchange   string   `mapstructure:"AMQP_EXCHANGE"`
+	AMQPRoutingKey string   `mapstructure:"AMQP_ROUTING_KEY"`
+	AMQPMandatory  bool     `mapstructure:"AMQP_MANDATORY"`
+	NATSURL        string   `mapstructure:"NATS_URL"`
+	NATSSubject    string   `mapstructure:"NATS_SUBJECT"`
+	NATSJetStream  bool     `mapstructure:"NATS_JETSTREAM"`
+	KafkaBrokers   []string `mapstructure:"KAFKA_BROKERS"`
+	KafkaTopic     string   `mapstructure:"KAFKA_TOPIC"`
+	EventSource    string   `mapstructure:"EVENT_SOURCE"`
+
+	// File settings. The channel is only added when FilePath is set.
+	FilePath       string        `mapstructure:"FILE_PATH"`
+	FileMaxSizeMB  int64         `mapstructure:"FILE_MAX_SIZE_MB"`
+	FileMaxAge     time.Duration `mapstructure:"FILE_MAX_AGE"`
+	FileMaxBackups int           `mapstructure:"FILE_MAX_BACKUPS"`
+	FileCompress   bool          `mapstructure:"FILE_COMPRESS"`
+
+	// Syslog settings. The channel is only added when SyslogNetwork is set.
+	SyslogNetwork   string `mapstructure:"SYSLOG_NETWORK"`
+	SyslogAddress   string `mapstructure:"SYSLOG_ADDRESS"`
+	SyslogFacility  string `mapstructure:"SYSLOG_FACILITY"`
+	SyslogAppName   string `mapstructure:"SYSLOG_APP_NAME"`
+	SyslogTLSCAFile string `mapstructure:"SYSLOG_TLS_CA_FILE"`
+
+	// Queue settings. QueueBackend is either "memory" or "bolt".
+	QueueBackend string `mapstructure:"QUEUE_BACKEND"`
+	QueuePath    string `mapstructure:"QUEUE_PATH"`
+
+	// SMTP settings used by the Email channel.
+	SMTPHost     string   `mapstructure:"SMTP_HOST"`
+	SMTPPort     string   `mapstructure:"SMTP_PORT"`
+	SMTPUsername string   `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string   `mapstructure:"SMTP_PASSWORD"`
+	SMTPAuth     string   `mapstructure:"SMTP_AUTH"`
+	SMTPTLSMode  string   `mapstructure:"SMTP_TLS_MODE"`
+	EmailFrom    string   `mapstructure:"EMAIL_FROM"`
+	EmailReplyTo string   `mapstructure:"EMAIL_REPLY_TO"`
+	EmailTo      []string `mapstructure:"EMAIL_TO"`
+	EmailSubject string   `mapstructure:"EMAIL_SUBJECT"`
+}
+
+// ChannelSettings configures a named channel instance. Type is a channel type such
+// as slack or email, and Settings holds the settings of that type in snake case.
+type ChannelSettings struct {
+	Name     string         `mapstructure:"NAME"`
+	Type     string         `mapstructure:"TYPE"`
+	Settings map[string]any `mapstructure:"SETTINGS"`
+}
+
+// RetryPolicy represents the retry settings of a channel. Jitter is nil when left out,
+// as 0 turns it off.
+type RetryPolicy struct {
+	MaxAttempts    int           `mapstructure:"MAX_ATTEMPTS"`
+	InitialBackoff time.Duration `mapstructure:"INITIAL_BACKOFF"`
+	MaxBackoff     time.Duration `mapstructure:"MAX_BACKOFF"`
+	Multiplier     float64       `mapstructure:"MULTIPLIER"`
+	Jitter         *float64      `mapstructure:"JITTER"`
+	MaxAge         time.Duration `mapstructure:"MAX_AGE"`
 }
 
 // LoadConfig loads the configuration settings from various sources.
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicy_Jitter(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("yml")
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+RETRY_POLICIES:
+  default:
+    MAX_ATTEMPTS: 5
+  slack:
+    JITTER: 0
+`)))
+	var settings Settings
+	require.NoError(t, v.Unmarshal(&settings))
+
+	// Jitter left out is unset, while 0 turns it off
+	assert.Nil(t, settings.RetryPolicies["default"].Jitter)
+	if assert.NotNil(t, settings.RetryPolicies["slack"].Jitter) {
+		assert.Zero(t, *settings.RetryPolicies["slack"].Jitter)
+	}
+}
@@ -0,0 +1,178 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAMQPPublisher records published messages and fails the routing keys in errs.
+type fakeAMQPPublisher struct {
+	published []amqpPublished
+	errs      map[string]error
+	closed    bool
+}
+
+type amqpPublished struct {
+	exchange, key string
+	msg           amqp.Publishing
+}
+
+func (p *fakeAMQPPublisher) Publish(_ context.Context, exchange, key string, msg amqp.Publishing) error {
+	if err := p.errs[key]; err != nil {
+		return err
+	}
+	p.published = append(p.published, amqpPublished{exchange: exchange, key: key, msg: msg})
+	return nil
+}
+
+func (p *fakeAMQPPublisher) IsClosed() bool {
+	return p.closed
+}
+
+func (p *fakeAMQPPublisher) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestAMQP_Send(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name            string
+		message         Message
+		errs            map[string]error
+		dialErr         error
+		expectKeys      []string
+		expectErr       string
+		expectPermanent bool
+	}{
+		{
+			name:       "Publishing with the configured routing key returns no error",
+			message:    Message{Title: "Disk full", Body: "Disk of db-1 is full"},
+			expectKeys: []string{"notifications"},
+		},
+		{
+			name:       "Recipients override the routing key",
+			message:    Message{Body: "Disk full", Recipients: []string{"ops", "dev"}},
+			expectKeys: []string{"ops", "dev"},
+		},
+		{
+			name:            "Unroutable messages are permanent",
+			message:         Message{Body: "Disk full", Recipients: []string{"ops", "nobody"}},
+			errs:            map[string]error{"nobody": Permanent(ErrAMQPUnroutable)},
+			expectKeys:      []string{"ops"},
+			expectErr:       "nobody: amqp message is not routed to any queue",
+			expectPermanent: true,
+		},
+		{
+			name:      "Rejected messages are retried",
+			message:   Message{Body: "Disk full"},
+			errs:      map[string]error{"notifications": Retryable(ErrAMQPNack)},
+			expectErr: "notifications: amqp broker rejected the message",
+		},
+		{
+			name:      "Failed connections are retried",
+			message:   Message{Body: "Disk full"},
+			dialErr:   errors.New("connection refused"),
+			expectErr: "connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &fakeAMQPPublisher{errs: tt.errs}
+			sender := NewAMQP(AMQPConfig{Exchange: "events", RoutingKey: "notifications"})
+			sender.dial = func(AMQPConfig) (amqpPublisher, error) {
+				return publisher, tt.dialErr
+			}
+			sender.now = func() time.Time { return now }
+
+			err := sender.Send(WithNotificationID(context.Background(), "n-1"), tt.message)
+
+			if tt.expectErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectErr)
+				assert.Equal(t, tt.expectPermanent, IsPermanent(err))
+			} else {
+				assert.NoError(t, err)
+			}
+			var keys []string
+			for _, published := range publisher.published {
+				keys = append(keys, published.key)
+
+				// Messages are persistent events named after the notification
+				assert.Equal(t, "events", published.exchange)
+				assert.Equal(t, CloudEventContentType, published.msg.ContentType)
+				assert.Equal(t, amqp.Persistent, published.msg.DeliveryMode)
+				assert.Equal(t, "n-1", published.msg.MessageId)
+				assert.Equal(t, now, published.msg.Timestamp)
+				var event CloudEvent
+				require.NoError(t, json.Unmarshal(published.msg.Body, &event))
+				assert.Equal(t, tt.message.Body, event.Data.Body)
+			}
+			assert.Equal(t, tt.expectKeys, keys)
+		})
+	}
+}
+
+func TestAMQP_Reconnect(t *testing.T) {
+	var dials int
+	sender := NewAMQP(AMQPConfig{RoutingKey: "notifications"})
+	sender.dial = func(AMQPConfig) (amqpPublisher, error) {
+		dials++
+		return &fakeAMQPPublisher{}, nil
+	}
+
+	// The connection is reused until it is closed
+	require.NoError(t, sender.Send(context.Background(), Message{Body: "one"}))
+	require.NoError(t, sender.Send(context.Background(), Message{Body: "two"}))
+	assert.Equal(t, 1, dials)
+	sender.publisher.(*fakeAMQPPublisher).closed = true
+	require.NoError(t, sender.Send(context.Background(), Message{Body: "three"}))
+	assert.Equal(t, 2, dials)
+
+	// Sends fail once the sender is closed
+	require.NoError(t, sender.Close())
+	err := sender.Send(context.Background(), Message{Body: "four"})
+	assert.ErrorIs(t, err, amqp.ErrClosed)
+	assert.False(t, IsPermanent(err))
+}
+
+func TestAMQPError(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		expectPermanent bool
+	}{
+		{
+			name:            "Missing exchange is permanent",
+			err:             &amqp.Error{Code: amqp.NotFound, Reason: "NOT_FOUND - no exchange 'events'"},
+			expectPermanent: true,
+		},
+		{
+			name:            "Refused access is permanent",
+			err:             &amqp.Error{Code: amqp.AccessRefused, Reason: "ACCESS_REFUSED"},
+			expectPermanent: true,
+		},
+		{
+			name: "Closed connection is retried",
+			err:  &amqp.Error{Code: amqp.ConnectionForced, Reason: "CONNECTION_FORCED"},
+		},
+		{
+			name: "Network error is retried",
+			err:  errors.New("connection reset by peer"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectPermanent, IsPermanent(amqpError(tt.err)))
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Base URLs of the APNs production and development environments.
+const (
+	APNsProductionURL  = "https://api.push.apple.com"
+	APNsDevelopmentURL = "https://api.sandbox.push.apple.com"
+)
+
+// apnsTokenLifetime is how long a provider token is reused. Apple rejects tokens older
+// than an hour and refreshing them more often than every 20 minutes.
+const apnsTokenLifetime = 50 * time.Minute
+
+var (
+	ErrInvalidAPNsKey = errors.New("invalid apns key")
+)
+
+// apnsInvalidTokenReasons are the reasons APNs gives for device tokens that must not be
+// used anymore.
+var apnsInvalidTokenReasons = map[string]bool{
+	"BadDeviceToken": true,
+	"Unregistered":   true,
+}
+
+// APNsConfig holds the settings of the APNs channel.
+type APNsConfig struct {
+	KeyID      string // ID of the .p8 signing key.
+	TeamID     string // ID of the Apple developer team.
+	Topic      string // Bundle ID of the app.
+	PrivateKey []byte // Contents of the .p8 signing key.
+	Production bool   // Send to the production rather than the development environment.
+	BaseURL    string // Base URL overriding the environment.
+	Name       string // Channel name, defaults to APNs.
+}
+
+// APNs represents a push channel sending messages to iOS apps through the Apple Push
+// Notification service over HTTP/2. Recipients are APNs device tokens.
+type APNs struct {
+	config APNsConfig
+	name   string       // The name of the sender.
+	client *http.Client // HTTP client for making requests.
+	tokens TokenStore   // Store of the device tokens reported as unregistered.
+	key    crypto.Signer
+
+	// Cached provider token.
+	mu       sync.Mutex
+	jwt      string
+	issuedAt time.Time
+}
+
+// NewAPNs creates a new APNs channel instance. It fails if the signing key is invalid.
+// The client must support HTTP/2, which the default transport does over TLS.
+func NewAPNs(config APNsConfig, client *http.Client, tokens TokenStore) (*APNs, error) {
+	key, err := parsePrivateKey(config.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAPNsKey, err)
+	}
+	if config.KeyID == "" || config.TeamID == "" || config.Topic == "" {
+		return nil, fmt.Errorf("%w: missing key id, team id or topic", ErrInvalidAPNsKey)
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = APNsDevelopmentURL
+		if config.Production {
+			config.BaseURL = APNsProductionURL
+		}
+	}
+
+	return &APNs{
+		config: config,
+		name:   senderName(config.Name, "APNs"),
+		client: client,
+		tokens: tokens,
+		key:    key,
+	}, nil
+}
+
+// Send sends a message to each of its device tokens, skipping tokens that were
+// invalidated before. Tokens APNs reports as unregistered are invalidated.
+func (a *APNs) Send(ctx context.Context, message Message) error {
+	tokens, err := pushTokens(a.tokens, a.name, message)
+	if err != nil {
+		return err
+	}
+	if err := sendToTokens(ctx, tokens, func(ctx context.Context, token string) error {
+		return a.sendTo(ctx, token, message)
+	}); err != nil {
+		return err
+	}
+	log.Printf("apns message sent: %s", message)
+
+	return nil
+}
+
+// GetName returns the name of the APNs sender.
+func (a *APNs) GetName() string {
+	return a.name
+}
+
+// sendTo sends a message to a single device token.
+func (a *APNs) sendTo(ctx context.Context, token string, message Message) error {
+	providerToken, err := a.providerToken()
+	if err != nil {
+		return Permanent(err)
+	}
+
+	// Create a JSON request body.
+	reqBody, err := json.Marshal(apnsPayload(message))
+	if err != nil {
+		return Permanent(err)
+	}
+
+	endpoint := strings.TrimSuffix(a.config.BaseURL, "/") + "/3/device/" + token
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(reqBody))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "bearer "+providerToken)
+	req.Header.Set("apns-topic", a.config.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+	if message.CorrelationKey != "" {
+		req.Header.Set("apns-collapse-id", truncate(message.CorrelationKey, 64))
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return Retryable(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	// Invalidate tokens of uninstalled apps and refresh expired provider tokens, and
+	// classify all other failures so the notifier knows whether to retry.
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	var result struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.Unmarshal(body, &result)
+	switch {
+	case apnsInvalidTokenReasons[result.Reason]:
+		return invalidateToken(a.tokens, a.name, token, result.Reason)
+	case result.Reason == "ExpiredProviderToken":
+		a.resetProviderToken()
+		return Retryable(errors.New("apns provider token expired"))
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return CheckResponse(resp)
+}
+
+// providerToken returns the cached provider token, signing a new one when it gets old.
+func (a *APNs) providerToken() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.jwt != "" && time.Since(a.issuedAt) < apnsTokenLifetime {
+		return a.jwt, nil
+	}
+
+	now := time.Now()
+	jwt, err := signJWT(a.key, map[string]any{"kid": a.config.KeyID}, map[string]any{
+		"iss": a.config.TeamID,
+		"iat": now.Unix(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("signing apns provider token: %w", err)
+	}
+	a.jwt = jwt
+	a.issuedAt = now
+	return jwt, nil
+}
+
+// resetProviderToken forgets the cached provider token.
+func (a *APNs) resetProviderToken() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.jwt = ""
+}
+
+// apnsPayload builds the APNs payload of a message. Custom data is sent next to the aps
+// dictionary, where the app can read it.
+func apnsPayload(message Message) map[string]any {
+	aps := map[string]any{
+		"alert": map[string]string{
+			"title": message.Title,
+			"body":  message.Text(),
+		},
+	}
+	if message.CorrelationKey != "" {
+		aps["thread-id"] = message.CorrelationKey
+	}
+
+	// Let urgent messages break through focus modes.
+	switch message.SeverityOrDefault() {
+	case SeverityError, SeverityCritical:
+		aps["interruption-level"] = "time-sensitive"
+	}
+
+	payload := map[string]any{}
+	if push := message.Push; push != nil {
+		for key, value := range push.Data {
+			payload[key] = value
+		}
+		if push.Sound != "" {
+			aps["sound"] = push.Sound
+		}
+		if push.Badge != nil {
+			aps["badge"] = *push.Badge
+		}
+	}
+	payload["aps"] = aps
+	return payload
+}
@@ -0,0 +1,181 @@
+package channel
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAPNs(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		config    APNsConfig
+		expectURL string
+		expectErr bool
+	}{
+		{
+			name:      "Channel sends to the development environment by default",
+			config:    APNsConfig{KeyID: "KEY", TeamID: "TEAM", Topic: "com.example.app", PrivateKey: pemKey(t, key)},
+			expectURL: APNsDevelopmentURL,
+		},
+		{
+			name:      "Channel sends to the production environment",
+			config:    APNsConfig{KeyID: "KEY", TeamID: "TEAM", Topic: "com.example.app", PrivateKey: pemKey(t, key), Production: true},
+			expectURL: APNsProductionURL,
+		},
+		{
+			name:      "Channel without topic returns error",
+			config:    APNsConfig{KeyID: "KEY", TeamID: "TEAM", PrivateKey: pemKey(t, key)},
+			expectErr: true,
+		},
+		{
+			name:      "Channel with invalid key returns error",
+			config:    APNsConfig{KeyID: "KEY", TeamID: "TEAM", Topic: "com.example.app", PrivateKey: []byte("key")},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewAPNs(tt.config, http.DefaultClient, NewMemoryTokenStore())
+			if tt.expectErr {
+				assert.ErrorIs(t, err, ErrInvalidAPNsKey)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectURL, a.config.BaseURL)
+		})
+	}
+}
+
+func TestAPNs_Send(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          int
+		response        string
+		expectErr       string
+		expectPermanent bool
+		expectInvalid   bool
+		expectNewToken  bool
+	}{
+		{
+			name:   "Sending message returns no error",
+			status: http.StatusOK,
+		},
+		{
+			name:            "Sending to an unregistered token invalidates it",
+			status:          http.StatusGone,
+			response:        `{"reason": "Unregistered", "timestamp": 1700000000000}`,
+			expectErr:       "device-1: device token was invalidated: Unregistered",
+			expectPermanent: true,
+			expectInvalid:   true,
+		},
+		{
+			name:            "Sending to a malformed token invalidates it",
+			status:          http.StatusBadRequest,
+			response:        `{"reason": "BadDeviceToken"}`,
+			expectErr:       "device-1: device token was invalidated: BadDeviceToken",
+			expectPermanent: true,
+			expectInvalid:   true,
+		},
+		{
+			name:           "Sending with an expired provider token signs a new one",
+			status:         http.StatusForbidden,
+			response:       `{"reason": "ExpiredProviderToken"}`,
+			expectErr:      "device-1: apns provider token expired",
+			expectNewToken: true,
+		},
+		{
+			name:            "Sending to the wrong topic returns permanent error",
+			status:          http.StatusBadRequest,
+			response:        `{"reason": "DeviceTokenNotForTopic"}`,
+			expectErr:       `device-1: request failed: {"reason": "DeviceTokenNotForTopic"}`,
+			expectPermanent: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			require.NoError(t, err)
+
+			var payload map[string]any
+			var protocol string
+			var authorizations []string
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				protocol = req.Proto
+				authorizations = append(authorizations, req.Header.Get("Authorization"))
+				assert.Equal(t, "/3/device/device-1", req.URL.Path)
+				assert.Equal(t, "com.example.app", req.Header.Get("apns-topic"))
+				assert.Equal(t, "alert", req.Header.Get("apns-push-type"))
+				assert.NoError(t, json.NewDecoder(req.Body).Decode(&payload))
+				rw.WriteHeader(tt.status)
+				rw.Write([]byte(tt.response))
+			}))
+			server.EnableHTTP2 = true
+			server.StartTLS()
+			defer server.Close()
+
+			tokens := NewMemoryTokenStore()
+			a, err := NewAPNs(APNsConfig{KeyID: "KEY", TeamID: "TEAM", Topic: "com.example.app", PrivateKey: pemKey(t, key), BaseURL: server.URL}, server.Client(), tokens)
+			require.NoError(t, err)
+			err = a.Send(context.Background(), Message{Title: "Deploy", Body: "Hello", Recipients: []string{"device-1"}})
+			assert.Equal(t, "HTTP/2.0", protocol)
+			assert.Equal(t, map[string]any{"title": "Deploy", "body": "Hello"}, payload["aps"].(map[string]any)["alert"])
+
+			// The provider token is signed with the key and reused unless it expired
+			header, claims := decodeJWT(t, strings.TrimPrefix(authorizations[0], "bearer "))
+			assert.Equal(t, "KEY", header["kid"])
+			assert.Equal(t, "TEAM", claims["iss"])
+			assert.True(t, verifyJWT(t, key.Public(), strings.TrimPrefix(authorizations[0], "bearer ")))
+			_ = a.Send(context.Background(), Message{Body: "Hello", Recipients: []string{"device-1"}})
+			if !tt.expectInvalid {
+				assert.Equal(t, tt.expectNewToken, authorizations[0] != authorizations[1])
+			}
+
+			invalid, _ := tokens.IsInvalid("APNs", "device-1")
+			assert.Equal(t, tt.expectInvalid, invalid)
+			if tt.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expectErr)
+			assert.Equal(t, tt.expectPermanent, IsPermanent(err))
+		})
+	}
+}
+
+func TestAPNsPayload(t *testing.T) {
+	badge := 0
+	payload := apnsPayload(Message{
+		Title:          "Disk full",
+		Body:           "Disk is full",
+		Severity:       SeverityError,
+		CorrelationKey: "disk",
+		Push:           &PushOptions{Badge: &badge, Sound: "default", Data: map[string]string{"host": "db-1"}},
+	})
+
+	// Custom data is sent next to the aps dictionary
+	assert.Equal(t, map[string]any{
+		"host": "db-1",
+		"aps": map[string]any{
+			"alert":              map[string]string{"title": "Disk full", "body": "Disk is full"},
+			"badge":              0,
+			"sound":              "default",
+			"thread-id":          "disk",
+			"interruption-level": "time-sensitive",
+		},
+	}, payload)
+}
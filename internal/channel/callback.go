@@ -0,0 +1,47 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidCallback          = errors.New("invalid callback")
+	ErrInvalidCallbackSignature = errors.New("invalid callback signature")
+)
+
+// notificationIDKey is the context key of the notification ID.
+type notificationIDKey struct{}
+
+// WithNotificationID returns a context carrying the ID of the notification being sent,
+// so senders can refer to it, for example in the callback URLs they give to providers.
+func WithNotificationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, notificationIDKey{}, id)
+}
+
+// NotificationID returns the ID of the notification being sent, or "" if unknown.
+func NotificationID(ctx context.Context) string {
+	id, _ := ctx.Value(notificationIDKey{}).(string)
+	return id
+}
+
+// DeliveryReport is a delivery status reported by a provider after it accepted a message.
+type DeliveryReport struct {
+	NotificationID string // ID of the notification the message was sent for.
+	MessageID      string // ID the provider assigned to the message.
+	Recipient      string
+	Status         string // Status as named by the provider, such as "delivered".
+	Final          bool   // Whether the status will not change anymore.
+	Error          string // Reason of a failed delivery.
+}
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . CallbackHandler
+
+// CallbackHandler is implemented by senders whose provider reports the delivery status
+// of messages by calling back the service.
+type CallbackHandler interface {
+	// HandleCallback verifies a callback request and returns the report it carries.
+	// It returns ErrInvalidCallbackSignature for requests not sent by the provider.
+	HandleCallback(req *http.Request) (DeliveryReport, error)
+}
@@ -0,0 +1,115 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package channelfakes
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+)
+
+type FakeCallbackHandler struct {
+	HandleCallbackStub        func(*http.Request) (channel.DeliveryReport, error)
+	handleCallbackMutex       sync.RWMutex
+	handleCallbackArgsForCall []struct {
+		arg1 *http.Request
+	}
+	handleCallbackReturns struct {
+		result1 channel.DeliveryReport
+		result2 error
+	}
+	handleCallbackReturnsOnCall map[int]struct {
+		result1 channel.DeliveryReport
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeCallbackHandler) HandleCallback(arg1 *http.Request) (channel.DeliveryReport, error) {
+	fake.handleCallbackMutex.Lock()
+	ret, specificReturn := fake.handleCallbackReturnsOnCall[len(fake.handleCallbackArgsForCall)]
+	fake.handleCallbackArgsForCall = append(fake.handleCallbackArgsForCall, struct {
+		arg1 *http.Request
+	}{arg1})
+	stub := fake.HandleCallbackStub
+	fakeReturns := fake.handleCallbackReturns
+	fake.recordInvocation("HandleCallback", []interface{}{arg1})
+	fake.handleCallbackMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCallbackHandler) HandleCallbackCallCount() int {
+	fake.handleCallbackMutex.RLock()
+	defer fake.handleCallbackMutex.RUnlock()
+	return len(fake.handleCallbackArgsForCall)
+}
+
+func (fake *FakeCallbackHandler) HandleCallbackCalls(stub func(*http.Request) (channel.DeliveryReport, error)) {
+	fake.handleCallbackMutex.Lock()
+	defer fake.handleCallbackMutex.Unlock()
+	fake.HandleCallbackStub = stub
+}
+
+func (fake *FakeCallbackHandler) HandleCallbackArgsForCall(i int) *http.Request {
+	fake.handleCallbackMutex.RLock()
+	defer fake.handleCallbackMutex.RUnlock()
+	argsForCall := fake.handleCallbackArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCallbackHandler) HandleCallbackReturns(result1 channel.DeliveryReport, result2 error) {
+	fake.handleCallbackMutex.Lock()
+	defer fake.handleCallbackMutex.Unlock()
+	fake.HandleCallbackStub = nil
+	fake.handleCallbackReturns = struct {
+		result1 channel.DeliveryReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCallbackHandler) HandleCallbackReturnsOnCall(i int, result1 channel.DeliveryReport, result2 error) {
+	fake.handleCallbackMutex.Lock()
+	defer fake.handleCallbackMutex.Unlock()
+	fake.HandleCallbackStub = nil
+	if fake.handleCallbackReturnsOnCall == nil {
+		fake.handleCallbackReturnsOnCall = make(map[int]struct {
+			result1 channel.DeliveryReport
+			result2 error
+		})
+	}
+	fake.handleCallbackReturnsOnCall[i] = struct {
+		result1 channel.DeliveryReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCallbackHandler) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeCallbackHandler) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ channel.CallbackHandler = new(FakeCallbackHandler)
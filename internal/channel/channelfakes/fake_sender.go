@@ -2,6 +2,7 @@
 package channelfakes
 
 import (
+	"context"
 	"sync"
 
 	"github.com/phgermanov/notification-service/internal/channel"
@@ -18,10 +19,11 @@ type FakeSender struct {
 	getNameReturnsOnCall map[int]struct {
 		result1 string
 	}
-	SendStub        func(string) error
+	SendStub        func(context.Context, channel.Message) error
 	sendMutex       sync.RWMutex
 	sendArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
+		arg2 channel.Message
 	}
 	sendReturns struct {
 		result1 error
@@ -86,18 +88,19 @@ func (fake *FakeSender) GetNameReturnsOnCall(i int, result1 string) {
 	}{result1}
 }
 
-func (fake *FakeSender) Send(arg1 string) error {
+func (fake *FakeSender) Send(arg1 context.Context, arg2 channel.Message) error {
 	fake.sendMutex.Lock()
 	ret, specificReturn := fake.sendReturnsOnCall[len(fake.sendArgsForCall)]
 	fake.sendArgsForCall = append(fake.sendArgsForCall, struct {
-		arg1 string
-	}{arg1})
+		arg1 context.Context
+		arg2 channel.Message
+	}{arg1, arg2})
 	stub := fake.SendStub
 	fakeReturns := fake.sendReturns
-	fake.recordInvocation("Send", []interface{}{arg1})
+	fake.recordInvocation("Send", []interface{}{arg1, arg2})
 	fake.sendMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
@@ -111,17 +114,17 @@ func (fake *FakeSender) SendCallCount() int {
 	return len(fake.sendArgsForCall)
 }
 
-func (fake *FakeSender) SendCalls(stub func(string) error) {
+func (fake *FakeSender) SendCalls(stub func(context.Context, channel.Message) error) {
 	fake.sendMutex.Lock()
 	defer fake.sendMutex.Unlock()
 	fake.SendStub = stub
 }
 
-func (fake *FakeSender) SendArgsForCall(i int) string {
+func (fake *FakeSender) SendArgsForCall(i int) (context.Context, channel.Message) {
 	fake.sendMutex.RLock()
 	defer fake.sendMutex.RUnlock()
 	argsForCall := fake.sendArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeSender) SendReturns(result1 error) {
@@ -150,10 +153,6 @@ func (fake *FakeSender) SendReturnsOnCall(i int, result1 error) {
 func (fake *FakeSender) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.getNameMutex.RLock()
-	defer fake.getNameMutex.RUnlock()
-	fake.sendMutex.RLock()
-	defer fake.sendMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
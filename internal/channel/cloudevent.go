@@ -0,0 +1,74 @@
+package channel
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// CloudEventType is the type of the events notifications are published as.
+const CloudEventType = "com.github.phgermanov.notification-service.notification"
+
+// CloudEventContentType is the content type of events in the structured content mode.
+const CloudEventContentType = "application/cloudevents+json"
+
+// cloudEventSource is the default source of events.
+const cloudEventSource = "/notification-service"
+
+// CloudEvent is a notification published to a message broker, following the
+// CloudEvents 1.0 specification in the structured JSON format.
+type CloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject,omitempty"` // Correlation key of the message.
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	Severity        Severity  `json:"severity"` // Extension attribute, so consumers can filter without decoding the data.
+	Data            Message   `json:"data"`
+}
+
+// newCloudEvent wraps a message in an event. The event is named after the notification,
+// so consumers can drop duplicates of a retried send. Recipients name the destinations
+// on the broker and are left out.
+func newCloudEvent(ctx context.Context, source string, message Message, now time.Time) (CloudEvent, error) {
+	id := NotificationID(ctx)
+	if id == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return CloudEvent{}, err
+		}
+		id = hex.EncodeToString(b)
+	}
+	if source == "" {
+		source = cloudEventSource
+	}
+	message.Recipients = nil
+	return CloudEvent{
+		SpecVersion:     "1.0",
+		ID:              id,
+		Source:          source,
+		Type:            CloudEventType,
+		Subject:         message.CorrelationKey,
+		Time:            now.UTC(),
+		DataContentType: "application/json",
+		Severity:        message.SeverityOrDefault(),
+		Data:            message,
+	}, nil
+}
+
+// encodeCloudEvent returns a message as event and its JSON encoding.
+func encodeCloudEvent(ctx context.Context, source string, message Message, now time.Time) (CloudEvent, []byte, error) {
+	event, err := newCloudEvent(ctx, source, message, now)
+	if err != nil {
+		return CloudEvent{}, nil, err
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return CloudEvent{}, nil, err
+	}
+	return event, body, nil
+}
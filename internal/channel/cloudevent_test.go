@@ -0,0 +1,68 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeCloudEvent(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	ctx := WithNotificationID(context.Background(), "n-1")
+	message := Message{
+		Title:          "Disk full",
+		Body:           "Disk of db-1 is full",
+		Severity:       SeverityCritical,
+		Recipients:     []string{"alerts"},
+		CorrelationKey: "disk-db-1",
+	}
+
+	event, body, err := encodeCloudEvent(ctx, "", message, now)
+	require.NoError(t, err)
+
+	// The event is named after the notification and leaves out the recipients
+	assert.Equal(t, CloudEvent{
+		SpecVersion:     "1.0",
+		ID:              "n-1",
+		Source:          "/notification-service",
+		Type:            CloudEventType,
+		Subject:         "disk-db-1",
+		Time:            now.UTC(),
+		DataContentType: "application/json",
+		Severity:        SeverityCritical,
+		Data: Message{
+			Title:          "Disk full",
+			Body:           "Disk of db-1 is full",
+			Severity:       SeverityCritical,
+			CorrelationKey: "disk-db-1",
+		},
+	}, event)
+
+	// The encoding uses the attribute names of the specification
+	var attributes map[string]any
+	require.NoError(t, json.Unmarshal(body, &attributes))
+	assert.Equal(t, "1.0", attributes["specversion"])
+	assert.Equal(t, "2024-05-01T10:00:00Z", attributes["time"])
+	assert.Equal(t, "critical", attributes["severity"])
+	assert.Equal(t, "Disk full", attributes["data"].(map[string]any)["title"])
+}
+
+func TestEncodeCloudEvent_WithoutNotificationID(t *testing.T) {
+	message := Message{Body: "Disk full"}
+
+	first, _, err := encodeCloudEvent(context.Background(), "/custom", message, time.Now())
+	require.NoError(t, err)
+	second, _, err := encodeCloudEvent(context.Background(), "/custom", message, time.Now())
+	require.NoError(t, err)
+
+	// Events get random IDs and the configured source
+	assert.Len(t, first.ID, 32)
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Equal(t, "/custom", first.Source)
+	assert.Equal(t, SeverityInfo, first.Severity)
+	assert.Empty(t, first.Subject)
+}
@@ -0,0 +1,160 @@
+package channel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Discord message limits, see https://discord.com/developers/docs/resources/message#embed-object-embed-limits.
+const (
+	discordMaxContent     = 2000
+	discordMaxTitle       = 256
+	discordMaxDescription = 4096
+	discordMaxFields      = 25
+	discordMaxFieldName   = 256
+	discordMaxFieldValue  = 1024
+	discordMaxFooter      = 2048
+	discordMaxEmbedTotal  = 6000
+)
+
+// discordSeverityColor maps severities to the colour of the embed, matching the Slack colours.
+var discordSeverityColor = map[Severity]int{
+	SeverityInfo:     0x439FE0,
+	SeverityWarning:  0xDAA038,
+	SeverityError:    0xD40E0D,
+	SeverityCritical: 0x8B0000,
+}
+
+// DiscordPayload is the request posted to a Discord webhook.
+type DiscordPayload struct {
+	Content         string                 `json:"content,omitempty"`
+	Username        string                 `json:"username,omitempty"`
+	AvatarURL       string                 `json:"avatar_url,omitempty"`
+	Embeds          []DiscordEmbed         `json:"embeds,omitempty"`
+	AllowedMentions DiscordAllowedMentions `json:"allowed_mentions"`
+}
+
+// DiscordAllowedMentions controls which mentions in a message notify users.
+type DiscordAllowedMentions struct {
+	Parse []string `json:"parse"`
+}
+
+// DiscordEmbed is a rich embed shown below the content of a message.
+type DiscordEmbed struct {
+	Title       string              `json:"title,omitempty"`
+	Description string              `json:"description,omitempty"`
+	URL         string              `json:"url,omitempty"`
+	Color       int                 `json:"color,omitempty"`
+	Fields      []DiscordEmbedField `json:"fields,omitempty"`
+	Footer      *DiscordEmbedFooter `json:"footer,omitempty"`
+}
+
+// DiscordEmbedField is a name value pair of an embed.
+type DiscordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+// DiscordEmbedFooter is the small text at the bottom of an embed.
+type DiscordEmbedFooter struct {
+	Text string `json:"text"`
+}
+
+// discordPayloads renders a message as an embed followed by plain messages with the
+// rest of the body when it is too long for the embed. Mentions in the message are
+// shown but never notify anyone.
+func discordPayloads(message Message) []DiscordPayload {
+	chunks := splitText(discordBody(message), discordMaxDescription)
+	description := ""
+	if len(chunks) > 0 {
+		description = chunks[0]
+	}
+	payloads := []DiscordPayload{{Embeds: []DiscordEmbed{discordEmbed(message, description)}}}
+
+	// Send the remaining body as plain messages in the order it was written.
+	if len(chunks) > 1 {
+		rest := strings.Join(chunks[1:], "")
+		for _, chunk := range splitText(rest, discordMaxContent) {
+			payloads = append(payloads, DiscordPayload{Content: chunk})
+		}
+	}
+	for i := range payloads {
+		payloads[i].AllowedMentions = DiscordAllowedMentions{Parse: []string{}}
+	}
+	return payloads
+}
+
+// discordEmbed renders the embed of a message with the given description. Fields are
+// left out once the embed would exceed the total size Discord accepts.
+func discordEmbed(message Message, description string) DiscordEmbed {
+	embed := DiscordEmbed{
+		Title:       truncate(message.Title, discordMaxTitle),
+		Description: description,
+		Color:       discordSeverityColor[message.SeverityOrDefault()],
+	}
+
+	// Link the title to the first link and list the other links in the description.
+	links := append([]Link(nil), message.Links...)
+	for _, attachment := range message.Attachments {
+		if attachment.URL != "" {
+			links = append(links, Link{Title: attachment.Filename, URL: attachment.URL})
+		}
+	}
+	if len(links) > 0 && embed.Title != "" {
+		embed.URL = links[0].URL
+		links = links[1:]
+	}
+	for _, link := range links {
+		line := fmt.Sprintf("[%s](%s)", discordEscape(link.title()), link.URL)
+		if len([]rune(embed.Description))+len([]rune(line))+1 > discordMaxDescription {
+			break
+		}
+		embed.Description = strings.TrimPrefix(embed.Description+"\n"+line, "\n")
+	}
+
+	// Finish with the severity and tags in the footer.
+	footer := "Severity: " + string(message.SeverityOrDefault())
+	if len(message.Tags) > 0 {
+		footer += " | " + strings.Join(message.Tags, ", ")
+	}
+	size := len([]rune(embed.Title)) + len([]rune(embed.Description))
+	footerLength := discordMaxEmbedTotal - size
+	if footerLength > discordMaxFooter {
+		footerLength = discordMaxFooter
+	}
+	embed.Footer = &DiscordEmbedFooter{Text: truncate(footer, footerLength)}
+	size += len([]rune(embed.Footer.Text))
+
+	// Show metadata as inline fields while they fit. Discord rejects empty values.
+	for _, key := range message.MetadataKeys() {
+		value := message.Metadata[key]
+		if value == "" {
+			value = "-"
+		}
+		field := DiscordEmbedField{
+			Name:   truncate(key, discordMaxFieldName),
+			Value:  truncate(value, discordMaxFieldValue),
+			Inline: true,
+		}
+		size += len([]rune(field.Name)) + len([]rune(field.Value))
+		if len(embed.Fields) == discordMaxFields || size > discordMaxEmbedTotal {
+			break
+		}
+		embed.Fields = append(embed.Fields, field)
+	}
+	return embed
+}
+
+// discordBody returns the body to show, preferring the Markdown body as Discord renders it.
+func discordBody(message Message) string {
+	if message.Markdown != "" {
+		return message.Markdown
+	}
+	return message.Body
+}
+
+// discordEscape escapes the characters that end a Markdown link title.
+func discordEscape(text string) string {
+	return strings.NewReplacer("[", "\\[", "]", "\\]").Replace(text)
+}
@@ -0,0 +1,79 @@
+package channel
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscordEmbed(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  Message
+		expected DiscordEmbed
+	}{
+		{
+			name:    "Plain body",
+			message: Message{Body: "Hello @everyone"},
+			expected: DiscordEmbed{
+				Description: "Hello @everyone",
+				Color:       0x439FE0,
+				Footer:      &DiscordEmbedFooter{Text: "Severity: info"},
+			},
+		},
+		{
+			name: "Rich message",
+			message: Message{
+				Title:       "Disk almost full",
+				Body:        "Only 5% left",
+				Markdown:    "Only **5%** left",
+				Severity:    SeverityCritical,
+				Tags:        []string{"disk", "prod"},
+				Metadata:    map[string]string{"region": "eu-west-1", "host": "db-1", "owner": ""},
+				Links:       []Link{{Title: "Dashboard", URL: "https://example.com/d"}, {Title: "Run [book]", URL: "https://example.com/r"}},
+				Attachments: []Attachment{{Filename: "graph.png", URL: "https://example.com/g.png"}},
+			},
+			expected: DiscordEmbed{
+				Title:       "Disk almost full",
+				URL:         "https://example.com/d",
+				Description: "Only **5%** left\n[Run \\[book\\]](https://example.com/r)\n[graph.png](https://example.com/g.png)",
+				Color:       0x8B0000,
+				Fields: []DiscordEmbedField{
+					{Name: "host", Value: "db-1", Inline: true},
+					{Name: "owner", Value: "-", Inline: true},
+					{Name: "region", Value: "eu-west-1", Inline: true},
+				},
+				Footer: &DiscordEmbedFooter{Text: "Severity: critical | disk, prod"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payloads := discordPayloads(tt.message)
+			assert.Len(t, payloads, 1)
+			assert.Equal(t, []DiscordEmbed{tt.expected}, payloads[0].Embeds)
+			assert.Equal(t, []string{}, payloads[0].AllowedMentions.Parse)
+		})
+	}
+}
+
+func TestDiscordEmbed_Limits(t *testing.T) {
+	metadata := map[string]string{}
+	for i := 0; i < 30; i++ {
+		metadata["key"+strconv.Itoa(i)] = strings.Repeat("v", discordMaxFieldValue+10)
+	}
+	embed := discordEmbed(Message{Title: strings.Repeat("t", 300), Metadata: metadata}, strings.Repeat("d", discordMaxDescription))
+
+	// Long values are truncated and fields are dropped to stay within the total size
+	assert.Len(t, []rune(embed.Title), discordMaxTitle)
+	assert.Len(t, []rune(embed.Fields[0].Value), discordMaxFieldValue)
+	size := len([]rune(embed.Title)) + len([]rune(embed.Description)) + len([]rune(embed.Footer.Text))
+	for _, field := range embed.Fields {
+		size += len([]rune(field.Name)) + len([]rune(field.Value))
+	}
+	assert.LessOrEqual(t, size, discordMaxEmbedTotal)
+	assert.NotEmpty(t, embed.Fields)
+}
@@ -0,0 +1,128 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DiscordConfig holds the settings of the Discord channel.
+type DiscordConfig struct {
+	WebhookURL string // Webhook URL of the Discord channel.
+	Username   string // Optional username override.
+	AvatarURL  string // Optional avatar image override.
+	Name       string // Channel name, defaults to Discord.
+}
+
+// Discord represents a Discord channel posting embeds to a webhook.
+type Discord struct {
+	config DiscordConfig
+	name   string       // The name of the sender.
+	client *http.Client // HTTP client for making requests.
+
+	// Time until which the webhook's rate limit bucket is exhausted.
+	mu      sync.Mutex
+	resetAt time.Time
+}
+
+// NewDiscord creates a new Discord channel instance.
+func NewDiscord(config DiscordConfig, client *http.Client) *Discord {
+	return &Discord{
+		config: config,
+		name:   senderName(config.Name, "Discord"),
+		client: client,
+	}
+}
+
+// Send posts a message to the Discord webhook as an embed. Bodies too long for the
+// embed are continued in further messages, and retries skip the messages posted before.
+func (d *Discord) Send(ctx context.Context, message Message) error {
+	// Webhooks post to a fixed channel.
+	if len(message.Recipients) > 0 {
+		return Permanent(fmt.Errorf("%w: discord webhooks post to a fixed channel", ErrRecipientsNotSupported))
+	}
+
+	for i, payload := range discordPayloads(message) {
+		payload.Username = d.config.Username
+		payload.AvatarURL = d.config.AvatarURL
+		err := sendOnce(ctx, fmt.Sprintf("message-%d", i+1), func() error {
+			return d.post(ctx, payload)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	log.Printf("discord message sent: %s", message)
+
+	return nil
+}
+
+// GetName returns the name of the Discord sender.
+func (d *Discord) GetName() string {
+	return d.name
+}
+
+// post posts a single payload. While the rate limit bucket is exhausted it fails with
+// the time until the reset, so the notification is retried then.
+func (d *Discord) post(ctx context.Context, payload DiscordPayload) error {
+	if err := d.checkRateLimit(); err != nil {
+		return err
+	}
+
+	// Create a JSON request body.
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	// Send the POST request to the webhook URL.
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.config.WebhookURL, bytes.NewReader(reqBody))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return Retryable(err)
+	}
+	defer resp.Body.Close()
+	d.updateRateLimit(resp)
+
+	// Classify failures so the notifier knows whether to retry. Discord sends the
+	// delay in Retry-After, and in X-RateLimit-Reset-After for exhausted buckets.
+	if err := CheckResponse(resp); err != nil {
+		if _, ok := RetryAfter(err); !ok && resp.StatusCode == http.StatusTooManyRequests {
+			return RateLimited(err, ParseRetryAfter(resp.Header.Get("X-RateLimit-Reset-After"), time.Now()))
+		}
+		return err
+	}
+	return nil
+}
+
+// updateRateLimit remembers when the rate limit bucket is reset once it is exhausted.
+func (d *Discord) updateRateLimit(resp *http.Response) {
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return
+	}
+	resetAfter := ParseRetryAfter(resp.Header.Get("X-RateLimit-Reset-After"), time.Now())
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.resetAt = time.Now().Add(resetAfter)
+}
+
+// checkRateLimit returns a rate limiting error until the rate limit bucket is reset.
+func (d *Discord) checkRateLimit() error {
+	d.mu.Lock()
+	wait := time.Until(d.resetAt)
+	d.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	return RateLimited(errors.New("discord rate limit exhausted"), wait)
+}
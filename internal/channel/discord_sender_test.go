@@ -0,0 +1,140 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscord_Send(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		headers          map[string]string
+		response         string
+		expectErr        string
+		expectPermanent  bool
+		expectRateLimit  bool
+		expectRetryAfter time.Duration
+	}{
+		{
+			name:   "Posting message returns no error",
+			status: http.StatusNoContent,
+		},
+		{
+			name:            "Posting to a deleted webhook returns permanent error",
+			status:          http.StatusNotFound,
+			response:        `{"message": "Unknown Webhook", "code": 10015}`,
+			expectErr:       `request failed: {"message": "Unknown Webhook", "code": 10015}`,
+			expectPermanent: true,
+		},
+		{
+			name:             "Posting when rate limited returns retry after",
+			status:           http.StatusTooManyRequests,
+			headers:          map[string]string{"Retry-After": "2", "X-RateLimit-Reset-After": "1.5"},
+			response:         `{"message": "You are being rate limited.", "retry_after": 1.5, "global": false}`,
+			expectErr:        `request failed: {"message": "You are being rate limited.", "retry_after": 1.5, "global": false}`,
+			expectRateLimit:  true,
+			expectRetryAfter: 2 * time.Second,
+		},
+		{
+			name:             "Posting when rate limited without Retry-After uses the bucket reset",
+			status:           http.StatusTooManyRequests,
+			headers:          map[string]string{"X-RateLimit-Reset-After": "1.5"},
+			response:         `{"message": "You are being rate limited."}`,
+			expectErr:        `request failed: {"message": "You are being rate limited."}`,
+			expectRateLimit:  true,
+			expectRetryAfter: 1500 * time.Millisecond,
+		},
+		{
+			name:      "Posting during an outage returns retryable error",
+			status:    http.StatusBadGateway,
+			response:  `Bad gateway`,
+			expectErr: "request failed: Bad gateway",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload DiscordPayload
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.NoError(t, json.NewDecoder(req.Body).Decode(&payload))
+				for key, value := range tt.headers {
+					rw.Header().Set(key, value)
+				}
+				rw.WriteHeader(tt.status)
+				rw.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			d := NewDiscord(DiscordConfig{WebhookURL: server.URL, Username: "Notifier"}, server.Client())
+			err := d.Send(context.Background(), Message{Title: "Deploy", Body: "Hello", Severity: SeverityError})
+			assert.Equal(t, "Notifier", payload.Username)
+			assert.Equal(t, "Deploy", payload.Embeds[0].Title)
+			assert.Equal(t, 0xD40E0D, payload.Embeds[0].Color)
+			if tt.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expectErr)
+			assert.Equal(t, tt.expectPermanent, IsPermanent(err))
+			var rateLimited *RateLimitedError
+			assert.Equal(t, tt.expectRateLimit, errors.As(err, &rateLimited))
+			retryAfter, _ := RetryAfter(err)
+			assert.Equal(t, tt.expectRetryAfter, retryAfter)
+		})
+	}
+}
+
+func TestDiscord_SendLongBody(t *testing.T) {
+	var payloads []DiscordPayload
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var payload DiscordPayload
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&payload))
+		payloads = append(payloads, payload)
+
+		// Exhaust the bucket after the first message
+		if len(payloads) == 1 {
+			rw.Header().Set("X-RateLimit-Remaining", "0")
+			rw.Header().Set("X-RateLimit-Reset-After", "30")
+		}
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	d := NewDiscord(DiscordConfig{WebhookURL: server.URL}, server.Client())
+	body := strings.Repeat("a", discordMaxDescription+discordMaxContent+10)
+	progress := NewProgress(nil)
+	ctx := WithProgress(context.Background(), progress)
+
+	// The exhausted bucket fails the send until it is reset, without waiting for it
+	err := d.Send(ctx, Message{Body: body})
+	retryAfter, ok := RetryAfter(err)
+	assert.True(t, ok)
+	assert.InDelta(t, 30*time.Second, retryAfter, float64(time.Second))
+	assert.Len(t, payloads, 1)
+
+	// The retry continues the body in plain messages after the one already posted
+	d.resetAt = time.Time{}
+	assert.NoError(t, d.Send(ctx, Message{Body: body}))
+	assert.Len(t, payloads, 3)
+	assert.Len(t, payloads[0].Embeds[0].Description, discordMaxDescription)
+	assert.Len(t, payloads[1].Content, discordMaxContent)
+	assert.Len(t, payloads[2].Content, 10)
+}
+
+func TestDiscord_SendRecipients(t *testing.T) {
+	d := NewDiscord(DiscordConfig{WebhookURL: "http://localhost"}, http.DefaultClient)
+
+	// Webhooks cannot post anywhere else
+	err := d.Send(context.Background(), Message{Body: "Hello", Recipients: []string{"general"}})
+	assert.ErrorIs(t, err, ErrRecipientsNotSupported)
+	assert.True(t, IsPermanent(err))
+}
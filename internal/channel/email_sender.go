@@ -1,25 +1,505 @@
 package channel
 
-import "log"
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
 
+// TLS modes supported by the Email sender.
+const (
+	EmailTLSNone     = "none"     // Plain text connection.
+	EmailTLSStartTLS = "starttls" // Upgrade a plain connection with STARTTLS.
+	EmailTLSImplicit = "tls"      // Connect over TLS from the start (SMTPS).
+)
+
+// Authentication mechanisms supported by the Email sender.
+const (
+	EmailAuthNone  = ""
+	EmailAuthPlain = "plain"
+	EmailAuthLogin = "login"
+)
+
+var (
+	ErrNoRecipients       = errors.New("email has no recipients")
+	ErrUnsupportedTLSMode = errors.New("unsupported SMTP TLS mode")
+	ErrUnsupportedAuth    = errors.New("unsupported SMTP auth mechanism")
+)
+
+// EmailConfig holds the SMTP settings used by the Email sender.
+type EmailConfig struct {
+	Host       string        // SMTP server host name.
+	Port       string        // SMTP server port.
+	Username   string        // Username used for authentication.
+	Password   string        // Password used for authentication.
+	From       string        // Address the email is sent from.
+	ReplyTo    string        // Optional Reply-To address.
+	To         []string      // Recipient addresses.
+	Subject    string        // Subject line used for messages without a title.
+	TLSMode    string        // One of EmailTLSNone, EmailTLSStartTLS or EmailTLSImplicit.
+	AuthMethod string        // One of EmailAuthNone, EmailAuthPlain or EmailAuthLogin.
+	Timeout    time.Duration // Timeout for establishing the connection.
+	TLSConfig  *tls.Config   // Optional TLS configuration, defaults to verifying Host.
+	Name       string        // Channel name, defaults to Email.
+}
+
+// Email represents an Email channel delivering messages over SMTP.
 type Email struct {
-	to   string
-	name string
+	config EmailConfig
+	name   string
 }
 
-func NewEmail(to string) *Email {
+// NewEmail creates a new Email channel instance.
+func NewEmail(config EmailConfig) *Email {
+	if config.TLSMode == "" {
+		config.TLSMode = EmailTLSStartTLS
+	}
+	if config.Subject == "" {
+		config.Subject = "Notification"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
 	return &Email{
-		to:   to,
-		name: "Email",
+		config: config,
+		name:   senderName(config.Name, "Email"),
 	}
 }
 
-func (e *Email) Send(message string) error {
-	log.Printf("sending email to: %s. Message: %s\n", e.to, message)
-	// Simulate email sending process here
-	return nil
+// Send sends a message as an email to the configured recipients.
+// SMTP 5xx replies and configuration errors are permanent, other failures are retryable.
+func (e *Email) Send(ctx context.Context, message Message) error {
+	if len(message.Recipients) > 0 {
+		return Permanent(fmt.Errorf("%w: email is sent to the configured recipients", ErrRecipientsNotSupported))
+	}
+	if len(e.config.To) == 0 {
+		return Permanent(ErrNoRecipients)
+	}
+
+	// Build the MIME message before connecting so formatting errors fail fast.
+	body, err := e.buildMessage(message)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	return classifySMTPError(e.send(ctx, body))
+}
+
+// send delivers the rendered message over an SMTP session.
+func (e *Email) send(ctx context.Context, body []byte) error {
+	client, stop, err := e.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer stop()
+	defer client.Close()
+
+	if err := e.authenticate(client); err != nil {
+		return err
+	}
+
+	// Send the envelope followed by the message itself.
+	if err := client.Mail(addressOf(e.config.From)); err != nil {
+		return err
+	}
+	for _, to := range e.config.To {
+		if err := client.Rcpt(addressOf(to)); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	log.Printf("email sent to: %s", strings.Join(e.config.To, ", "))
+
+	return client.Quit()
 }
 
+// GetName returns the name of the Email sender.
 func (e *Email) GetName() string {
 	return e.name
 }
+
+// dial connects to the SMTP server and negotiates TLS according to the configured mode.
+// The connection is closed when ctx is done, aborting the SMTP session, until the
+// returned stop function is called.
+func (e *Email) dial(ctx context.Context) (*smtp.Client, func() bool, error) {
+	addr := net.JoinHostPort(e.config.Host, e.config.Port)
+	dialer := &net.Dialer{Timeout: e.config.Timeout}
+
+	var conn net.Conn
+	var err error
+	switch e.config.TLSMode {
+	case EmailTLSImplicit:
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: e.tlsConfig()}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", addr)
+	case EmailTLSStartTLS, EmailTLSNone:
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	default:
+		return nil, nil, Permanent(fmt.Errorf("%w: %s", ErrUnsupportedTLSMode, e.config.TLSMode))
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+
+	client, err := smtp.NewClient(conn, e.config.Host)
+	if err != nil {
+		stop()
+		conn.Close()
+		return nil, nil, err
+	}
+
+	// Upgrade the connection when STARTTLS is required.
+	if e.config.TLSMode == EmailTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			stop()
+			client.Close()
+			return nil, nil, Permanent(errors.New("smtp server does not support STARTTLS"))
+		}
+		if err := client.StartTLS(e.tlsConfig()); err != nil {
+			stop()
+			client.Close()
+			return nil, nil, err
+		}
+	}
+	return client, stop, nil
+}
+
+// authenticate performs SMTP authentication with the configured mechanism.
+func (e *Email) authenticate(client *smtp.Client) error {
+	var auth smtp.Auth
+	switch strings.ToLower(e.config.AuthMethod) {
+	case EmailAuthNone:
+		return nil
+	case EmailAuthPlain:
+		auth = smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.Host)
+	case EmailAuthLogin:
+		auth = &loginAuth{username: e.config.Username, password: e.config.Password, host: e.config.Host}
+	default:
+		return Permanent(fmt.Errorf("%w: %s", ErrUnsupportedAuth, e.config.AuthMethod))
+	}
+	if ok, _ := client.Extension("AUTH"); !ok {
+		return Permanent(errors.New("smtp server does not support AUTH"))
+	}
+	return client.Auth(startCheckedAuth{auth})
+}
+
+// startCheckedAuth marks the errors of an authentication mechanism raised before any
+// credentials are sent as permanent. They reject the configuration, such as sending
+// credentials over an unencrypted connection, which a retry does not change.
+type startCheckedAuth struct {
+	smtp.Auth
+}
+
+// Start begins the authentication, failing permanently if the mechanism refuses to.
+func (a startCheckedAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	proto, toServer, err := a.Auth.Start(server)
+	if err != nil {
+		return "", nil, Permanent(err)
+	}
+	return proto, toServer, nil
+}
+
+// tlsConfig returns the TLS configuration used for the SMTP connection.
+func (e *Email) tlsConfig() *tls.Config {
+	if e.config.TLSConfig != nil {
+		return e.config.TLSConfig
+	}
+	return &tls.Config{ServerName: e.config.Host}
+}
+
+// buildMessage renders the RFC 5322 message with text and HTML alternative parts.
+// Messages with inline attachments are wrapped in a multipart/mixed body.
+func (e *Email) buildMessage(message Message) ([]byte, error) {
+	messageID, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	alternative, err := buildAlternative(message)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "From", e.config.From)
+	writeHeader(&buf, "To", strings.Join(e.config.To, ", "))
+	if e.config.ReplyTo != "" {
+		writeHeader(&buf, "Reply-To", e.config.ReplyTo)
+	}
+	subject := message.Title
+	if subject == "" {
+		subject = e.config.Subject
+	}
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", subject))
+	writeHeader(&buf, "Date", time.Now().Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", fmt.Sprintf("<%s@%s>", messageID, domainOf(e.config.From)))
+
+	// Flag urgent messages so mail clients highlight them.
+	if message.Severity == SeverityError || message.Severity == SeverityCritical {
+		writeHeader(&buf, "Importance", "high")
+		writeHeader(&buf, "X-Priority", "1")
+	}
+	if len(message.Tags) > 0 {
+		keywords := make([]string, len(message.Tags))
+		for i, tag := range message.Tags {
+			keywords[i] = mime.QEncoding.Encode("utf-8", tag)
+		}
+		writeHeader(&buf, "Keywords", strings.Join(keywords, ", "))
+	}
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	// Without inline attachments the alternative parts form the whole body.
+	var inline []Attachment
+	for _, attachment := range message.Attachments {
+		if len(attachment.Content) > 0 {
+			inline = append(inline, attachment)
+		}
+	}
+	if len(inline) == 0 {
+		buf.Write(alternative)
+		return buf.Bytes(), nil
+	}
+
+	boundary, err := randomToken(12)
+	if err != nil {
+		return nil, err
+	}
+	writeHeader(&buf, "Content-Type", fmt.Sprintf("multipart/mixed; boundary=%q", boundary))
+	buf.WriteString("\r\n")
+	buf.WriteString("--" + boundary + "\r\n")
+	buf.Write(alternative)
+	for _, attachment := range inline {
+		buf.WriteString("--" + boundary + "\r\n")
+		writeAttachment(&buf, attachment)
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+
+	return buf.Bytes(), nil
+}
+
+// buildAlternative renders the multipart/alternative entity holding the text and HTML parts.
+func buildAlternative(message Message) ([]byte, error) {
+	boundary, err := randomToken(12)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", boundary))
+	buf.WriteString("\r\n")
+
+	// Write the plain text part first so clients prefer the HTML part when they support it.
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{contentType: "text/plain", content: emailText(message)},
+		{contentType: "text/html", content: emailHTML(message)},
+	}
+	for _, part := range parts {
+		buf.WriteString("--" + boundary + "\r\n")
+		writeHeader(&buf, "Content-Type", part.contentType+"; charset=utf-8")
+		writeHeader(&buf, "Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		qp := quotedprintable.NewWriter(&buf)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+
+	return buf.Bytes(), nil
+}
+
+// emailText renders the plain text part of a message.
+func emailText(message Message) string {
+	var b strings.Builder
+	b.WriteString(message.Text())
+	if len(message.Metadata) > 0 {
+		b.WriteString("\n")
+		for _, key := range message.MetadataKeys() {
+			fmt.Fprintf(&b, "\n%s: %s", key, message.Metadata[key])
+		}
+	}
+	links := emailLinks(message)
+	if len(links) > 0 {
+		b.WriteString("\n")
+		for _, link := range links {
+			fmt.Fprintf(&b, "\n%s: %s", link.title(), link.URL)
+		}
+	}
+	return b.String()
+}
+
+// emailHTML renders the HTML part of a message, preferring the HTML body when given.
+func emailHTML(message Message) string {
+	if message.HTML != "" {
+		return message.HTML
+	}
+
+	var b strings.Builder
+	b.WriteString("<html><body>")
+	if message.Title != "" {
+		b.WriteString("<h2>" + html.EscapeString(message.Title) + "</h2>")
+	}
+	b.WriteString("<p>" + strings.ReplaceAll(html.EscapeString(message.Text()), "\n", "<br>\n") + "</p>")
+	if len(message.Metadata) > 0 {
+		b.WriteString("<table>")
+		for _, key := range message.MetadataKeys() {
+			fmt.Fprintf(&b, "<tr><th align=\"left\">%s</th><td>%s</td></tr>", html.EscapeString(key), html.EscapeString(message.Metadata[key]))
+		}
+		b.WriteString("</table>")
+	}
+	links := emailLinks(message)
+	if len(links) > 0 {
+		b.WriteString("<ul>")
+		for _, link := range links {
+			fmt.Fprintf(&b, "<li><a href=\"%s\">%s</a></li>", html.EscapeString(link.URL), html.EscapeString(link.title()))
+		}
+		b.WriteString("</ul>")
+	}
+	b.WriteString("</body></html>")
+	return b.String()
+}
+
+// emailLinks returns the links of a message followed by attachments only available by URL.
+func emailLinks(message Message) []Link {
+	links := append([]Link(nil), message.Links...)
+	for _, attachment := range message.Attachments {
+		if len(attachment.Content) == 0 && attachment.URL != "" {
+			links = append(links, Link{Title: attachment.Filename, URL: attachment.URL})
+		}
+	}
+	return links
+}
+
+// writeAttachment writes an inline attachment as a base64 encoded MIME part.
+func writeAttachment(buf *bytes.Buffer, attachment Attachment) {
+	contentType := attachment.ContentType
+	if _, _, err := mime.ParseMediaType(contentType); err != nil {
+		contentType = "application/octet-stream"
+	}
+	writeHeader(buf, "Content-Type", contentType)
+	writeHeader(buf, "Content-Transfer-Encoding", "base64")
+	writeHeader(buf, "Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	buf.WriteString("\r\n")
+
+	// Wrap the encoded content at 76 characters as required by RFC 2045.
+	encoded := base64.StdEncoding.EncodeToString(attachment.Content)
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+}
+
+// writeHeader writes a single header line.
+func writeHeader(buf *bytes.Buffer, key, value string) {
+	buf.WriteString(key + ": " + value + "\r\n")
+}
+
+// classifySMTPError marks SMTP 5xx replies as permanent and all other failures as retryable.
+func classifySMTPError(err error) error {
+	if err == nil || IsPermanent(err) {
+		return err
+	}
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return Permanent(err)
+	}
+	return Retryable(err)
+}
+
+// loginAuth implements the non-standard but widely used LOGIN authentication mechanism.
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+// Start begins the LOGIN exchange, refusing to send credentials over an unencrypted connection.
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+// Next answers the server's username and password challenges.
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSuffix(string(fromServer), ":")) {
+	case "username":
+		return []byte(a.username), nil
+	case "password":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected server challenge: %s", fromServer)
+	}
+}
+
+// addressOf extracts the bare address from a value such as "Name <user@example.com>".
+func addressOf(value string) string {
+	if addr, err := mail.ParseAddress(value); err == nil {
+		return addr.Address
+	}
+	return value
+}
+
+// domainOf returns the domain part of an address, used for generating Message-IDs.
+func domainOf(value string) string {
+	address := addressOf(value)
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		return address[i+1:]
+	}
+	return "localhost"
+}
+
+// isLocalhost reports whether the host refers to the local machine.
+func isLocalhost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
+// randomToken returns a random hex string of n bytes.
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
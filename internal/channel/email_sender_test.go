@@ -0,0 +1,351 @@
+package channel
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmail_Send(t *testing.T) {
+	tests := []struct {
+		name         string
+		implicitTLS  bool
+		config       EmailConfig
+		message      Message
+		expectErr    string
+		expectAuth   string
+		expectRcpts  []string
+		expectInBody []string
+	}{
+		{
+			name: "Sending email over STARTTLS with PLAIN auth",
+			config: EmailConfig{
+				Username:   "user",
+				Password:   "secret",
+				From:       "Notifier <notifier@example.com>",
+				ReplyTo:    "support@example.com",
+				To:         []string{"alice@example.com", "bob@example.com"},
+				TLSMode:    EmailTLSStartTLS,
+				AuthMethod: EmailAuthPlain,
+			},
+			expectAuth:  "PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00user\x00secret")),
+			expectRcpts: []string{"alice@example.com", "bob@example.com"},
+			expectInBody: []string{
+				"From: Notifier <notifier@example.com>",
+				"To: alice@example.com, bob@example.com",
+				"Reply-To: support@example.com",
+				"Subject: Notification",
+				"MIME-Version: 1.0",
+				"Content-Type: multipart/alternative;",
+				"Content-Type: text/plain; charset=utf-8",
+				"Content-Type: text/html; charset=utf-8",
+				"Hello <world>",
+				"Hello &lt;world&gt;",
+			},
+		},
+		{
+			name:        "Sending email over implicit TLS with LOGIN auth",
+			implicitTLS: true,
+			config: EmailConfig{
+				Username:   "user",
+				Password:   "secret",
+				From:       "notifier@example.com",
+				To:         []string{"alice@example.com"},
+				TLSMode:    EmailTLSImplicit,
+				AuthMethod: EmailAuthLogin,
+			},
+			expectAuth:   "LOGIN user secret",
+			expectRcpts:  []string{"alice@example.com"},
+			expectInBody: []string{"To: alice@example.com", "Hello <world>"},
+		},
+		{
+			name: "Sending rich message with attachments",
+			config: EmailConfig{
+				From:    "notifier@example.com",
+				To:      []string{"alice@example.com"},
+				TLSMode: EmailTLSNone,
+			},
+			message: Message{
+				Title:    "Disk almost full",
+				Body:     "Only 5% left",
+				Severity: SeverityCritical,
+				Tags:     []string{"disk", "prod"},
+				Metadata: map[string]string{"host": "db-1"},
+				Links:    []Link{{Title: "Dashboard", URL: "https://example.com/d"}},
+				Attachments: []Attachment{
+					{Filename: "usage.csv", ContentType: "text/csv", Content: []byte("disk,95")},
+					{Filename: "graph.png", URL: "https://example.com/graph.png"},
+				},
+			},
+			expectRcpts: []string{"alice@example.com"},
+			expectInBody: []string{
+				"Subject: Disk almost full",
+				"Importance: high",
+				"Keywords: disk, prod",
+				"Content-Type: multipart/mixed;",
+				"Content-Type: multipart/alternative;",
+				"host: db-1",
+				"Dashboard: https://example.com/d",
+				"graph.png: https://example.com/graph.png",
+				"Content-Disposition: attachment; filename=usage.csv",
+				base64.StdEncoding.EncodeToString([]byte("disk,95")),
+			},
+		},
+		{
+			name: "Sending email with recipients of the message returns error",
+			config: EmailConfig{
+				From: "notifier@example.com",
+				To:   []string{"alice@example.com"},
+			},
+			message:   Message{Body: "Hello", Recipients: []string{"carol@example.com"}},
+			expectErr: "channel does not support recipients: email is sent to the configured recipients",
+		},
+		{
+			name: "Sending email without recipients returns error",
+			config: EmailConfig{
+				From: "notifier@example.com",
+			},
+			expectErr: ErrNoRecipients.Error(),
+		},
+		{
+			name: "Sending email with unknown auth mechanism returns error",
+			config: EmailConfig{
+				From:       "notifier@example.com",
+				To:         []string{"alice@example.com"},
+				TLSMode:    EmailTLSNone,
+				AuthMethod: "cram-md5",
+			},
+			expectErr: "unsupported SMTP auth mechanism: cram-md5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeSMTPServer(t, tt.implicitTLS)
+
+			host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+			tt.config.Host = host
+			tt.config.Port = port
+			tt.config.TLSConfig = server.clientTLS
+			e := NewEmail(tt.config)
+			if tt.message.Text() == "" {
+				tt.message = Message{Body: "Hello <world>"}
+			}
+
+			err := e.Send(context.Background(), tt.message)
+			if tt.expectErr != "" {
+				assert.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+
+			received := server.received()
+			assert.Equal(t, tt.expectAuth, received.auth)
+			assert.Equal(t, "notifier@example.com", received.from)
+			assert.Equal(t, tt.expectRcpts, received.rcpts)
+			for _, s := range tt.expectInBody {
+				assert.Contains(t, received.data, s)
+			}
+		})
+	}
+}
+
+// receivedMail holds what the fake SMTP server received during a session.
+type receivedMail struct {
+	auth  string
+	from  string
+	rcpts []string
+	data  string
+}
+
+// fakeSMTPServer is a minimal in-process SMTP server supporting STARTTLS and AUTH.
+type fakeSMTPServer struct {
+	listener  net.Listener
+	serverTLS *tls.Config
+	clientTLS *tls.Config
+	mu        sync.Mutex
+	mail      receivedMail
+	done      chan struct{}
+}
+
+// newFakeSMTPServer starts a fake SMTP server, optionally wrapped in TLS from the start.
+func newFakeSMTPServer(t *testing.T, implicitTLS bool) *fakeSMTPServer {
+	serverTLS, clientTLS := testTLSConfigs(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	if implicitTLS {
+		listener = tls.NewListener(listener, serverTLS)
+	}
+
+	s := &fakeSMTPServer{
+		listener:  listener,
+		serverTLS: serverTLS,
+		clientTLS: clientTLS,
+		done:      make(chan struct{}),
+	}
+	go s.serve()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+// received waits for the session to finish and returns the received mail.
+func (s *fakeSMTPServer) received() receivedMail {
+	select {
+	case <-s.done:
+	case <-time.After(5 * time.Second):
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mail
+}
+
+// serve handles a single SMTP session.
+func (s *fakeSMTPServer) serve() {
+	defer close(s.done)
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 localhost ESMTP fake")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250-localhost")
+			if _, isTLS := conn.(*tls.Conn); !isTLS {
+				tp.PrintfLine("250-STARTTLS")
+			}
+			tp.PrintfLine("250 AUTH PLAIN LOGIN")
+		case "STARTTLS":
+			tp.PrintfLine("220 ready to start TLS")
+			tlsConn := tls.Server(conn, s.serverTLS)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			tp = textproto.NewConn(conn)
+		case "AUTH":
+			s.handleAuth(tp, arg)
+		case "MAIL":
+			s.mu.Lock()
+			s.mail.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			s.mu.Unlock()
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			s.mu.Lock()
+			s.mail.rcpts = append(s.mail.rcpts, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			s.mu.Unlock()
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 end data with <CR><LF>.<CR><LF>")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.mail.data = string(data)
+			s.mu.Unlock()
+			tp.PrintfLine("250 OK")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("502 command not implemented")
+		}
+	}
+}
+
+// handleAuth records the credentials presented with the PLAIN or LOGIN mechanism.
+func (s *fakeSMTPServer) handleAuth(tp *textproto.Conn, arg string) {
+	mechanism, initial, _ := strings.Cut(arg, " ")
+	switch mechanism {
+	case "PLAIN":
+		s.mu.Lock()
+		s.mail.auth = "PLAIN " + initial
+		s.mu.Unlock()
+	case "LOGIN":
+		var creds []string
+		for _, challenge := range []string{"Username:", "Password:"} {
+			tp.PrintfLine("334 %s", base64.StdEncoding.EncodeToString([]byte(challenge)))
+			line, _ := tp.ReadLine()
+			decoded, _ := base64.StdEncoding.DecodeString(line)
+			creds = append(creds, string(decoded))
+		}
+		s.mu.Lock()
+		s.mail.auth = "LOGIN " + strings.Join(creds, " ")
+		s.mu.Unlock()
+	}
+	tp.PrintfLine("235 authenticated")
+}
+
+// testTLSConfigs creates a self-signed certificate for 127.0.0.1 and matching server and client TLS configs.
+func testTLSConfigs(t *testing.T) (*tls.Config, *tls.Config) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	serverTLS := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	clientTLS := &tls.Config{RootCAs: pool, ServerName: "127.0.0.1"}
+	return serverTLS, clientTLS
+}
+
+func TestStartCheckedAuth(t *testing.T) {
+	server := &smtp.ServerInfo{Name: "smtp.example.com", Auth: []string{"PLAIN", "LOGIN"}}
+	for _, auth := range []smtp.Auth{
+		smtp.PlainAuth("", "user", "secret", "smtp.example.com"),
+		&loginAuth{username: "user", password: "secret", host: "smtp.example.com"},
+	} {
+		// Credentials are not sent over an unencrypted connection, which retries do not change
+		_, _, err := startCheckedAuth{auth}.Start(server)
+		assert.EqualError(t, err, "unencrypted connection")
+		assert.True(t, IsPermanent(err))
+		assert.True(t, IsPermanent(classifySMTPError(err)))
+	}
+
+	// Mechanisms that start successfully are unchanged
+	proto, _, err := startCheckedAuth{smtp.PlainAuth("", "user", "secret", "smtp.example.com")}.Start(&smtp.ServerInfo{Name: "smtp.example.com", TLS: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "PLAIN", proto)
+}
@@ -0,0 +1,152 @@
+package channel
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PermanentError is a send failure that will not succeed when retried,
+// such as invalid credentials or a malformed request.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// RetryableError is a temporary send failure, such as a network error or a server error.
+type RetryableError struct {
+	Err error
+}
+
+func (e *RetryableError) Error() string { return e.Err.Error() }
+func (e *RetryableError) Unwrap() error { return e.Err }
+
+// RateLimitedError is a send failure caused by rate limiting. RetryAfter is the delay
+// requested by the provider, or 0 when it did not specify one.
+type RateLimitedError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string { return e.Err.Error() }
+func (e *RateLimitedError) Unwrap() error { return e.Err }
+
+// Permanent marks err as a failure that must not be retried.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// Retryable marks err as a temporary failure that may succeed when retried.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryableError{Err: err}
+}
+
+// RateLimited marks err as a rate limiting failure to be retried after retryAfter.
+func RateLimited(err error, retryAfter time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &RateLimitedError{Err: err, RetryAfter: retryAfter}
+}
+
+// IsPermanent reports whether err was marked as a permanent failure.
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
+}
+
+// RetryAfter returns the delay requested by a rate limited provider, if any.
+func RetryAfter(err error) (time.Duration, bool) {
+	var rateLimited *RateLimitedError
+	if errors.As(err, &rateLimited) && rateLimited.RetryAfter > 0 {
+		return rateLimited.RetryAfter, true
+	}
+	return 0, false
+}
+
+// JoinErrors combines the failures of a send to several targets. The result is
+// permanent only if all failures are permanent, so that a retry is attempted as
+// long as one of the targets may still succeed. It returns nil without errors.
+func JoinErrors(errs ...error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+
+	permanent := true
+	var retryAfter time.Duration
+	for _, err := range errs {
+		permanent = permanent && IsPermanent(err)
+		if d, ok := RetryAfter(err); ok && d > retryAfter {
+			retryAfter = d
+		}
+	}
+	joined := errors.Join(errs...)
+	if permanent {
+		return Permanent(joined)
+	}
+
+	// Flatten the errors so permanent failures of single targets do not mark the whole send as permanent.
+	flattened := errors.New(strings.ReplaceAll(joined.Error(), "\n", "; "))
+	if retryAfter > 0 {
+		return RateLimited(flattened, retryAfter)
+	}
+	return Retryable(flattened)
+}
+
+// CheckResponse returns nil for successful responses and otherwise an error
+// classified by the status code: 429 is rate limited honouring the Retry-After
+// header, 408 and 5xx are retryable and all other codes are permanent.
+func CheckResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Retryable(err)
+	}
+	requestErr := fmt.Errorf("request failed: %s", string(b))
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return RateLimited(requestErr, ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()))
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode >= 500:
+		return Retryable(requestErr)
+	default:
+		return Permanent(requestErr)
+	}
+}
+
+// ParseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+// It returns 0 when the value is missing or invalid.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
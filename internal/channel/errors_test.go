@@ -0,0 +1,162 @@
+package channel
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckResponse(t *testing.T) {
+	tests := []struct {
+		name              string
+		statusCode        int
+		header            http.Header
+		expectErr         bool
+		expectPermanent   bool
+		expectRetryAfter  time.Duration
+		expectRateLimited bool
+	}{
+		{
+			name:       "Success returns no error",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:            "Client error is permanent",
+			statusCode:      http.StatusBadRequest,
+			expectErr:       true,
+			expectPermanent: true,
+		},
+		{
+			name:       "Server error is retryable",
+			statusCode: http.StatusBadGateway,
+			expectErr:  true,
+		},
+		{
+			name:              "Too many requests honours Retry-After",
+			statusCode:        http.StatusTooManyRequests,
+			header:            http.Header{"Retry-After": []string{"30"}},
+			expectErr:         true,
+			expectRetryAfter:  30 * time.Second,
+			expectRateLimited: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: tt.statusCode,
+				Header:     tt.header,
+				Body:       io.NopCloser(strings.NewReader("body")),
+			}
+
+			err := CheckResponse(resp)
+			if !tt.expectErr {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, "request failed: body")
+			assert.Equal(t, tt.expectPermanent, IsPermanent(err))
+			retryAfter, rateLimited := RetryAfter(err)
+			assert.Equal(t, tt.expectRateLimited, rateLimited)
+			assert.Equal(t, tt.expectRetryAfter, retryAfter)
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2023, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		value  string
+		expect time.Duration
+	}{
+		{name: "Seconds", value: "120", expect: 2 * time.Minute},
+		{name: "HTTP date", value: "Fri, 01 Sep 2023 12:00:30 GMT", expect: 30 * time.Second},
+		{name: "Date in the past", value: "Fri, 01 Sep 2023 11:00:00 GMT", expect: 0},
+		{name: "Empty", value: "", expect: 0},
+		{name: "Invalid", value: "soon", expect: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, ParseRetryAfter(tt.value, now))
+		})
+	}
+}
+
+func TestErrorWrappers(t *testing.T) {
+	base := errors.New("boom")
+
+	// Wrapped errors keep their message and can be unwrapped
+	for _, err := range []error{Permanent(base), Retryable(base), RateLimited(base, time.Second)} {
+		assert.EqualError(t, err, "boom")
+		assert.ErrorIs(t, err, base)
+	}
+
+	// Wrapping nil returns nil
+	assert.NoError(t, Permanent(nil))
+	assert.NoError(t, Retryable(nil))
+	assert.NoError(t, RateLimited(nil, time.Second))
+}
+
+func TestJoinErrors(t *testing.T) {
+	tests := []struct {
+		name              string
+		errs              []error
+		expectErr         bool
+		expectPermanent   bool
+		expectRetryAfter  time.Duration
+		expectMessagePart string
+	}{
+		{
+			name: "No errors",
+		},
+		{
+			name:            "Single error is kept",
+			errs:            []error{Permanent(errors.New("bad token"))},
+			expectErr:       true,
+			expectPermanent: true,
+		},
+		{
+			name:            "All permanent",
+			errs:            []error{Permanent(errors.New("a")), Permanent(errors.New("b"))},
+			expectErr:       true,
+			expectPermanent: true,
+		},
+		{
+			name:              "Mixed failures are retried",
+			errs:              []error{Permanent(errors.New("a")), Retryable(errors.New("b"))},
+			expectErr:         true,
+			expectMessagePart: "a; b",
+		},
+		{
+			name:             "Longest retry after wins",
+			errs:             []error{RateLimited(errors.New("a"), time.Second), RateLimited(errors.New("b"), time.Minute)},
+			expectErr:        true,
+			expectRetryAfter: time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := JoinErrors(tt.errs...)
+			if !tt.expectErr {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Equal(t, tt.expectPermanent, IsPermanent(err))
+			retryAfter, _ := RetryAfter(err)
+			assert.Equal(t, tt.expectRetryAfter, retryAfter)
+			if tt.expectMessagePart != "" {
+				assert.Contains(t, err.Error(), tt.expectMessagePart)
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FCMAPIURL is the base URL of the Firebase Cloud Messaging HTTP v1 API.
+const FCMAPIURL = "https://fcm.googleapis.com"
+
+// fcmScope is the OAuth 2.0 scope needed to send messages with FCM.
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+var (
+	ErrInvalidFCMCredentials = errors.New("invalid fcm credentials")
+)
+
+// FCMConfig holds the settings of the FCM channel.
+type FCMConfig struct {
+	CredentialsJSON []byte // Contents of the Google service account key file.
+	ProjectID       string // Firebase project, defaults to the project of the service account.
+	BaseURL         string // Base URL of the FCM API, defaults to FCMAPIURL.
+	TokenURL        string // OAuth token endpoint, defaults to the token_uri of the service account.
+	Name            string // Channel name, defaults to FCM.
+}
+
+// FCM represents a push channel sending messages to Android, iOS and web apps through
+// Firebase Cloud Messaging. Recipients are FCM registration tokens.
+type FCM struct {
+	config      FCMConfig
+	name        string       // The name of the sender.
+	client      *http.Client // HTTP client for making requests.
+	tokens      TokenStore   // Store of the device tokens reported as unregistered.
+	clientEmail string       // Email of the service account.
+	key         crypto.Signer
+
+	// Cached OAuth access token.
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// fcmServiceAccount is the part of a Google service account key file used by FCM.
+type fcmServiceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// FCMMessage is the message of an FCM send request.
+type FCMMessage struct {
+	Token        string            `json:"token"`
+	Notification FCMNotification   `json:"notification"`
+	Data         map[string]string `json:"data,omitempty"`
+	Android      *FCMAndroid       `json:"android,omitempty"`
+	APNs         *FCMAPNs          `json:"apns,omitempty"`
+}
+
+// FCMNotification is the notification shown on all platforms.
+type FCMNotification struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+}
+
+// FCMAndroid holds the Android specific options of a message.
+type FCMAndroid struct {
+	Priority     string                 `json:"priority,omitempty"`
+	CollapseKey  string                 `json:"collapse_key,omitempty"`
+	Notification FCMAndroidNotification `json:"notification"`
+}
+
+// FCMAndroidNotification holds the Android specific options of a notification.
+type FCMAndroidNotification struct {
+	Sound             string `json:"sound,omitempty"`
+	NotificationCount *int   `json:"notification_count,omitempty"`
+}
+
+// FCMAPNs holds the options passed on to APNs for iOS apps.
+type FCMAPNs struct {
+	Payload map[string]any `json:"payload"`
+}
+
+// fcmError is the error response of the FCM API.
+type fcmError struct {
+	Error struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// NewFCM creates a new FCM channel instance. It fails if the service account credentials are invalid.
+func NewFCM(config FCMConfig, client *http.Client, tokens TokenStore) (*FCM, error) {
+	var account fcmServiceAccount
+	if err := json.Unmarshal(config.CredentialsJSON, &account); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFCMCredentials, err)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, fmt.Errorf("%w: missing client_email or private_key", ErrInvalidFCMCredentials)
+	}
+	key, err := parsePrivateKey([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFCMCredentials, err)
+	}
+
+	if config.ProjectID == "" {
+		config.ProjectID = account.ProjectID
+	}
+	if config.ProjectID == "" {
+		return nil, fmt.Errorf("%w: missing project id", ErrInvalidFCMCredentials)
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = FCMAPIURL
+	}
+	if config.TokenURL == "" {
+		config.TokenURL = account.TokenURI
+	}
+	if config.TokenURL == "" {
+		config.TokenURL = "https://oauth2.googleapis.com/token"
+	}
+
+	return &FCM{
+		config:      config,
+		name:        senderName(config.Name, "FCM"),
+		client:      client,
+		tokens:      tokens,
+		clientEmail: account.ClientEmail,
+		key:         key,
+	}, nil
+}
+
+// Send sends a message to each of its device tokens, skipping tokens that were
+// invalidated before. Tokens FCM reports as unregistered are invalidated.
+func (f *FCM) Send(ctx context.Context, message Message) error {
+	tokens, err := pushTokens(f.tokens, f.name, message)
+	if err != nil {
+		return err
+	}
+	if err := sendToTokens(ctx, tokens, func(ctx context.Context, token string) error {
+		return f.sendTo(ctx, token, message)
+	}); err != nil {
+		return err
+	}
+	log.Printf("fcm message sent: %s", message)
+
+	return nil
+}
+
+// GetName returns the name of the FCM sender.
+func (f *FCM) GetName() string {
+	return f.name
+}
+
+// sendTo sends a message to a single device token.
+func (f *FCM) sendTo(ctx context.Context, token string, message Message) error {
+	accessToken, err := f.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Create a JSON request body.
+	reqBody, err := json.Marshal(map[string]FCMMessage{"message": fcmMessage(token, message)})
+	if err != nil {
+		return Permanent(err)
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/projects/%s/messages:send", strings.TrimSuffix(f.config.BaseURL, "/"), url.PathEscape(f.config.ProjectID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(reqBody))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return Retryable(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	// Invalidate tokens of uninstalled apps and refresh expired access tokens, and
+	// classify all other failures so the notifier knows whether to retry.
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	var result fcmError
+	_ = json.Unmarshal(body, &result)
+	for _, detail := range result.Error.Details {
+		if detail.ErrorCode == "UNREGISTERED" {
+			return invalidateToken(f.tokens, f.name, token, "UNREGISTERED")
+		}
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		f.resetToken()
+		return Retryable(fmt.Errorf("fcm access token rejected: %s", result.Error.Message))
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return CheckResponse(resp)
+}
+
+// token returns a cached OAuth access token, requesting a new one shortly before it expires.
+func (f *FCM) token(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.accessToken != "" && time.Now().Before(f.expiresAt) {
+		return f.accessToken, nil
+	}
+
+	// Exchange a JWT signed with the service account key for an access token.
+	now := time.Now()
+	assertion, err := signJWT(f.key, map[string]any{}, map[string]any{
+		"iss":   f.clientEmail,
+		"scope": fcmScope,
+		"aud":   f.config.TokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", Permanent(fmt.Errorf("signing fcm token request: %w", err))
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", Retryable(err)
+	}
+	defer resp.Body.Close()
+	if err := CheckResponse(resp); err != nil {
+		return "", fmt.Errorf("requesting fcm access token: %w", err)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil || result.AccessToken == "" {
+		return "", Retryable(fmt.Errorf("invalid fcm access token response: %v", err))
+	}
+
+	// Renew the token a minute early so it does not expire during a request.
+	f.accessToken = result.AccessToken
+	f.expiresAt = now.Add(time.Duration(result.ExpiresIn)*time.Second - time.Minute)
+	return f.accessToken, nil
+}
+
+// resetToken forgets the cached access token.
+func (f *FCM) resetToken() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.accessToken = ""
+}
+
+// fcmMessage builds the FCM message sent to a device token.
+func fcmMessage(token string, message Message) FCMMessage {
+	result := FCMMessage{
+		Token: token,
+		Notification: FCMNotification{
+			Title: message.Title,
+			Body:  message.Text(),
+		},
+		Android: &FCMAndroid{CollapseKey: message.CorrelationKey},
+	}
+	aps := map[string]any{}
+	if message.CorrelationKey != "" {
+		aps["thread-id"] = message.CorrelationKey
+	}
+
+	// Deliver urgent messages right away, even to devices in doze mode.
+	switch message.SeverityOrDefault() {
+	case SeverityError, SeverityCritical:
+		result.Android.Priority = "high"
+	default:
+		result.Android.Priority = "normal"
+	}
+
+	if push := message.Push; push != nil {
+		result.Data = push.Data
+		result.Android.Notification.Sound = push.Sound
+		result.Android.Notification.NotificationCount = push.Badge
+		if push.Sound != "" {
+			aps["sound"] = push.Sound
+		}
+		if push.Badge != nil {
+			aps["badge"] = *push.Badge
+		}
+	}
+	if len(aps) > 0 {
+		result.APNs = &FCMAPNs{Payload: map[string]any{"aps": aps}}
+	}
+	return result
+}
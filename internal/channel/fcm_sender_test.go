@@ -0,0 +1,205 @@
+package channel
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestFCM creates an FCM sender whose API and token endpoint are served by handler.
+func newTestFCM(t *testing.T, handler http.HandlerFunc) (*FCM, *MemoryTokenStore) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	credentials, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"project_id":   "app",
+		"client_email": "notifier@app.iam.gserviceaccount.com",
+		"private_key":  string(pemKey(t, key)),
+		"token_uri":    server.URL + "/token",
+	})
+	require.NoError(t, err)
+	tokens := NewMemoryTokenStore()
+	f, err := NewFCM(FCMConfig{CredentialsJSON: credentials, BaseURL: server.URL}, server.Client(), tokens)
+	require.NoError(t, err)
+	return f, tokens
+}
+
+func TestNewFCM(t *testing.T) {
+	tests := []struct {
+		name        string
+		credentials string
+	}{
+		{
+			name:        "Credentials that are not JSON return error",
+			credentials: "not json",
+		},
+		{
+			name:        "Credentials without private key return error",
+			credentials: `{"project_id": "app", "client_email": "notifier@app.iam.gserviceaccount.com"}`,
+		},
+		{
+			name:        "Credentials with invalid private key return error",
+			credentials: `{"project_id": "app", "client_email": "notifier@app.iam.gserviceaccount.com", "private_key": "key"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFCM(FCMConfig{CredentialsJSON: []byte(tt.credentials)}, http.DefaultClient, NewMemoryTokenStore())
+			assert.ErrorIs(t, err, ErrInvalidFCMCredentials)
+		})
+	}
+}
+
+func TestFCM_Send(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		response         string
+		expectErr        string
+		expectPermanent  bool
+		expectInvalid    bool
+		expectTokenCalls int
+	}{
+		{
+			name:             "Sending message returns no error",
+			status:           http.StatusOK,
+			response:         `{"name": "projects/app/messages/1"}`,
+			expectTokenCalls: 1,
+		},
+		{
+			name:             "Sending to an unregistered token invalidates it",
+			status:           http.StatusNotFound,
+			response:         `{"error": {"code": 404, "status": "NOT_FOUND", "details": [{"@type": "type.googleapis.com/google.firebase.fcm.v1.FcmError", "errorCode": "UNREGISTERED"}]}}`,
+			expectErr:        "device-1: device token was invalidated: UNREGISTERED",
+			expectPermanent:  true,
+			expectInvalid:    true,
+			expectTokenCalls: 1,
+		},
+		{
+			name:             "Sending with a rejected access token requests a new one",
+			status:           http.StatusUnauthorized,
+			response:         `{"error": {"code": 401, "message": "Request had invalid authentication credentials.", "status": "UNAUTHENTICATED"}}`,
+			expectErr:        "device-1: fcm access token rejected: Request had invalid authentication credentials.",
+			expectTokenCalls: 2,
+		},
+		{
+			name:             "Sending an invalid message returns permanent error",
+			status:           http.StatusBadRequest,
+			response:         `{"error": {"code": 400, "status": "INVALID_ARGUMENT"}}`,
+			expectErr:        `device-1: request failed: {"error": {"code": 400, "status": "INVALID_ARGUMENT"}}`,
+			expectPermanent:  true,
+			expectTokenCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]FCMMessage
+			tokenCalls := 0
+			f, tokens := newTestFCM(t, func(rw http.ResponseWriter, req *http.Request) {
+				if req.URL.Path == "/token" {
+					tokenCalls++
+					assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", req.FormValue("grant_type"))
+					_, claims := decodeJWT(t, req.FormValue("assertion"))
+					assert.Equal(t, fcmScope, claims["scope"])
+					rw.Write([]byte(`{"access_token": "access", "expires_in": 3599, "token_type": "Bearer"}`))
+					return
+				}
+				assert.Equal(t, "/v1/projects/app/messages:send", req.URL.Path)
+				assert.Equal(t, "Bearer access", req.Header.Get("Authorization"))
+				assert.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+				rw.WriteHeader(tt.status)
+				rw.Write([]byte(tt.response))
+			})
+
+			err := f.Send(context.Background(), Message{Title: "Deploy", Body: "Hello", Recipients: []string{"device-1"}})
+			assert.Equal(t, "device-1", body["message"].Token)
+			assert.Equal(t, FCMNotification{Title: "Deploy", Body: "Hello"}, body["message"].Notification)
+
+			// A second message reuses the access token unless it was rejected
+			_ = f.Send(context.Background(), Message{Body: "Hello", Recipients: []string{"device-2"}})
+			assert.Equal(t, tt.expectTokenCalls, tokenCalls)
+
+			invalid, _ := tokens.IsInvalid("FCM", "device-1")
+			assert.Equal(t, tt.expectInvalid, invalid)
+			if tt.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expectErr)
+			assert.Equal(t, tt.expectPermanent, IsPermanent(err))
+		})
+	}
+}
+
+func TestFCM_SendProgress(t *testing.T) {
+	var sent []string
+	f, _ := newTestFCM(t, func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/token" {
+			rw.Write([]byte(`{"access_token": "access", "expires_in": 3599, "token_type": "Bearer"}`))
+			return
+		}
+		var body map[string]FCMMessage
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		sent = append(sent, body["message"].Token)
+
+		// The second device is unavailable once
+		if body["message"].Token == "device-2" && len(sent) == 2 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.Write([]byte(`{"name": "projects/app/messages/1"}`))
+	})
+	progress := NewProgress(nil)
+	ctx := WithProgress(context.Background(), progress)
+	message := Message{Body: "Hello", Recipients: []string{"device-1", "device-2"}}
+
+	err := f.Send(ctx, message)
+	assert.Error(t, err)
+	assert.False(t, IsPermanent(err))
+	assert.Equal(t, []string{"device-1"}, progress.Targets())
+
+	// The retry skips the device that already got the message
+	assert.NoError(t, f.Send(ctx, message))
+	assert.Equal(t, []string{"device-1", "device-2", "device-2"}, sent)
+}
+
+func TestFCMMessage(t *testing.T) {
+	badge := 3
+	message := Message{
+		Title:          "Disk full",
+		Body:           "Disk is full",
+		Severity:       SeverityCritical,
+		CorrelationKey: "disk",
+		Push:           &PushOptions{Badge: &badge, Sound: "default", Data: map[string]string{"host": "db-1"}},
+	}
+
+	result := fcmMessage("device-1", message)
+	assert.Equal(t, FCMMessage{
+		Token:        "device-1",
+		Notification: FCMNotification{Title: "Disk full", Body: "Disk is full"},
+		Data:         map[string]string{"host": "db-1"},
+		Android: &FCMAndroid{
+			Priority:     "high",
+			CollapseKey:  "disk",
+			Notification: FCMAndroidNotification{Sound: "default", NotificationCount: &badge},
+		},
+		APNs: &FCMAPNs{Payload: map[string]any{"aps": map[string]any{"badge": 3, "sound": "default", "thread-id": "disk"}}},
+	}, result)
+
+	// Messages without push options have no APNs payload
+	result = fcmMessage("device-1", Message{Body: "Hello"})
+	assert.Equal(t, "normal", result.Android.Priority)
+	assert.Nil(t, result.APNs)
+}
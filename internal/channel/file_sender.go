@@ -0,0 +1,80 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+var ErrNoFilePath = errors.New("file channel requires a path")
+
+// FileConfig holds the settings of the File channel.
+type FileConfig struct {
+	Path       string        // File notifications are appended to.
+	MaxSize    int64         // Size in bytes after which the file is rotated, unlimited when 0.
+	MaxAge     time.Duration // Rotates the file when a period of this length starts, such as 24h at midnight UTC.
+	MaxBackups int           // Number of rotated files kept, all when 0.
+	Compress   bool          // Compresses rotated files with gzip.
+	Source     string        // Source of the events, defaults to /notification-service.
+	Name       string        // Channel name, defaults to File.
+}
+
+// File represents a channel appending notifications to a local file as JSON lines,
+// one CloudEvent per line, for log pipelines and audit trails. Recipients are ignored.
+type File struct {
+	config FileConfig
+	name   string // The name of the sender.
+	file   *rotatingFile
+	now    func() time.Time // Clock used for the event time.
+}
+
+// NewFile creates a new File channel instance and opens the file, so missing
+// permissions are reported on startup.
+func NewFile(config FileConfig) (*File, error) {
+	if config.Path == "" {
+		return nil, ErrNoFilePath
+	}
+	f := &File{
+		config: config,
+		name:   senderName(config.Name, "File"),
+		file: &rotatingFile{
+			path:       config.Path,
+			maxSize:    config.MaxSize,
+			period:     config.MaxAge,
+			maxBackups: config.MaxBackups,
+			compress:   config.Compress,
+			now:        time.Now,
+		},
+		now: time.Now,
+	}
+	if err := f.file.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Send appends a message to the file.
+func (f *File) Send(ctx context.Context, message Message) error {
+	_, body, err := encodeCloudEvent(ctx, f.config.Source, message, f.now())
+	if err != nil {
+		return Permanent(err)
+	}
+	// Failed writes, such as to a full disk, may succeed later.
+	if _, err := f.file.Write(append(body, '\n')); err != nil {
+		return Retryable(err)
+	}
+	log.Printf("file message sent: %s", message)
+
+	return nil
+}
+
+// GetName returns the name of the File sender.
+func (f *File) GetName() string {
+	return f.name
+}
+
+// Close closes the file.
+func (f *File) Close() error {
+	return f.file.Close()
+}
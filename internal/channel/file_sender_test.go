@@ -0,0 +1,51 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile_Send(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "notifications.log")
+	sender, err := NewFile(FileConfig{Path: path})
+	require.NoError(t, err)
+	defer sender.Close()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	sender.now = func() time.Time { return now }
+
+	// Each message is appended as one line
+	require.NoError(t, sender.Send(WithNotificationID(context.Background(), "n-1"), Message{Title: "Disk full", Body: "Disk of db-1\nis full"}))
+	require.NoError(t, sender.Send(WithNotificationID(context.Background(), "n-2"), Message{Body: "Disk usage is back to 60%"}))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	require.Len(t, lines, 2)
+	var event CloudEvent
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &event))
+	assert.Equal(t, "n-1", event.ID)
+	assert.Equal(t, now, event.Time)
+	assert.Equal(t, "Disk of db-1\nis full", event.Data.Body)
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &event))
+	assert.Equal(t, "n-2", event.ID)
+}
+
+func TestNewFile(t *testing.T) {
+	// A path is required
+	_, err := NewFile(FileConfig{})
+	assert.ErrorIs(t, err, ErrNoFilePath)
+
+	// Files that cannot be created are reported on startup
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file"), nil, 0o640))
+	_, err = NewFile(FileConfig{Path: filepath.Join(dir, "file", "notifications.log")})
+	assert.Error(t, err)
+}
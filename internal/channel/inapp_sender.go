@@ -0,0 +1,78 @@
+package channel
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+var (
+	ErrNoInboxRecipients = errors.New("in-app notifications need user ids as recipients")
+)
+
+// InApp represents a channel adding notifications to the in-app inboxes of users.
+// Recipients are user IDs.
+type InApp struct {
+	name  string // The name of the sender.
+	inbox *Inbox
+}
+
+// NewInApp creates a new InApp channel instance adding notifications to inbox.
+func NewInApp(inbox *Inbox) *InApp {
+	return &InApp{
+		name:  "InApp",
+		inbox: inbox,
+	}
+}
+
+// Send adds a message to the inbox of each recipient. The item is named after the
+// notification, so a retried send does not add it twice.
+func (a *InApp) Send(ctx context.Context, message Message) error {
+	if len(message.Recipients) == 0 {
+		return Permanent(ErrNoInboxRecipients)
+	}
+	id := NotificationID(ctx)
+	if id == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return Retryable(err)
+		}
+		id = hex.EncodeToString(b)
+	}
+
+	// Store the message without its recipients and the content of attachments, which
+	// the inbox links to by URL.
+	item := message
+	item.Recipients = nil
+	item.Attachments = nil
+	for _, attachment := range message.Attachments {
+		if attachment.URL != "" {
+			attachment.Content = nil
+			item.Attachments = append(item.Attachments, attachment)
+		}
+	}
+
+	var errs []error
+	now := time.Now()
+	for _, userID := range message.Recipients {
+		err := a.inbox.Add(InboxItem{ID: id, UserID: userID, Message: item, CreatedAt: now, UpdatedAt: now})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", userID, Retryable(err)))
+		}
+	}
+	if err := JoinErrors(errs...); err != nil {
+		return err
+	}
+	log.Printf("in-app message sent: %s", message)
+
+	return nil
+}
+
+// GetName returns the name of the InApp sender.
+func (a *InApp) GetName() string {
+	return a.name
+}
@@ -0,0 +1,47 @@
+package channel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInApp_Send(t *testing.T) {
+	inbox := NewInbox(NewMemoryInboxStore())
+	a := NewInApp(inbox)
+	message := Message{
+		Title:      "Deploy",
+		Body:       "Hello",
+		Recipients: []string{"alice", "bob"},
+		Attachments: []Attachment{
+			{Filename: "log.txt", Content: []byte("log")},
+			{Filename: "report.pdf", Content: []byte("pdf"), URL: "https://example.com/report.pdf"},
+		},
+	}
+
+	// Each recipient gets the message once, even when the send is retried
+	ctx := WithNotificationID(context.Background(), "n-1")
+	require.NoError(t, a.Send(ctx, message))
+	require.NoError(t, a.Send(ctx, message))
+	for _, userID := range []string{"alice", "bob"} {
+		page, err := inbox.List(userID, InboxFilter{})
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		assert.Equal(t, "n-1", page.Items[0].ID)
+		assert.Equal(t, "Deploy", page.Items[0].Message.Title)
+
+		// Attachments are only kept as links
+		assert.Nil(t, page.Items[0].Message.Recipients)
+		assert.Equal(t, []Attachment{{Filename: "report.pdf", URL: "https://example.com/report.pdf"}}, page.Items[0].Message.Attachments)
+	}
+}
+
+func TestInApp_SendWithoutRecipients(t *testing.T) {
+	a := NewInApp(NewInbox(NewMemoryInboxStore()))
+
+	err := a.Send(context.Background(), Message{Body: "Hello"})
+	assert.ErrorIs(t, err, ErrNoInboxRecipients)
+	assert.True(t, IsPermanent(err))
+}
@@ -0,0 +1,231 @@
+package channel
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Inbox event types sent to the streams of users.
+const (
+	InboxEventCreated = "created"
+	InboxEventUpdated = "updated"
+)
+
+// inboxStreamBuffer is the number of events buffered for a stream. Events for a stream
+// that does not keep up are dropped rather than blocking senders.
+const inboxStreamBuffer = 16
+
+var (
+	ErrInboxItemNotFound = errors.New("inbox item not found")
+)
+
+// InboxItem is a notification in the in-app inbox of a user.
+type InboxItem struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Message   Message   `json:"message"`
+	Read      bool      `json:"read"`
+	Archived  bool      `json:"archived"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// InboxFilter selects a page of inbox items.
+type InboxFilter struct {
+	UnreadOnly bool // Only list unread items.
+	Archived   bool // List archived instead of active items.
+	Offset     int
+	Limit      int // Maximum number of items, 0 for all.
+}
+
+// InboxPage is a page of inbox items, newest first.
+type InboxPage struct {
+	Items  []InboxItem `json:"items"`
+	Total  int         `json:"total"`  // Number of items matching the filter.
+	Unread int         `json:"unread"` // Number of unread items that are not archived.
+}
+
+// InboxEvent is sent to the streams of a user when an item is created or updated.
+type InboxEvent struct {
+	Type string    `json:"type"`
+	Item InboxItem `json:"item"`
+}
+
+// InboxStore keeps the inbox items of users.
+type InboxStore interface {
+	// Add stores a new item. It reports false without changes if the user already has
+	// an item with the ID, so a retried send does not reset it.
+	Add(item InboxItem) (bool, error)
+	// List returns the items of a user matching the filter.
+	List(userID string, filter InboxFilter) (InboxPage, error)
+	// Update changes an item of a user. It returns ErrInboxItemNotFound if the user has
+	// no item with the ID.
+	Update(userID, id string, update func(item *InboxItem)) (InboxItem, error)
+}
+
+// MemoryInboxStore is an in-memory InboxStore. Its contents are lost when the process exits.
+type MemoryInboxStore struct {
+	mu    sync.RWMutex
+	items map[string][]InboxItem // Items of each user, oldest first.
+}
+
+// NewMemoryInboxStore creates a new MemoryInboxStore.
+func NewMemoryInboxStore() *MemoryInboxStore {
+	return &MemoryInboxStore{
+		items: make(map[string][]InboxItem),
+	}
+}
+
+// Add stores a new item unless the user already has an item with the ID.
+func (s *MemoryInboxStore) Add(item InboxItem) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.items[item.UserID] {
+		if existing.ID == item.ID {
+			return false, nil
+		}
+	}
+	s.items[item.UserID] = append(s.items[item.UserID], item)
+	return true, nil
+}
+
+// List returns the items of a user matching the filter.
+func (s *MemoryInboxStore) List(userID string, filter InboxFilter) (InboxPage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return FilterInbox(s.items[userID], filter), nil
+}
+
+// Update changes an item of a user.
+func (s *MemoryInboxStore) Update(userID, id string, update func(item *InboxItem)) (InboxItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, item := range s.items[userID] {
+		if item.ID == id {
+			update(&item)
+			s.items[userID][i] = item
+			return item, nil
+		}
+	}
+	return InboxItem{}, ErrInboxItemNotFound
+}
+
+// FilterInbox returns the page of a user's items, given oldest first, that matches the filter.
+func FilterInbox(items []InboxItem, filter InboxFilter) InboxPage {
+	page := InboxPage{Items: []InboxItem{}}
+	for i := len(items) - 1; i >= 0; i-- {
+		item := items[i]
+		if !item.Read && !item.Archived {
+			page.Unread++
+		}
+		if item.Archived != filter.Archived || (filter.UnreadOnly && item.Read) {
+			continue
+		}
+		if page.Total >= filter.Offset && (filter.Limit == 0 || len(page.Items) < filter.Limit) {
+			page.Items = append(page.Items, item)
+		}
+		page.Total++
+	}
+	return page
+}
+
+// Inbox stores in-app notifications and streams changes to the users' open connections.
+type Inbox struct {
+	store InboxStore
+
+	mu      sync.Mutex
+	streams map[string]map[chan InboxEvent]struct{} // Open streams of each user.
+	closed  bool
+}
+
+// NewInbox creates a new Inbox keeping items in store.
+func NewInbox(store InboxStore) *Inbox {
+	return &Inbox{
+		store:   store,
+		streams: make(map[string]map[chan InboxEvent]struct{}),
+	}
+}
+
+// Add stores a new item and streams it to the user. Items already in the inbox are left unchanged.
+func (i *Inbox) Add(item InboxItem) error {
+	added, err := i.store.Add(item)
+	if err != nil || !added {
+		return err
+	}
+	i.publish(InboxEvent{Type: InboxEventCreated, Item: item})
+	return nil
+}
+
+// List returns the items of a user matching the filter.
+func (i *Inbox) List(userID string, filter InboxFilter) (InboxPage, error) {
+	return i.store.List(userID, filter)
+}
+
+// Update changes an item of a user and streams the change to the user.
+func (i *Inbox) Update(userID, id string, update func(item *InboxItem)) (InboxItem, error) {
+	item, err := i.store.Update(userID, id, func(item *InboxItem) {
+		update(item)
+		item.UpdatedAt = time.Now()
+	})
+	if err != nil {
+		return InboxItem{}, err
+	}
+	i.publish(InboxEvent{Type: InboxEventUpdated, Item: item})
+	return item, nil
+}
+
+// Subscribe opens a stream of the changes to a user's inbox. The stream is closed by
+// calling the returned function or when the inbox is closed.
+func (i *Inbox) Subscribe(userID string) (<-chan InboxEvent, func()) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	events := make(chan InboxEvent, inboxStreamBuffer)
+	if i.closed {
+		close(events)
+		return events, func() {}
+	}
+	if i.streams[userID] == nil {
+		i.streams[userID] = make(map[chan InboxEvent]struct{})
+	}
+	i.streams[userID][events] = struct{}{}
+
+	return events, func() {
+		i.mu.Lock()
+		defer i.mu.Unlock()
+		if _, open := i.streams[userID][events]; !open {
+			return
+		}
+		delete(i.streams[userID], events)
+		if len(i.streams[userID]) == 0 {
+			delete(i.streams, userID)
+		}
+		close(events)
+	}
+}
+
+// Close closes all streams, for example so that a server can shut down.
+func (i *Inbox) Close() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.closed = true
+	for userID, streams := range i.streams {
+		for events := range streams {
+			close(events)
+		}
+		delete(i.streams, userID)
+	}
+}
+
+// publish sends an event to the open streams of its user.
+func (i *Inbox) publish(event InboxEvent) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for events := range i.streams[event.Item.UserID] {
+		select {
+		case events <- event:
+		default:
+			// The stream does not keep up, the client can catch up by listing the inbox.
+		}
+	}
+}
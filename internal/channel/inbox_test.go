@@ -0,0 +1,138 @@
+package channel
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterInbox(t *testing.T) {
+	// Five items, oldest first: 1 is read, 2 is archived.
+	var items []InboxItem
+	for i := 1; i <= 5; i++ {
+		items = append(items, InboxItem{ID: fmt.Sprint(i), Read: i == 1, Archived: i == 2})
+	}
+
+	tests := []struct {
+		name        string
+		filter      InboxFilter
+		expectIDs   []string
+		expectTotal int
+	}{
+		{
+			name:        "All active items newest first",
+			filter:      InboxFilter{},
+			expectIDs:   []string{"5", "4", "3", "1"},
+			expectTotal: 4,
+		},
+		{
+			name:        "Page of active items",
+			filter:      InboxFilter{Offset: 1, Limit: 2},
+			expectIDs:   []string{"4", "3"},
+			expectTotal: 4,
+		},
+		{
+			name:        "Page past the end",
+			filter:      InboxFilter{Offset: 10, Limit: 2},
+			expectIDs:   []string{},
+			expectTotal: 4,
+		},
+		{
+			name:        "Unread items",
+			filter:      InboxFilter{UnreadOnly: true},
+			expectIDs:   []string{"5", "4", "3"},
+			expectTotal: 3,
+		},
+		{
+			name:        "Archived items",
+			filter:      InboxFilter{Archived: true},
+			expectIDs:   []string{"2"},
+			expectTotal: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := FilterInbox(items, tt.filter)
+			ids := []string{}
+			for _, item := range page.Items {
+				ids = append(ids, item.ID)
+			}
+			assert.Equal(t, tt.expectIDs, ids)
+			assert.Equal(t, tt.expectTotal, page.Total)
+			assert.Equal(t, 3, page.Unread)
+		})
+	}
+}
+
+func TestMemoryInboxStore(t *testing.T) {
+	store := NewMemoryInboxStore()
+
+	// Items are added once per user
+	added, err := store.Add(InboxItem{ID: "1", UserID: "alice"})
+	assert.NoError(t, err)
+	assert.True(t, added)
+	added, err = store.Add(InboxItem{ID: "1", UserID: "alice", Read: true})
+	assert.NoError(t, err)
+	assert.False(t, added)
+	added, err = store.Add(InboxItem{ID: "1", UserID: "bob"})
+	assert.NoError(t, err)
+	assert.True(t, added)
+
+	item, err := store.Update("alice", "1", func(item *InboxItem) { item.Read = true })
+	assert.NoError(t, err)
+	assert.True(t, item.Read)
+	_, err = store.Update("alice", "2", func(item *InboxItem) {})
+	assert.Equal(t, ErrInboxItemNotFound, err)
+
+	page, err := store.List("alice", InboxFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, page.Total)
+	assert.Equal(t, 0, page.Unread)
+}
+
+func TestInbox_Subscribe(t *testing.T) {
+	inbox := NewInbox(NewMemoryInboxStore())
+	alice, cancel := inbox.Subscribe("alice")
+	bob, _ := inbox.Subscribe("bob")
+
+	// Streams receive the changes to their user's inbox
+	require.NoError(t, inbox.Add(InboxItem{ID: "1", UserID: "alice"}))
+	require.NoError(t, inbox.Add(InboxItem{ID: "1", UserID: "alice"}))
+	_, err := inbox.Update("alice", "1", func(item *InboxItem) { item.Read = true })
+	require.NoError(t, err)
+
+	event := <-alice
+	assert.Equal(t, InboxEventCreated, event.Type)
+	event = <-alice
+	assert.Equal(t, InboxEventUpdated, event.Type)
+	assert.True(t, event.Item.Read)
+	assert.False(t, event.Item.UpdatedAt.IsZero())
+	assert.Empty(t, alice)
+	assert.Empty(t, bob)
+
+	// Streams are closed when cancelled or when the inbox is closed
+	cancel()
+	cancel()
+	_, open := <-alice
+	assert.False(t, open)
+	inbox.Close()
+	_, open = <-bob
+	assert.False(t, open)
+	closed, _ := inbox.Subscribe("alice")
+	_, open = <-closed
+	assert.False(t, open)
+}
+
+func TestInbox_SlowStream(t *testing.T) {
+	inbox := NewInbox(NewMemoryInboxStore())
+	events, _ := inbox.Subscribe("alice")
+
+	// Events for a stream that is not read are dropped once its buffer is full
+	for i := 0; i < inboxStreamBuffer+5; i++ {
+		require.NoError(t, inbox.Add(InboxItem{ID: fmt.Sprint(i), UserID: "alice"}))
+	}
+	assert.Len(t, events, inboxStreamBuffer)
+}
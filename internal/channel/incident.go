@@ -0,0 +1,50 @@
+package channel
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// incidentSource is the default source reported to incident management services.
+const incidentSource = "notification-service"
+
+// incidentKey returns the correlation key of a message as the deduplication key of an
+// incident. Keys longer than the service accepts are hashed, so that every message with
+// the same key still maps to the same incident.
+func incidentKey(key string, max int) string {
+	if len(key) <= max {
+		return key
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// messageIncidentKey returns the key of the incident of a message: its correlation key,
+// or for triggers without one the ID of the notification, so that retries of a trigger
+// do not open an incident each.
+func messageIncidentKey(ctx context.Context, message Message, max int) string {
+	key := message.CorrelationKey
+	if key == "" && message.ActionOrDefault() == ActionTrigger {
+		key = NotificationID(ctx)
+	}
+	return incidentKey(key, max)
+}
+
+// incidentDescription returns the text of a message followed by its links, as incident
+// services show them in the description. The text is left out if it is the summary.
+func incidentDescription(message Message, summary string) string {
+	lines := []string{}
+	if text := message.Text(); text != summary {
+		lines = append(lines, text)
+	}
+	for _, link := range message.Links {
+		if link.Title != "" {
+			lines = append(lines, link.Title+": "+link.URL)
+		} else {
+			lines = append(lines, link.URL)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,124 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// kafkaBatchTimeout is how long the writer waits for more messages of a batch. Sends
+// wait for their batch, so it is kept short.
+const kafkaBatchTimeout = 10 * time.Millisecond
+
+// KafkaConfig holds the settings of the Kafka channel.
+type KafkaConfig struct {
+	Brokers []string // Addresses of the bootstrap brokers, such as kafka:9092.
+	Topic   string   // Topic of messages without recipients.
+	Source  string   // Source of the events, defaults to /notification-service.
+	Name    string   // Channel name, defaults to Kafka.
+}
+
+// Kafka represents a channel publishing notifications as CloudEvents to Kafka topics.
+// Recipients are topics overriding the configured one. Messages are keyed by their
+// correlation key, so related messages keep their order, and a send succeeds once all
+// in-sync replicas acknowledged it.
+type Kafka struct {
+	config KafkaConfig
+	name   string // The name of the sender.
+	writer kafkaWriter
+	now    func() time.Time // Clock used for the event time.
+}
+
+// kafkaWriter writes messages to Kafka, implemented by kafka.Writer.
+type kafkaWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// NewKafka creates a new Kafka channel instance. Connections are opened on the first send.
+func NewKafka(config KafkaConfig) *Kafka {
+	return &Kafka{
+		config: config,
+		name:   senderName(config.Name, "Kafka"),
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(config.Brokers...),
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			BatchTimeout: kafkaBatchTimeout,
+		},
+		now: time.Now,
+	}
+}
+
+// Send publishes a message to each topic.
+func (k *Kafka) Send(ctx context.Context, message Message) error {
+	topics := message.Recipients
+	if len(topics) == 0 {
+		topics = []string{k.config.Topic}
+	}
+	event, body, err := encodeCloudEvent(ctx, k.config.Source, message, k.now())
+	if err != nil {
+		return Permanent(err)
+	}
+	key := message.CorrelationKey
+	if key == "" {
+		key = event.ID
+	}
+
+	msgs := make([]kafka.Message, len(topics))
+	for i, topic := range topics {
+		msgs[i] = kafka.Message{
+			Topic:   topic,
+			Key:     []byte(key),
+			Value:   body,
+			Headers: []kafka.Header{{Key: "content-type", Value: []byte(CloudEventContentType)}},
+			Time:    event.Time,
+		}
+	}
+	if err := k.writer.WriteMessages(ctx, msgs...); err != nil {
+		return kafkaError(topics, err)
+	}
+	log.Printf("kafka message sent: %s", message)
+
+	return nil
+}
+
+// GetName returns the name of the Kafka sender.
+func (k *Kafka) GetName() string {
+	return k.name
+}
+
+// Close flushes pending messages and closes the connections to the brokers.
+func (k *Kafka) Close() error {
+	return k.writer.Close()
+}
+
+// kafkaError classifies the error of a write, which reports the errors of each message.
+func kafkaError(topics []string, err error) error {
+	var writeErrs kafka.WriteErrors
+	if !errors.As(err, &writeErrs) {
+		return kafkaErrorClass(err)
+	}
+	var errs []error
+	for i, writeErr := range writeErrs {
+		if writeErr != nil && i < len(topics) {
+			errs = append(errs, fmt.Errorf("%s: %w", topics[i], kafkaErrorClass(writeErr)))
+		}
+	}
+	return JoinErrors(errs...)
+}
+
+// kafkaErrorClass classifies the error of a single message. Errors the brokers report
+// as temporary, such as a leader election, are retried, as are network errors. Other
+// broker errors, such as too large messages or missing permissions, are permanent.
+func kafkaErrorClass(err error) error {
+	var kafkaErr kafka.Error
+	if errors.As(err, &kafkaErr) && !kafkaErr.Temporary() {
+		return Permanent(err)
+	}
+	return Retryable(err)
+}
@@ -0,0 +1,120 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKafkaWriter records written messages and returns err.
+type fakeKafkaWriter struct {
+	written []kafka.Message
+	err     error
+	closed  bool
+}
+
+func (w *fakeKafkaWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	w.written = append(w.written, msgs...)
+	return w.err
+}
+
+func (w *fakeKafkaWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestKafka_Send(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name            string
+		message         Message
+		err             error
+		expectTopics    []string
+		expectKey       string
+		expectErr       string
+		expectPermanent bool
+	}{
+		{
+			name:         "Writing to the configured topic returns no error",
+			message:      Message{Title: "Disk full", Body: "Disk of db-1 is full"},
+			expectTopics: []string{"notifications"},
+			expectKey:    "n-1",
+		},
+		{
+			name:         "Recipients override the topic and messages are keyed by correlation key",
+			message:      Message{Body: "Disk full", Recipients: []string{"ops", "dev"}, CorrelationKey: "disk-db-1"},
+			expectTopics: []string{"ops", "dev"},
+			expectKey:    "disk-db-1",
+		},
+		{
+			name:            "Messages rejected by the broker are permanent",
+			message:         Message{Body: "Disk full", Recipients: []string{"ops", "dev"}},
+			err:             kafka.WriteErrors{nil, kafka.MessageSizeTooLarge},
+			expectTopics:    []string{"ops", "dev"},
+			expectKey:       "n-1",
+			expectErr:       "dev: [10] Message Size Too Large",
+			expectPermanent: true,
+		},
+		{
+			name:         "Leader elections are retried",
+			message:      Message{Body: "Disk full"},
+			err:          kafka.WriteErrors{kafka.NotLeaderForPartition},
+			expectTopics: []string{"notifications"},
+			expectKey:    "n-1",
+			expectErr:    "notifications: [6] Not Leader For Partition",
+		},
+		{
+			name:         "Network errors are retried",
+			message:      Message{Body: "Disk full"},
+			err:          errors.New("dial tcp: connection refused"),
+			expectTopics: []string{"notifications"},
+			expectKey:    "n-1",
+			expectErr:    "connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &fakeKafkaWriter{err: tt.err}
+			sender := NewKafka(KafkaConfig{Brokers: []string{"kafka:9092"}, Topic: "notifications"})
+			sender.writer = writer
+			sender.now = func() time.Time { return now }
+
+			err := sender.Send(WithNotificationID(context.Background(), "n-1"), tt.message)
+
+			if tt.expectErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectErr)
+				assert.Equal(t, tt.expectPermanent, IsPermanent(err))
+			} else {
+				assert.NoError(t, err)
+			}
+			var topics []string
+			for _, msg := range writer.written {
+				topics = append(topics, msg.Topic)
+				assert.Equal(t, tt.expectKey, string(msg.Key))
+				assert.Equal(t, []kafka.Header{{Key: "content-type", Value: []byte(CloudEventContentType)}}, msg.Headers)
+				assert.Equal(t, now, msg.Time)
+				var event CloudEvent
+				require.NoError(t, json.Unmarshal(msg.Value, &event))
+				assert.Equal(t, "n-1", event.ID)
+			}
+			assert.Equal(t, tt.expectTopics, topics)
+		})
+	}
+}
+
+func TestKafka_Close(t *testing.T) {
+	writer := &fakeKafkaWriter{}
+	sender := NewKafka(KafkaConfig{Brokers: []string{"kafka:9092"}, Topic: "notifications"})
+	sender.writer = writer
+
+	require.NoError(t, sender.Close())
+	assert.True(t, writer.closed)
+}
@@ -0,0 +1,61 @@
+package channel
+
+import (
+	"sync"
+	"time"
+)
+
+// lookupLimit is the number of lookups cached before expired entries are dropped.
+const lookupLimit = 10000
+
+// lookupCache caches the IDs names resolve to for a limited time, such as the IDs of
+// Slack channels and users or the room IDs of Matrix aliases.
+type lookupCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]lookup
+}
+
+// lookup is a cached ID.
+type lookup struct {
+	id      string
+	expires time.Time
+}
+
+// newLookupCache creates a new lookupCache keeping entries for ttl.
+func newLookupCache(ttl time.Duration) *lookupCache {
+	return &lookupCache{
+		ttl:     ttl,
+		entries: make(map[string]lookup),
+	}
+}
+
+// get returns a cached ID that has not expired yet.
+func (c *lookupCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, found := c.entries[key]
+	if !found || time.Now().After(entry.expires) {
+		return "", false
+	}
+	return entry.id, true
+}
+
+// set caches an ID, dropping expired entries once the cache is full.
+func (c *lookupCache) set(key, id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.entries) >= lookupLimit {
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	if len(c.entries) >= lookupLimit {
+		c.entries = make(map[string]lookup)
+	}
+	c.entries[key] = lookup{id: id, expires: now.Add(c.ttl)}
+}
@@ -0,0 +1,76 @@
+package channel
+
+import (
+	"html"
+	"strings"
+)
+
+// matrixMaxBody is the number of characters of the body sent, keeping events well below
+// the 64 KiB limit of the Matrix specification.
+const matrixMaxBody = 8000
+
+// matrixHTMLFormat is the format of the HTML body of an event.
+const matrixHTMLFormat = "org.matrix.custom.html"
+
+// Matrix message types of notifications. Notices are meant for automated messages,
+// which clients show less prominently and bots do not respond to.
+const (
+	MatrixText   = "m.text"
+	MatrixNotice = "m.notice"
+)
+
+// MatrixMessage is the content of an m.room.message event.
+type MatrixMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format,omitempty"`
+	FormattedBody string `json:"formatted_body,omitempty"`
+}
+
+// matrixMessage renders a message as an event with a plain text body and an HTML body.
+// The title is shown in bold, prefixed with the severity emoji, followed by the body,
+// metadata, links and tags.
+func matrixMessage(message Message, msgType string) MatrixMessage {
+	var plain, formatted []string
+
+	// Start with the title, using the emoji of Telegram as neither has colours.
+	title := telegramSeverityEmoji[message.SeverityOrDefault()]
+	if message.Title != "" {
+		title += " " + message.Title
+	}
+	plain = append(plain, title)
+	formatted = append(formatted, "<p><strong>"+html.EscapeString(title)+"</strong></p>")
+
+	// Keep line breaks of the plain body.
+	if text := message.Text(); text != message.Title {
+		text = truncate(text, matrixMaxBody)
+		plain = append(plain, text)
+		formatted = append(formatted, "<p>"+strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")+"</p>")
+	}
+
+	// List metadata and links, followed by the tags.
+	var items []string
+	for _, key := range message.MetadataKeys() {
+		plain = append(plain, key+": "+message.Metadata[key])
+		items = append(items, "<li><strong>"+html.EscapeString(key)+":</strong> "+html.EscapeString(message.Metadata[key])+"</li>")
+	}
+	for _, link := range slackLinks(message) {
+		plain = append(plain, link.title()+": "+link.URL)
+		items = append(items, `<li><a href="`+html.EscapeString(link.URL)+`">`+html.EscapeString(link.title())+"</a></li>")
+	}
+	if len(items) > 0 {
+		formatted = append(formatted, "<ul>"+strings.Join(items, "")+"</ul>")
+	}
+	if len(message.Tags) > 0 {
+		tags := strings.Join(message.Tags, ", ")
+		plain = append(plain, "Tags: "+tags)
+		formatted = append(formatted, "<p><em>Tags: "+html.EscapeString(tags)+"</em></p>")
+	}
+
+	return MatrixMessage{
+		MsgType:       msgType,
+		Body:          strings.Join(plain, "\n"),
+		Format:        matrixHTMLFormat,
+		FormattedBody: strings.Join(formatted, ""),
+	}
+}
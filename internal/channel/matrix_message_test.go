@@ -0,0 +1,55 @@
+package channel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatrixMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  Message
+		msgType  string
+		expected MatrixMessage
+	}{
+		{
+			name: "Message with all fields",
+			message: Message{
+				Title:    "Deploy <prod> failed",
+				Body:     "Step build failed\nsee logs",
+				Severity: SeverityError,
+				Tags:     []string{"ci"},
+				Metadata: map[string]string{"branch": "a&b"},
+				Links:    []Link{{Title: "Pipeline", URL: "https://ci.example.com/1?a=1&b=2"}},
+			},
+			msgType: MatrixText,
+			expected: MatrixMessage{
+				MsgType: "m.text",
+				Body:    "❌ Deploy <prod> failed\nStep build failed\nsee logs\nbranch: a&b\nPipeline: https://ci.example.com/1?a=1&b=2\nTags: ci",
+				Format:  "org.matrix.custom.html",
+				FormattedBody: "<p><strong>❌ Deploy &lt;prod&gt; failed</strong></p>" +
+					"<p>Step build failed<br>see logs</p>" +
+					`<ul><li><strong>branch:</strong> a&amp;b</li><li><a href="https://ci.example.com/1?a=1&amp;b=2">Pipeline</a></li></ul>` +
+					"<p><em>Tags: ci</em></p>",
+			},
+		},
+		{
+			name:    "Notice without title",
+			message: Message{Body: "Disk full"},
+			msgType: MatrixNotice,
+			expected: MatrixMessage{
+				MsgType:       "m.notice",
+				Body:          "ℹ️\nDisk full",
+				Format:        "org.matrix.custom.html",
+				FormattedBody: "<p><strong>ℹ️</strong></p><p>Disk full</p>",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matrixMessage(tt.message, tt.msgType))
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMatrixNoRoom = errors.New("matrix message has no room")
+)
+
+// MatrixConfig holds the settings of the Matrix channel.
+type MatrixConfig struct {
+	HomeserverURL string // URL of the homeserver, such as https://matrix.example.com.
+	AccessToken   string // Access token of the account sending messages.
+	RoomID        string // Default room for messages without recipients.
+	Notice        bool   // Send m.notice instead of m.text messages.
+	Name          string // Channel name, defaults to Matrix.
+}
+
+// Matrix represents a channel sending messages to Matrix rooms with the client-server
+// API. Recipients are room IDs, such as !abc:example.com, or room aliases, such as
+// #ops:example.com. The account must have joined the rooms.
+type Matrix struct {
+	config  MatrixConfig
+	name    string       // The name of the sender.
+	client  *http.Client // HTTP client for making requests.
+	aliases *lookupCache
+}
+
+// matrixError is the body of a failed client-server API request.
+type matrixError struct {
+	ErrCode      string `json:"errcode"`
+	Error        string `json:"error"`
+	RetryAfterMS int64  `json:"retry_after_ms"`
+}
+
+// NewMatrix creates a new Matrix channel instance.
+func NewMatrix(config MatrixConfig, client *http.Client) *Matrix {
+	config.HomeserverURL = strings.TrimSuffix(config.HomeserverURL, "/")
+	return &Matrix{
+		config:  config,
+		name:    senderName(config.Name, "Matrix"),
+		client:  client,
+		aliases: newLookupCache(time.Hour),
+	}
+}
+
+// Send sends a message to each of its recipients, or to the configured room when it has
+// none. Each event has a transaction ID derived from the notification and the room, so
+// the homeserver ignores a retried send that already succeeded.
+func (m *Matrix) Send(ctx context.Context, message Message) error {
+	rooms := message.Recipients
+	if len(rooms) == 0 {
+		if m.config.RoomID == "" {
+			return Permanent(ErrMatrixNoRoom)
+		}
+		rooms = []string{m.config.RoomID}
+	}
+	msgType := MatrixText
+	if m.config.Notice {
+		msgType = MatrixNotice
+	}
+	content := matrixMessage(message, msgType)
+
+	var errs []error
+	for _, room := range rooms {
+		if err := m.sendTo(ctx, room, content); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", room, err))
+		}
+	}
+	if err := JoinErrors(errs...); err != nil {
+		return err
+	}
+	log.Printf("matrix message sent: %s", message)
+
+	return nil
+}
+
+// GetName returns the name of the Matrix sender.
+func (m *Matrix) GetName() string {
+	return m.name
+}
+
+// sendTo sends an m.room.message event to a room.
+func (m *Matrix) sendTo(ctx context.Context, room string, content MatrixMessage) error {
+	roomID, err := m.resolve(ctx, room)
+	if err != nil {
+		return err
+	}
+	txnID, err := matrixTransactionID(NotificationID(ctx), m.name, roomID)
+	if err != nil {
+		return Retryable(err)
+	}
+	path := "/_matrix/client/v3/rooms/" + url.PathEscape(roomID) + "/send/m.room.message/" + txnID
+	return m.call(ctx, http.MethodPut, path, content, &struct{}{})
+}
+
+// resolve returns the room ID of a room alias. Other rooms are returned as they are.
+func (m *Matrix) resolve(ctx context.Context, room string) (string, error) {
+	if !strings.HasPrefix(room, "#") {
+		return room, nil
+	}
+	if roomID, found := m.aliases.get(room); found {
+		return roomID, nil
+	}
+
+	var result struct {
+		RoomID string `json:"room_id"`
+	}
+	if err := m.call(ctx, http.MethodGet, "/_matrix/client/v3/directory/room/"+url.PathEscape(room), nil, &result); err != nil {
+		return "", err
+	}
+	m.aliases.set(room, result.RoomID)
+	return result.RoomID, nil
+}
+
+// call calls a client-server API endpoint, sending body as JSON and decoding a successful
+// response into result.
+func (m *Matrix) call(ctx context.Context, method, path string, body any, result any) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return Permanent(err)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, m.config.HomeserverURL+path, reqBody)
+	if err != nil {
+		return Permanent(err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+m.config.AccessToken)
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return Retryable(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return Retryable(fmt.Errorf("decoding matrix response: %w", err))
+		}
+		return nil
+	}
+
+	// Proxies in front of the homeserver may answer with other bodies than API errors.
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return Retryable(err)
+	}
+	var apiErr matrixError
+	if err := json.Unmarshal(b, &apiErr); err != nil || apiErr.ErrCode == "" {
+		apiErr = matrixError{ErrCode: resp.Status, Error: string(b)}
+	}
+	return matrixAPIError(resp, apiErr)
+}
+
+// matrixAPIError classifies a failed request. Rate limited requests carry the delay in
+// the body, and in Retry-After with newer homeservers.
+func matrixAPIError(resp *http.Response, apiErr matrixError) error {
+	err := fmt.Errorf("matrix api error: %s: %s", apiErr.ErrCode, apiErr.Error)
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		retryAfter := time.Duration(apiErr.RetryAfterMS) * time.Millisecond
+		if retryAfter == 0 {
+			retryAfter = ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		}
+		return RateLimited(err, retryAfter)
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode >= 500:
+		return Retryable(err)
+	default:
+		return Permanent(err)
+	}
+}
+
+// matrixTransactionID returns the transaction ID of an event a channel sends to a room.
+// Sends of the same notification by the channel share it, others get a random one.
+// Channels sharing an access token and room get one each, so that the homeserver does
+// not drop the event of the second channel as a duplicate.
+func matrixTransactionID(notificationID, channelName, roomID string) (string, error) {
+	if notificationID != "" {
+		sum := sha256.Sum256([]byte(notificationID + "\x00" + strings.ToLower(channelName) + "\x00" + roomID))
+		return hex.EncodeToString(sum[:16]), nil
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,128 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatrix_Send(t *testing.T) {
+	tests := []struct {
+		name             string
+		recipients       []string
+		status           int
+		response         string
+		expectRooms      []string
+		expectErr        string
+		expectPermanent  bool
+		expectRetryAfter time.Duration
+	}{
+		{
+			name:        "Sending to the configured room returns no error",
+			status:      http.StatusOK,
+			response:    `{"event_id":"$1"}`,
+			expectRooms: []string{"!ops:example.com"},
+		},
+		{
+			name:        "Room aliases are resolved",
+			recipients:  []string{"#alerts:example.com", "!dev:example.com"},
+			status:      http.StatusOK,
+			response:    `{"event_id":"$1"}`,
+			expectRooms: []string{"!alerts:example.com", "!dev:example.com"},
+		},
+		{
+			name:             "Sending when rate limited returns retry after",
+			status:           http.StatusTooManyRequests,
+			response:         `{"errcode":"M_LIMIT_EXCEEDED","error":"Too many requests","retry_after_ms":2500}`,
+			expectErr:        "!ops:example.com: matrix api error: M_LIMIT_EXCEEDED: Too many requests",
+			expectRetryAfter: 2500 * time.Millisecond,
+		},
+		{
+			name:            "Sending to a room the account has not joined returns permanent error",
+			status:          http.StatusForbidden,
+			response:        `{"errcode":"M_FORBIDDEN","error":"User not in room"}`,
+			expectErr:       "!ops:example.com: matrix api error: M_FORBIDDEN: User not in room",
+			expectPermanent: true,
+		},
+		{
+			name:      "Sending during an outage returns retryable error",
+			status:    http.StatusBadGateway,
+			response:  `Bad gateway`,
+			expectErr: "!ops:example.com: matrix api error: 502 Bad Gateway: Bad gateway",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rooms []string
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "Bearer access-token", req.Header.Get("Authorization"))
+				if req.Method == http.MethodGet {
+					assert.Equal(t, "/_matrix/client/v3/directory/room/#alerts:example.com", req.URL.Path)
+					_, _ = rw.Write([]byte(`{"room_id":"!alerts:example.com","servers":["example.com"]}`))
+					return
+				}
+				assert.Equal(t, http.MethodPut, req.Method)
+				room, txnID, found := strings.Cut(strings.TrimPrefix(req.URL.Path, "/_matrix/client/v3/rooms/"), "/send/m.room.message/")
+				assert.True(t, found)
+				assert.Equal(t, matrixTxnID(t, "notification-1", "Matrix", room), txnID)
+				var content MatrixMessage
+				assert.NoError(t, json.NewDecoder(req.Body).Decode(&content))
+				assert.Equal(t, "m.notice", content.MsgType)
+				assert.Equal(t, "ℹ️ Disk full", content.Body)
+				rooms = append(rooms, room)
+				rw.WriteHeader(tt.status)
+				_, _ = rw.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			m := NewMatrix(MatrixConfig{HomeserverURL: server.URL, AccessToken: "access-token", RoomID: "!ops:example.com", Notice: true}, server.Client())
+			ctx := WithNotificationID(context.Background(), "notification-1")
+			err := m.Send(ctx, Message{Title: "Disk full", Recipients: tt.recipients})
+			if tt.expectRooms != nil {
+				assert.Equal(t, tt.expectRooms, rooms)
+			}
+			if tt.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expectErr)
+			assert.Equal(t, tt.expectPermanent, IsPermanent(err))
+			retryAfter, _ := RetryAfter(err)
+			assert.Equal(t, tt.expectRetryAfter, retryAfter)
+		})
+	}
+}
+
+func TestMatrix_SendWithoutRoom(t *testing.T) {
+	m := NewMatrix(MatrixConfig{HomeserverURL: "https://matrix.example.com", AccessToken: "access-token"}, http.DefaultClient)
+
+	err := m.Send(context.Background(), Message{Body: "Disk full"})
+	assert.ErrorIs(t, err, ErrMatrixNoRoom)
+	assert.True(t, IsPermanent(err))
+}
+
+func TestMatrixTransactionID(t *testing.T) {
+	// Sends of a notification by a channel to a room share the transaction ID
+	assert.Equal(t, matrixTxnID(t, "notification-1", "Matrix", "!a:example.com"), matrixTxnID(t, "notification-1", "Matrix", "!a:example.com"))
+	assert.NotEqual(t, matrixTxnID(t, "notification-1", "Matrix", "!a:example.com"), matrixTxnID(t, "notification-1", "Matrix", "!b:example.com"))
+	assert.NotEqual(t, matrixTxnID(t, "notification-1", "Matrix", "!a:example.com"), matrixTxnID(t, "notification-2", "Matrix", "!a:example.com"))
+	assert.NotEqual(t, matrixTxnID(t, "notification-1", "matrix-ops", "!a:example.com"), matrixTxnID(t, "notification-1", "matrix-dev", "!a:example.com"))
+
+	// Sends without notification get random IDs
+	assert.NotEqual(t, matrixTxnID(t, "", "Matrix", "!a:example.com"), matrixTxnID(t, "", "Matrix", "!a:example.com"))
+}
+
+// matrixTxnID returns the transaction ID of a send, failing the test on errors.
+func matrixTxnID(t *testing.T, notificationID, channelName, roomID string) string {
+	txnID, err := matrixTransactionID(notificationID, channelName, roomID)
+	require.NoError(t, err)
+	return txnID
+}
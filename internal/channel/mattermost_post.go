@@ -0,0 +1,104 @@
+package channel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mattermost post limits, see https://developers.mattermost.com/integrate/reference/message-attachments/.
+const (
+	mattermostMaxText   = 16000
+	mattermostMaxTitle  = 256
+	mattermostMaxFields = 25
+)
+
+// mattermostEscaper escapes the characters Mattermost's Markdown would format.
+var mattermostEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "~", `\~`, "[", `\[`, "]", `\]`,
+	"#", `\#`, "|", `\|`, "<", `\<`, ">", `\>`,
+)
+
+// MattermostPost is a post created through an incoming webhook or the REST API.
+type MattermostPost struct {
+	ChannelID string               `json:"channel_id,omitempty"` // Only used by the REST API.
+	Channel   string               `json:"channel,omitempty"`    // Channel name overriding the webhook's channel.
+	Username  string               `json:"username,omitempty"`
+	IconURL   string               `json:"icon_url,omitempty"`
+	Props     *MattermostPostProps `json:"props,omitempty"`
+}
+
+// MattermostPostProps holds the attachments of a post.
+type MattermostPostProps struct {
+	Attachments []MattermostAttachment `json:"attachments"`
+}
+
+// MattermostAttachment is a message attachment, shown as a box with a coloured border.
+type MattermostAttachment struct {
+	Fallback  string                      `json:"fallback"`
+	Color     string                      `json:"color,omitempty"`
+	Title     string                      `json:"title,omitempty"`
+	TitleLink string                      `json:"title_link,omitempty"`
+	Text      string                      `json:"text,omitempty"`
+	Fields    []MattermostAttachmentField `json:"fields,omitempty"`
+	Footer    string                      `json:"footer,omitempty"`
+}
+
+// MattermostAttachmentField is a name value pair of an attachment.
+type MattermostAttachmentField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// mattermostAttachment renders a message as an attachment coloured by severity, with the
+// title linking to the first link, the body and remaining links as text, metadata as
+// fields and the severity and tags in the footer.
+func mattermostAttachment(message Message) MattermostAttachment {
+	attachment := MattermostAttachment{
+		Fallback: message.String(),
+		Color:    slackSeverityColor[message.SeverityOrDefault()],
+		Title:    truncate(message.Title, mattermostMaxTitle),
+	}
+
+	// Link the title to the first link and list the other links below the body.
+	var lines []string
+	if body := mattermostBody(message); body != "" {
+		lines = append(lines, body)
+	}
+	links := slackLinks(message)
+	if len(links) > 0 && attachment.Title != "" {
+		attachment.TitleLink = links[0].URL
+		links = links[1:]
+	}
+	for _, link := range links {
+		lines = append(lines, fmt.Sprintf("[%s](%s)", discordEscape(link.title()), link.URL))
+	}
+	attachment.Text = truncate(strings.Join(lines, "\n"), mattermostMaxText)
+
+	// Show metadata as two-column fields.
+	for _, key := range message.MetadataKeys() {
+		if len(attachment.Fields) == mattermostMaxFields {
+			break
+		}
+		attachment.Fields = append(attachment.Fields, MattermostAttachmentField{
+			Title: key,
+			Value: mattermostEscaper.Replace(message.Metadata[key]),
+			Short: true,
+		})
+	}
+
+	// Finish with the severity and tags.
+	attachment.Footer = "Severity: " + string(message.SeverityOrDefault())
+	if len(message.Tags) > 0 {
+		attachment.Footer += " | " + strings.Join(message.Tags, ", ")
+	}
+	return attachment
+}
+
+// mattermostBody returns the body in Markdown, preferring the Markdown body as Mattermost renders it.
+func mattermostBody(message Message) string {
+	if message.Markdown != "" {
+		return message.Markdown
+	}
+	return mattermostEscaper.Replace(message.Body)
+}
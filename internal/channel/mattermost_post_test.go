@@ -0,0 +1,56 @@
+package channel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMattermostAttachment(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  Message
+		expected MattermostAttachment
+	}{
+		{
+			name: "Message with all fields",
+			message: Message{
+				Title:       "Deploy failed",
+				Body:        "Step *build* failed",
+				Severity:    SeverityError,
+				Tags:        []string{"ci", "prod"},
+				Metadata:    map[string]string{"service": "api", "branch": "feature_x"},
+				Links:       []Link{{Title: "Pipeline", URL: "https://ci.example.com/1"}, {Title: "Logs [raw]", URL: "https://logs.example.com"}},
+				Attachments: []Attachment{{Filename: "report.pdf", URL: "https://files.example.com/report.pdf"}},
+			},
+			expected: MattermostAttachment{
+				Fallback:  "Deploy failed",
+				Color:     "#D40E0D",
+				Title:     "Deploy failed",
+				TitleLink: "https://ci.example.com/1",
+				Text:      "Step \\*build\\* failed\n[Logs \\[raw\\]](https://logs.example.com)\n[report.pdf](https://files.example.com/report.pdf)",
+				Fields: []MattermostAttachmentField{
+					{Title: "branch", Value: "feature\\_x", Short: true},
+					{Title: "service", Value: "api", Short: true},
+				},
+				Footer: "Severity: error | ci, prod",
+			},
+		},
+		{
+			name:    "Markdown body is used as is",
+			message: Message{Body: "plain", Markdown: "**bold**", Links: []Link{{URL: "https://example.com"}}},
+			expected: MattermostAttachment{
+				Fallback: "plain",
+				Color:    "#439FE0",
+				Text:     "**bold**\n[https://example.com](https://example.com)",
+				Footer:   "Severity: info",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, mattermostAttachment(tt.message))
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMattermostNoTarget = errors.New("mattermost message has no target channel")
+)
+
+// MattermostConfig holds the settings of the Mattermost channel. Messages are posted
+// through the REST API when BotToken is set and to the incoming webhook otherwise.
+type MattermostConfig struct {
+	WebhookURL string // Incoming webhook URL.
+	ServerURL  string // URL of the Mattermost server, used with BotToken.
+	BotToken   string // Access token of a bot account, enables the REST API.
+	ChannelID  string // Default channel for messages without recipients in REST API mode.
+	Username   string // Optional username override, needs the override setting of the server.
+	IconURL    string // Optional icon image override, needs the override setting of the server.
+	Name       string // Channel name, defaults to Mattermost.
+}
+
+// Mattermost represents a Mattermost channel. Recipients are channel names with
+// webhooks, which post there if the webhook is not locked to its channel, and channel
+// IDs with the REST API.
+type Mattermost struct {
+	config MattermostConfig
+	name   string       // The name of the sender.
+	client *http.Client // HTTP client for making requests.
+}
+
+// NewMattermost creates a new Mattermost channel instance.
+func NewMattermost(config MattermostConfig, client *http.Client) *Mattermost {
+	config.ServerURL = strings.TrimSuffix(config.ServerURL, "/")
+	return &Mattermost{
+		config: config,
+		name:   senderName(config.Name, "Mattermost"),
+		client: client,
+	}
+}
+
+// Send posts a message to each of its recipients, or to the configured channel when it has none.
+// Recipients the message was posted to in an earlier attempt are skipped.
+func (m *Mattermost) Send(ctx context.Context, message Message) error {
+	post := MattermostPost{
+		Username: m.config.Username,
+		IconURL:  m.config.IconURL,
+		Props:    &MattermostPostProps{Attachments: []MattermostAttachment{mattermostAttachment(message)}},
+	}
+
+	targets := message.Recipients
+	if len(targets) == 0 {
+		targets = []string{""}
+		if m.config.BotToken != "" {
+			targets = []string{m.config.ChannelID}
+		}
+	}
+	var errs []error
+	for _, target := range targets {
+		err := sendOnce(ctx, target, func() error {
+			return m.sendTo(ctx, target, post)
+		})
+		if err != nil {
+			if target != "" {
+				err = fmt.Errorf("%s: %w", target, err)
+			}
+			errs = append(errs, err)
+		}
+	}
+	if err := JoinErrors(errs...); err != nil {
+		return err
+	}
+	log.Printf("mattermost message sent: %s", message)
+
+	return nil
+}
+
+// GetName returns the name of the Mattermost sender.
+func (m *Mattermost) GetName() string {
+	return m.name
+}
+
+// sendTo posts to a single target, the channel of the webhook when it is empty.
+func (m *Mattermost) sendTo(ctx context.Context, target string, post MattermostPost) error {
+	if m.config.BotToken == "" {
+		post.Channel = target
+		return m.post(ctx, m.config.WebhookURL, "", post)
+	}
+	if target == "" {
+		return Permanent(ErrMattermostNoTarget)
+	}
+	post.ChannelID = target
+	return m.post(ctx, m.config.ServerURL+"/api/v4/posts", m.config.BotToken, post)
+}
+
+// post sends a post to a webhook, or to the REST API when a token is given.
+func (m *Mattermost) post(ctx context.Context, url, token string, post MattermostPost) error {
+	// Create a JSON request body.
+	reqBody, err := json.Marshal(post)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	// Send the POST request, authenticated with the bot token for the REST API.
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(reqBody))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return Retryable(err)
+	}
+	defer resp.Body.Close()
+
+	// Classify failures so the notifier knows whether to retry. Mattermost sends the
+	// seconds until its rate limit resets in X-Ratelimit-Reset.
+	if err := CheckResponse(resp); err != nil {
+		if _, ok := RetryAfter(err); !ok && resp.StatusCode == http.StatusTooManyRequests {
+			return RateLimited(err, ParseRetryAfter(resp.Header.Get("X-Ratelimit-Reset"), time.Now()))
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMattermost_Send(t *testing.T) {
+	tests := []struct {
+		name             string
+		config           MattermostConfig
+		recipients       []string
+		status           int
+		response         string
+		header           map[string]string
+		expectPath       string
+		expectAuth       string
+		expectPosts      []MattermostPost
+		expectErr        string
+		expectPermanent  bool
+		expectRetryAfter time.Duration
+	}{
+		{
+			name:        "Posting to a webhook returns no error",
+			config:      MattermostConfig{Username: "notifier"},
+			status:      http.StatusOK,
+			response:    `ok`,
+			expectPath:  "/hooks/abc",
+			expectPosts: []MattermostPost{{Username: "notifier"}},
+		},
+		{
+			name:        "Recipients override the channel of the webhook",
+			recipients:  []string{"town-square", "ops"},
+			status:      http.StatusOK,
+			expectPath:  "/hooks/abc",
+			expectPosts: []MattermostPost{{Channel: "town-square"}, {Channel: "ops"}},
+		},
+		{
+			name:        "Posting with a bot token uses the REST API",
+			config:      MattermostConfig{BotToken: "bot-token", ChannelID: "channel-1"},
+			status:      http.StatusCreated,
+			response:    `{"id":"post-1"}`,
+			expectPath:  "/api/v4/posts",
+			expectAuth:  "Bearer bot-token",
+			expectPosts: []MattermostPost{{ChannelID: "channel-1"}},
+		},
+		{
+			name:            "Posting with a bot token without channel returns permanent error",
+			config:          MattermostConfig{BotToken: "bot-token"},
+			expectErr:       ErrMattermostNoTarget.Error(),
+			expectPermanent: true,
+		},
+		{
+			name:            "Posting to a channel the bot is not a member of returns permanent error",
+			config:          MattermostConfig{BotToken: "bot-token"},
+			recipients:      []string{"channel-2"},
+			status:          http.StatusForbidden,
+			response:        `{"id":"api.context.permissions.app_error","status_code":403}`,
+			expectPath:      "/api/v4/posts",
+			expectAuth:      "Bearer bot-token",
+			expectErr:       `channel-2: request failed: {"id":"api.context.permissions.app_error","status_code":403}`,
+			expectPermanent: true,
+		},
+		{
+			name:             "Posting when rate limited returns retry after",
+			status:           http.StatusTooManyRequests,
+			response:         `limit exceeded`,
+			header:           map[string]string{"X-Ratelimit-Reset": "3"},
+			expectPath:       "/hooks/abc",
+			expectErr:        "request failed: limit exceeded",
+			expectRetryAfter: 3 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var posts []MattermostPost
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, tt.expectPath, req.URL.Path)
+				assert.Equal(t, tt.expectAuth, req.Header.Get("Authorization"))
+				var post MattermostPost
+				assert.NoError(t, json.NewDecoder(req.Body).Decode(&post))
+				if assert.NotNil(t, post.Props) && assert.Len(t, post.Props.Attachments, 1) {
+					assert.Equal(t, "Disk full", post.Props.Attachments[0].Title)
+				}
+				post.Props = nil
+				posts = append(posts, post)
+				for key, value := range tt.header {
+					rw.Header().Set(key, value)
+				}
+				rw.WriteHeader(tt.status)
+				_, _ = rw.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			config := tt.config
+			config.WebhookURL = server.URL + "/hooks/abc"
+			config.ServerURL = server.URL + "/"
+			m := NewMattermost(config, server.Client())
+			err := m.Send(context.Background(), Message{Title: "Disk full", Recipients: tt.recipients})
+			if tt.expectPosts != nil {
+				assert.Equal(t, tt.expectPosts, posts)
+			}
+			if tt.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expectErr)
+			assert.Equal(t, tt.expectPermanent, IsPermanent(err))
+			retryAfter, _ := RetryAfter(err)
+			assert.Equal(t, tt.expectRetryAfter, retryAfter)
+		})
+	}
+}
+
+func TestMattermost_SendProgress(t *testing.T) {
+	var posted []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var post MattermostPost
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&post))
+		posted = append(posted, post.ChannelID)
+		if post.ChannelID == "ch2" && len(posted) < 3 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	m := NewMattermost(MattermostConfig{ServerURL: server.URL, BotToken: "token"}, server.Client())
+	progress := NewProgress(nil)
+	ctx := WithProgress(context.Background(), progress)
+	message := Message{Title: "Disk full", Recipients: []string{"ch1", "ch2"}}
+
+	// The first attempt fails for one channel only
+	err := m.Send(ctx, message)
+	assert.Error(t, err)
+	assert.False(t, IsPermanent(err))
+
+	// The retry skips the channel that already got the post
+	assert.NoError(t, m.Send(ctx, message))
+	assert.Equal(t, []string{"ch1", "ch2", "ch2"}, posted)
+	assert.Equal(t, []string{"ch1", "ch2"}, progress.Targets())
+}
@@ -0,0 +1,208 @@
+package channel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Severity describes how urgent a message is.
+type Severity string
+
+// Severities supported by Message.
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityError    Severity = "error"
+	SeverityCritical Severity = "critical"
+)
+
+// Action tells incident channels what to do with the incident of a message.
+type Action string
+
+// Actions supported by Message.
+const (
+	ActionTrigger     Action = "trigger"     // Open an incident, or update the open one.
+	ActionAcknowledge Action = "acknowledge" // Acknowledge the open incident.
+	ActionResolve     Action = "resolve"     // Resolve the open incident.
+)
+
+var (
+	ErrEmptyMessage      = errors.New("message has no content")
+	ErrInvalidSeverity   = errors.New("invalid severity")
+	ErrInvalidAttachment = errors.New("invalid attachment")
+	ErrInvalidAction     = errors.New("invalid action")
+
+	ErrRecipientsNotSupported = errors.New("channel does not support recipients")
+)
+
+// Message is the content of a notification. Each channel renders the fields it supports
+// and falls back to the plain Body for the rest.
+type Message struct {
+	Title       string            `json:"title,omitempty"`       // Title or subject line.
+	Body        string            `json:"body,omitempty"`        // Plain text body.
+	Markdown    string            `json:"markdown,omitempty"`    // Optional Markdown body for channels that render it.
+	HTML        string            `json:"html,omitempty"`        // Optional HTML body for channels that render it.
+	Severity    Severity          `json:"severity,omitempty"`    // How urgent the message is, defaults to info.
+	Tags        []string          `json:"tags,omitempty"`        // Free form labels.
+	Metadata    map[string]string `json:"metadata,omitempty"`    // Additional key value pairs shown with the message.
+	Links       []Link            `json:"links,omitempty"`       // Links related to the message.
+	Attachments []Attachment      `json:"attachments,omitempty"` // Files sent along with the message.
+
+	// Recipients overrides the configured targets of a channel. Each channel interprets
+	// them in its own way, such as email addresses or Slack channel and user IDs.
+	Recipients []string `json:"recipients,omitempty"`
+
+	// CorrelationKey groups related messages, such as the updates of one incident.
+	// Channels that support it show them together, for example as a Slack thread.
+	CorrelationKey string `json:"correlation_key,omitempty"`
+
+	// Action tells incident channels such as PagerDuty whether to open, acknowledge or
+	// resolve the incident identified by the correlation key. Defaults to trigger.
+	Action Action `json:"action,omitempty"`
+
+	// Push holds the options of mobile push notifications.
+	Push *PushOptions `json:"push,omitempty"`
+}
+
+// PushOptions are the settings of a message sent as mobile push notification.
+type PushOptions struct {
+	Badge *int              `json:"badge,omitempty"` // Number shown on the app icon, 0 clears it.
+	Sound string            `json:"sound,omitempty"` // Name of a sound in the app, or "default".
+	Data  map[string]string `json:"data,omitempty"`  // Custom data passed to the app.
+}
+
+// Link is a titled URL related to a message.
+type Link struct {
+	Title string `json:"title,omitempty"`
+	URL   string `json:"url"`
+}
+
+// Attachment is a file sent along with a message, either inline or by URL.
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type,omitempty"`
+	Content     []byte `json:"content,omitempty"` // Base64 encoded in JSON.
+	URL         string `json:"url,omitempty"`     // Used by channels that cannot upload files.
+}
+
+// UnmarshalJSON decodes a message, accepting a plain string as the message body.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var body string
+	if err := json.Unmarshal(data, &body); err == nil {
+		*m = Message{Body: body}
+		return nil
+	}
+
+	// Decode into an alias to avoid recursing into this method.
+	type message Message
+	var decoded message
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*m = Message(decoded)
+	return nil
+}
+
+// Validate checks that the message has content and well-formed fields.
+func (m Message) Validate() error {
+	if m.Title == "" && m.Body == "" && m.Markdown == "" && m.HTML == "" {
+		return ErrEmptyMessage
+	}
+	switch m.Severity {
+	case "", SeverityInfo, SeverityWarning, SeverityError, SeverityCritical:
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidSeverity, m.Severity)
+	}
+	switch m.Action {
+	case "", ActionTrigger:
+	case ActionAcknowledge, ActionResolve:
+		if m.CorrelationKey == "" {
+			return fmt.Errorf("%w: %s needs a correlation key", ErrInvalidAction, m.Action)
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidAction, m.Action)
+	}
+	for _, recipient := range m.Recipients {
+		if strings.TrimSpace(recipient) == "" {
+			return errors.New("recipient is empty")
+		}
+	}
+	if m.Push != nil && m.Push.Badge != nil && *m.Push.Badge < 0 {
+		return errors.New("push badge is negative")
+	}
+	for _, link := range m.Links {
+		if link.URL == "" {
+			return errors.New("link has no url")
+		}
+	}
+	for _, attachment := range m.Attachments {
+		if attachment.Filename == "" {
+			return fmt.Errorf("%w: missing filename", ErrInvalidAttachment)
+		}
+		if len(attachment.Content) == 0 && attachment.URL == "" {
+			return fmt.Errorf("%w: %s has neither content nor url", ErrInvalidAttachment, attachment.Filename)
+		}
+	}
+	return nil
+}
+
+// Text returns the plain text body, falling back to the Markdown body and the title.
+func (m Message) Text() string {
+	switch {
+	case m.Body != "":
+		return m.Body
+	case m.Markdown != "":
+		return m.Markdown
+	default:
+		return m.Title
+	}
+}
+
+// String returns a short description of the message for logging.
+func (m Message) String() string {
+	if m.Title != "" {
+		return m.Title
+	}
+	text := []rune(m.Text())
+	if len(text) > 80 {
+		return string(text[:77]) + "..."
+	}
+	return string(text)
+}
+
+// SeverityOrDefault returns the severity of the message, defaulting to SeverityInfo.
+func (m Message) SeverityOrDefault() Severity {
+	if m.Severity == "" {
+		return SeverityInfo
+	}
+	return m.Severity
+}
+
+// ActionOrDefault returns the action of the message, defaulting to ActionTrigger.
+func (m Message) ActionOrDefault() Action {
+	if m.Action == "" {
+		return ActionTrigger
+	}
+	return m.Action
+}
+
+// MetadataKeys returns the metadata keys in sorted order, so channels render them consistently.
+func (m Message) MetadataKeys() []string {
+	keys := make([]string, 0, len(m.Metadata))
+	for key := range m.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// title returns the link title, falling back to its URL.
+func (l Link) title() string {
+	if strings.TrimSpace(l.Title) == "" {
+		return l.URL
+	}
+	return l.Title
+}
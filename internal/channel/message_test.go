@@ -0,0 +1,110 @@
+package channel
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessage_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expected  Message
+		expectErr bool
+	}{
+		{
+			name:     "Plain string is the body",
+			input:    `"Hello"`,
+			expected: Message{Body: "Hello"},
+		},
+		{
+			name:  "Object sets all fields",
+			input: `{"title":"Deploy","body":"done","severity":"warning","tags":["prod"],"links":[{"url":"https://example.com"}],"attachments":[{"filename":"a.txt","content":"aGk="}]}`,
+			expected: Message{
+				Title:       "Deploy",
+				Body:        "done",
+				Severity:    SeverityWarning,
+				Tags:        []string{"prod"},
+				Links:       []Link{{URL: "https://example.com"}},
+				Attachments: []Attachment{{Filename: "a.txt", Content: []byte("hi")}},
+			},
+		},
+		{
+			name:      "Other types are rejected",
+			input:     `42`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var message Message
+			err := json.Unmarshal([]byte(tt.input), &message)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, message)
+		})
+	}
+}
+
+func TestMessage_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		message     Message
+		expectedErr error
+	}{
+		{
+			name:    "Body only",
+			message: Message{Body: "Hello"},
+		},
+		{
+			name:    "HTML only",
+			message: Message{HTML: "<b>Hello</b>"},
+		},
+		{
+			name:        "Empty message",
+			message:     Message{Severity: SeverityInfo},
+			expectedErr: ErrEmptyMessage,
+		},
+		{
+			name:        "Unknown severity",
+			message:     Message{Body: "Hello", Severity: "panic"},
+			expectedErr: ErrInvalidSeverity,
+		},
+		{
+			name:    "Resolve with correlation key",
+			message: Message{Body: "Disk is fine", Action: ActionResolve, CorrelationKey: "disk"},
+		},
+		{
+			name:        "Resolve without correlation key",
+			message:     Message{Body: "Disk is fine", Action: ActionResolve},
+			expectedErr: ErrInvalidAction,
+		},
+		{
+			name:        "Unknown action",
+			message:     Message{Body: "Hello", Action: "escalate"},
+			expectedErr: ErrInvalidAction,
+		},
+		{
+			name:        "Attachment without content",
+			message:     Message{Body: "Hello", Attachments: []Attachment{{Filename: "a.txt"}}},
+			expectedErr: ErrInvalidAttachment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.message.Validate()
+			if tt.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
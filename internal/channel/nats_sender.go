@@ -0,0 +1,190 @@
+package channel
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSConfig holds the settings of the NATS channel.
+type NATSConfig struct {
+	URL     string // Server URLs, separated by commas.
+	Subject string // Subject of messages without recipients.
+	Source  string // Source of the events, defaults to /notification-service.
+
+	// JetStream publishes to streams, which acknowledge messages once they are stored
+	// and drop duplicates of a retried send. Without it messages are only flushed to
+	// the server and lost if no subscriber is listening.
+	JetStream bool
+	Name      string // Channel name, defaults to NATS.
+}
+
+// NATS represents a channel publishing notifications as CloudEvents to NATS subjects.
+// Recipients are subjects overriding the configured one.
+type NATS struct {
+	config NATSConfig
+	name   string // The name of the sender.
+	dial   func(config NATSConfig) (natsPublisher, error)
+	now    func() time.Time // Clock used for the event time.
+
+	// Connection opened on the first send. The client reconnects on its own.
+	mu        sync.Mutex
+	publisher natsPublisher
+	closed    bool
+}
+
+// natsPublisher publishes messages to NATS.
+type natsPublisher interface {
+	// Publish publishes a message and waits until the server or stream received it.
+	Publish(ctx context.Context, msg *nats.Msg) error
+	Close()
+}
+
+// NewNATS creates a new NATS channel instance. The connection is opened on the first send.
+func NewNATS(config NATSConfig) *NATS {
+	return &NATS{
+		config: config,
+		name:   senderName(config.Name, "NATS"),
+		dial:   dialNATS,
+		now:    time.Now,
+	}
+}
+
+// Send publishes a message to each subject.
+func (n *NATS) Send(ctx context.Context, message Message) error {
+	subjects := message.Recipients
+	if len(subjects) == 0 {
+		subjects = []string{n.config.Subject}
+	}
+	event, body, err := encodeCloudEvent(ctx, n.config.Source, message, n.now())
+	if err != nil {
+		return Permanent(err)
+	}
+	publisher, err := n.connect()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, subject := range subjects {
+		msg := nats.NewMsg(subject)
+		msg.Header.Set("Content-Type", CloudEventContentType)
+		// Streams drop messages with an ID they stored recently.
+		msg.Header.Set(nats.MsgIdHdr, natsMsgID(event.ID, n.name, subject))
+		msg.Data = body
+		if err := publisher.Publish(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", subject, natsError(err)))
+		}
+	}
+	if err := JoinErrors(errs...); err != nil {
+		return err
+	}
+	log.Printf("nats message sent: %s", message)
+
+	return nil
+}
+
+// natsMsgID returns the message ID of an event published to a subject by a channel.
+// Retries of a send share it, while the subjects and channels a stream may capture
+// together get one each, so the stream does not drop them as duplicates.
+func natsMsgID(eventID, channelName, subject string) string {
+	sum := sha256.Sum256([]byte(eventID + "\x00" + strings.ToLower(channelName) + "\x00" + subject))
+	return hex.EncodeToString(sum[:16])
+}
+
+// GetName returns the name of the NATS sender.
+func (n *NATS) GetName() string {
+	return n.name
+}
+
+// Close closes the connection to the server.
+func (n *NATS) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.closed = true
+	if n.publisher != nil {
+		n.publisher.Close()
+	}
+	return nil
+}
+
+// connect returns the publisher, connecting to the server if needed.
+func (n *NATS) connect() (natsPublisher, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		return nil, Retryable(nats.ErrConnectionClosed)
+	}
+	if n.publisher != nil {
+		return n.publisher, nil
+	}
+	publisher, err := n.dial(n.config)
+	if err != nil {
+		return nil, Retryable(err)
+	}
+	n.publisher = publisher
+	return publisher, nil
+}
+
+// natsConn is a natsPublisher using a client connection.
+type natsConn struct {
+	conn *nats.Conn
+	js   nats.JetStreamContext // Nil to publish without JetStream.
+}
+
+// dialNATS connects to the servers, reconnecting for as long as the process runs.
+func dialNATS(config NATSConfig) (natsPublisher, error) {
+	conn, err := nats.Connect(config.URL, nats.Name("notification-service"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+	c := &natsConn{conn: conn}
+	if config.JetStream {
+		if c.js, err = conn.JetStream(); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Publish publishes a message, waiting for the acknowledgement of the stream with
+// JetStream and for the server to process it otherwise.
+func (c *natsConn) Publish(ctx context.Context, msg *nats.Msg) error {
+	if c.js != nil {
+		_, err := c.js.PublishMsg(msg, nats.Context(ctx))
+		return err
+	}
+	if err := c.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+	return c.conn.FlushWithContext(ctx)
+}
+
+// Close closes the connection.
+func (c *natsConn) Close() {
+	c.conn.Close()
+}
+
+// natsError classifies an error of the client or a stream. Invalid and too large
+// messages are permanent, as are errors a stream reports for the request. Others,
+// such as timeouts and streams that are not available, are retried.
+func natsError(err error) error {
+	var apiErr *nats.APIError
+	switch {
+	case errors.Is(err, nats.ErrBadSubject), errors.Is(err, nats.ErrMaxPayload):
+		return Permanent(err)
+	case errors.As(err, &apiErr) && apiErr.Code < 500:
+		return Permanent(err)
+	default:
+		return Retryable(err)
+	}
+}
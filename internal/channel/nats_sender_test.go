@@ -0,0 +1,188 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNATSPublisher records published messages and fails the subjects in errs.
+type fakeNATSPublisher struct {
+	published []*nats.Msg
+	errs      map[string]error
+	closed    bool
+}
+
+func (p *fakeNATSPublisher) Publish(_ context.Context, msg *nats.Msg) error {
+	if err := p.errs[msg.Subject]; err != nil {
+		return err
+	}
+	p.published = append(p.published, msg)
+	return nil
+}
+
+func (p *fakeNATSPublisher) Close() {
+	p.closed = true
+}
+
+func TestNATS_Send(t *testing.T) {
+	tests := []struct {
+		name            string
+		message         Message
+		errs            map[string]error
+		dialErr         error
+		expectSubjects  []string
+		expectErr       string
+		expectPermanent bool
+	}{
+		{
+			name:           "Publishing to the configured subject returns no error",
+			message:        Message{Title: "Disk full", Body: "Disk of db-1 is full"},
+			expectSubjects: []string{"notifications"},
+		},
+		{
+			name:           "Recipients override the subject",
+			message:        Message{Body: "Disk full", Recipients: []string{"ops", "dev"}},
+			expectSubjects: []string{"ops", "dev"},
+		},
+		{
+			name:            "Invalid subjects are permanent",
+			message:         Message{Body: "Disk full", Recipients: []string{"ops", "bad subject"}},
+			errs:            map[string]error{"bad subject": nats.ErrBadSubject},
+			expectSubjects:  []string{"ops"},
+			expectErr:       "bad subject: nats: invalid subject",
+			expectPermanent: true,
+		},
+		{
+			name:      "Timeouts are retried",
+			message:   Message{Body: "Disk full"},
+			errs:      map[string]error{"notifications": nats.ErrTimeout},
+			expectErr: "notifications: nats: timeout",
+		},
+		{
+			name:      "Failed connections are retried",
+			message:   Message{Body: "Disk full"},
+			dialErr:   nats.ErrNoServers,
+			expectErr: "no servers available",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &fakeNATSPublisher{errs: tt.errs}
+			sender := NewNATS(NATSConfig{Subject: "notifications"})
+			sender.dial = func(NATSConfig) (natsPublisher, error) {
+				return publisher, tt.dialErr
+			}
+
+			err := sender.Send(WithNotificationID(context.Background(), "n-1"), tt.message)
+
+			if tt.expectErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectErr)
+				assert.Equal(t, tt.expectPermanent, IsPermanent(err))
+			} else {
+				assert.NoError(t, err)
+			}
+			var subjects []string
+			for _, msg := range publisher.published {
+				subjects = append(subjects, msg.Subject)
+
+				// Messages carry an ID derived from the event ID, so streams drop duplicates
+				assert.Equal(t, CloudEventContentType, msg.Header.Get("Content-Type"))
+				assert.Equal(t, natsMsgID("n-1", "NATS", msg.Subject), msg.Header.Get(nats.MsgIdHdr))
+				var event CloudEvent
+				require.NoError(t, json.Unmarshal(msg.Data, &event))
+				assert.Equal(t, "n-1", event.ID)
+			}
+			assert.Equal(t, tt.expectSubjects, subjects)
+		})
+	}
+}
+
+func TestNATS_SendMsgIDs(t *testing.T) {
+	publish := func(name string) []string {
+		publisher := &fakeNATSPublisher{}
+		sender := NewNATS(NATSConfig{Name: name})
+		sender.dial = func(NATSConfig) (natsPublisher, error) {
+			return publisher, nil
+		}
+		message := Message{Body: "Disk full", Recipients: []string{"alerts.ops", "alerts.dev"}}
+		require.NoError(t, sender.Send(WithNotificationID(context.Background(), "n-1"), message))
+		require.NoError(t, sender.Send(WithNotificationID(context.Background(), "n-1"), message))
+		var ids []string
+		for _, msg := range publisher.published {
+			ids = append(ids, msg.Header.Get(nats.MsgIdHdr))
+		}
+		return ids
+	}
+
+	// Retries reuse the IDs, while subjects a stream may capture together get one each
+	ops := publish("nats-ops")
+	require.Len(t, ops, 4)
+	assert.Equal(t, ops[:2], ops[2:])
+	assert.NotEqual(t, ops[0], ops[1])
+
+	// Other channels get other IDs
+	dev := publish("nats-dev")
+	assert.NotContains(t, dev, ops[0])
+	assert.NotContains(t, dev, ops[1])
+}
+
+func TestNATS_Close(t *testing.T) {
+	publisher := &fakeNATSPublisher{}
+	sender := NewNATS(NATSConfig{Subject: "notifications"})
+	sender.dial = func(NATSConfig) (natsPublisher, error) {
+		return publisher, nil
+	}
+	require.NoError(t, sender.Send(context.Background(), Message{Body: "Disk full"}))
+
+	// Closing closes the connection and fails later sends
+	require.NoError(t, sender.Close())
+	assert.True(t, publisher.closed)
+	err := sender.Send(context.Background(), Message{Body: "Disk full"})
+	assert.ErrorIs(t, err, nats.ErrConnectionClosed)
+	assert.False(t, IsPermanent(err))
+}
+
+func TestNATSError(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		expectPermanent bool
+	}{
+		{
+			name:            "Too large message is permanent",
+			err:             nats.ErrMaxPayload,
+			expectPermanent: true,
+		},
+		{
+			name:            "Stream error for the request is permanent",
+			err:             &nats.APIError{Code: 400, ErrorCode: nats.JSErrCodeBadRequest, Description: "bad request"},
+			expectPermanent: true,
+		},
+		{
+			name: "Unavailable stream is retried",
+			err:  &nats.APIError{Code: 503, ErrorCode: nats.JSErrCodeJetStreamNotEnabled, Description: "jetstream not enabled"},
+		},
+		{
+			name: "Missing stream is retried",
+			err:  nats.ErrNoStreamResponse,
+		},
+		{
+			name: "Network error is retried",
+			err:  errors.New("connection reset by peer"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectPermanent, IsPermanent(natsError(tt.err)))
+		})
+	}
+}
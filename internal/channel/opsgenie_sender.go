@@ -0,0 +1,183 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Base URLs of the Opsgenie API in the US and EU regions.
+const (
+	OpsgenieAPIURL   = "https://api.opsgenie.com"
+	OpsgenieEUAPIURL = "https://api.eu.opsgenie.com"
+)
+
+// Opsgenie limits the length of alert fields.
+const (
+	opsgenieMaxMessage     = 130
+	opsgenieMaxAlias       = 512
+	opsgenieMaxDescription = 15000
+)
+
+// opsgeniePriorities maps the severities of messages to alert priorities.
+var opsgeniePriorities = map[Severity]string{
+	SeverityCritical: "P1",
+	SeverityError:    "P2",
+	SeverityWarning:  "P3",
+	SeverityInfo:     "P5",
+}
+
+// OpsgenieConfig holds the settings of the Opsgenie channel.
+type OpsgenieConfig struct {
+	APIKey  string // Key of an API integration.
+	Source  string // Source reported with alerts, defaults to notification-service.
+	BaseURL string // Base URL of the Alert API, defaults to OpsgenieAPIURL.
+	Name    string // Channel name, defaults to Opsgenie.
+}
+
+// Opsgenie represents a channel creating, acknowledging and closing Opsgenie alerts.
+// Recipients are responders of new alerts, users by email address and teams by name.
+// Messages with the same correlation key belong to the same alert.
+type Opsgenie struct {
+	config OpsgenieConfig
+	name   string       // The name of the sender.
+	client *http.Client // HTTP client for making requests.
+}
+
+// OpsgenieAlert is the request body creating an alert.
+type OpsgenieAlert struct {
+	Message     string              `json:"message"`
+	Alias       string              `json:"alias,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Responders  []OpsgenieResponder `json:"responders,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Details     map[string]string   `json:"details,omitempty"`
+	Source      string              `json:"source,omitempty"`
+	Priority    string              `json:"priority"`
+}
+
+// OpsgenieResponder is a user or team notified of an alert.
+type OpsgenieResponder struct {
+	Type     string `json:"type"`
+	Name     string `json:"name,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// OpsgenieAction is the request body acknowledging or closing an alert.
+type OpsgenieAction struct {
+	Source string `json:"source,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+// NewOpsgenie creates a new Opsgenie channel instance.
+func NewOpsgenie(config OpsgenieConfig, client *http.Client) *Opsgenie {
+	if config.Source == "" {
+		config.Source = incidentSource
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = OpsgenieAPIURL
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+	return &Opsgenie{
+		config: config,
+		name:   senderName(config.Name, "Opsgenie"),
+		client: client,
+	}
+}
+
+// Send creates an alert for a message, or acknowledges or closes the alert with its
+// correlation key.
+func (o *Opsgenie) Send(ctx context.Context, message Message) error {
+	alias := messageIncidentKey(ctx, message, opsgenieMaxAlias)
+	var err error
+	switch action := message.ActionOrDefault(); action {
+	case ActionTrigger:
+		err = o.post(ctx, "/v2/alerts", opsgenieAlert(o.config.Source, alias, message))
+	case ActionAcknowledge, ActionResolve:
+		if alias == "" {
+			return Permanent(fmt.Errorf("%w: %s needs a correlation key", ErrInvalidAction, action))
+		}
+		// Opsgenie closes alerts rather than resolving them.
+		path := "/acknowledge"
+		if action == ActionResolve {
+			path = "/close"
+		}
+		err = o.post(ctx, "/v2/alerts/"+url.PathEscape(alias)+path+"?identifierType=alias", OpsgenieAction{
+			Source: o.config.Source,
+			Note:   message.Text(),
+		})
+	default:
+		return Permanent(fmt.Errorf("%w: %s", ErrInvalidAction, action))
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("opsgenie message sent: %s", message)
+
+	return nil
+}
+
+// GetName returns the name of the Opsgenie sender.
+func (o *Opsgenie) GetName() string {
+	return o.name
+}
+
+// post sends a request to the Alert API. Requests are processed asynchronously, so a
+// successful response only means that the request was accepted.
+func (o *Opsgenie) post(ctx context.Context, path string, body any) error {
+	// Create a JSON request body.
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	// Send the POST request authenticated with the API key.
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.config.BaseURL+path, bytes.NewReader(reqBody))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+o.config.APIKey)
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return Retryable(err)
+	}
+	defer resp.Body.Close()
+
+	// Classify failures so the notifier knows whether to retry.
+	return CheckResponse(resp)
+}
+
+// opsgenieAlert converts a message to an alert with an alias. The title is the alert
+// message, the body and links make up the description.
+func opsgenieAlert(source, alias string, message Message) OpsgenieAlert {
+	title := message.Title
+	if title == "" {
+		title = message.Text()
+	}
+	title = truncate(title, opsgenieMaxMessage)
+	alert := OpsgenieAlert{
+		Message:  title,
+		Alias:    alias,
+		Tags:     message.Tags,
+		Details:  message.Metadata,
+		Source:   source,
+		Priority: opsgeniePriorities[message.SeverityOrDefault()],
+	}
+	if description := incidentDescription(message, title); description != "" {
+		alert.Description = truncate(description, opsgenieMaxDescription)
+	}
+	for _, recipient := range message.Recipients {
+		if strings.Contains(recipient, "@") {
+			alert.Responders = append(alert.Responders, OpsgenieResponder{Type: "user", Username: recipient})
+		} else {
+			alert.Responders = append(alert.Responders, OpsgenieResponder{Type: "team", Name: recipient})
+		}
+	}
+	return alert
+}
@@ -0,0 +1,149 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpsgenie_Send(t *testing.T) {
+	tests := []struct {
+		name            string
+		notificationID  string
+		message         Message
+		status          int
+		response        string
+		expectPath      string
+		expectBody      any
+		expectErr       string
+		expectPermanent bool
+	}{
+		{
+			name: "Creating an alert returns no error",
+			message: Message{
+				Title:          "Disk full",
+				Body:           "Disk of db-1 is full",
+				Severity:       SeverityError,
+				Tags:           []string{"disk"},
+				Metadata:       map[string]string{"host": "db-1"},
+				Links:          []Link{{Title: "Dashboard", URL: "https://grafana.example.com"}},
+				Recipients:     []string{"ops", "alice@example.com"},
+				CorrelationKey: "disk-db-1",
+			},
+			status:     http.StatusAccepted,
+			response:   `{"result":"Request will be processed","requestId":"1"}`,
+			expectPath: "/v2/alerts",
+			expectBody: &OpsgenieAlert{
+				Message:     "Disk full",
+				Alias:       "disk-db-1",
+				Description: "Disk of db-1 is full\nDashboard: https://grafana.example.com",
+				Responders:  []OpsgenieResponder{{Type: "team", Name: "ops"}, {Type: "user", Username: "alice@example.com"}},
+				Tags:        []string{"disk"},
+				Details:     map[string]string{"host": "db-1"},
+				Source:      "notification-service",
+				Priority:    "P2",
+			},
+		},
+		{
+			name:           "Creating an alert without a correlation key uses the notification ID",
+			notificationID: "n-1",
+			message:        Message{Body: "Disk full"},
+			status:         http.StatusAccepted,
+			expectPath:     "/v2/alerts",
+			expectBody: &OpsgenieAlert{
+				Message:  "Disk full",
+				Alias:    "n-1",
+				Source:   "notification-service",
+				Priority: "P5",
+			},
+		},
+		{
+			name:       "Acknowledging an alert uses its alias",
+			message:    Message{Body: "Looking into it", Action: ActionAcknowledge, CorrelationKey: "disk/db-1"},
+			status:     http.StatusAccepted,
+			expectPath: "/v2/alerts/disk%2Fdb-1/acknowledge?identifierType=alias",
+			expectBody: &OpsgenieAction{Source: "notification-service", Note: "Looking into it"},
+		},
+		{
+			name:       "Resolving an alert closes it",
+			message:    Message{Body: "Disk is fine again", Action: ActionResolve, CorrelationKey: "disk-db-1"},
+			status:     http.StatusAccepted,
+			expectPath: "/v2/alerts/disk-db-1/close?identifierType=alias",
+			expectBody: &OpsgenieAction{Source: "notification-service", Note: "Disk is fine again"},
+		},
+		{
+			name:            "Resolving without a correlation key returns permanent error",
+			message:         Message{Body: "Disk is fine again", Action: ActionResolve},
+			expectErr:       "invalid action: resolve needs a correlation key",
+			expectPermanent: true,
+		},
+		{
+			name:            "Sending with an invalid API key returns permanent error",
+			message:         Message{Body: "Disk full"},
+			status:          http.StatusUnauthorized,
+			response:        `{"message":"Could not authenticate"}`,
+			expectPath:      "/v2/alerts",
+			expectErr:       `request failed: {"message":"Could not authenticate"}`,
+			expectPermanent: true,
+		},
+		{
+			name:       "Sending during an outage returns retryable error",
+			message:    Message{Body: "Disk full"},
+			status:     http.StatusServiceUnavailable,
+			expectPath: "/v2/alerts",
+			expectErr:  "request failed: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "GenieKey api-key", req.Header.Get("Authorization"))
+				path = req.URL.EscapedPath() + "?" + req.URL.RawQuery
+				if tt.expectBody != nil {
+					body := json.RawMessage{}
+					assert.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+					expected, err := json.Marshal(tt.expectBody)
+					assert.NoError(t, err)
+					assert.JSONEq(t, string(expected), string(body))
+				}
+				rw.WriteHeader(tt.status)
+				_, _ = rw.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			o := NewOpsgenie(OpsgenieConfig{APIKey: "api-key", BaseURL: server.URL + "/"}, server.Client())
+			err := o.Send(WithNotificationID(context.Background(), tt.notificationID), tt.message)
+			if tt.expectPath != "" {
+				assert.Equal(t, tt.expectPath, strings.TrimSuffix(path, "?"))
+			}
+			if tt.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expectErr)
+			assert.Equal(t, tt.expectPermanent, IsPermanent(err))
+		})
+	}
+}
+
+func TestOpsgenieAlert(t *testing.T) {
+	// Long texts are shortened to the alert message, the full text is the description
+	text := strings.Repeat("a", 200)
+	alert := opsgenieAlert("notification-service", "", Message{Body: text, Severity: SeverityCritical})
+	assert.Len(t, []rune(alert.Message), opsgenieMaxMessage)
+	assert.Equal(t, text, alert.Description)
+	assert.Equal(t, "P1", alert.Priority)
+
+	// Short texts are not repeated
+	alert = opsgenieAlert("notification-service", "", Message{Body: "Disk full"})
+	assert.Equal(t, "Disk full", alert.Message)
+	assert.Empty(t, alert.Description)
+	assert.Equal(t, "P5", alert.Priority)
+}
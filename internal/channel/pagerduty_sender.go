@@ -0,0 +1,183 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// PagerDutyAPIURL is the base URL of the PagerDuty Events API v2.
+const PagerDutyAPIURL = "https://events.pagerduty.com"
+
+// PagerDuty limits the length of summaries and deduplication keys.
+const (
+	pagerDutyMaxSummary  = 1024
+	pagerDutyMaxDedupKey = 255
+)
+
+var (
+	ErrNoRoutingKey = errors.New("pagerduty needs a routing key")
+)
+
+// PagerDutyConfig holds the settings of the PagerDuty channel.
+type PagerDutyConfig struct {
+	RoutingKey string // Integration key of the PagerDuty service, used without recipients.
+	Source     string // Affected system reported with incidents, defaults to notification-service.
+	BaseURL    string // Base URL of the Events API, defaults to PagerDutyAPIURL.
+	Name       string // Channel name, defaults to PagerDuty.
+}
+
+// PagerDuty represents a channel opening, acknowledging and resolving PagerDuty incidents
+// with the Events API v2. Recipients are routing keys overriding the configured one.
+// Messages with the same correlation key belong to the same incident.
+type PagerDuty struct {
+	config PagerDutyConfig
+	name   string       // The name of the sender.
+	client *http.Client // HTTP client for making requests.
+}
+
+// PagerDutyEvent is an event of the PagerDuty Events API v2.
+type PagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key,omitempty"`
+	Payload     *PagerDutyPayload `json:"payload,omitempty"` // Only sent with trigger events.
+	Links       []PagerDutyLink   `json:"links,omitempty"`
+}
+
+// PagerDutyPayload describes the incident of a trigger event.
+type PagerDutyPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Class         string         `json:"class,omitempty"`
+	CustomDetails map[string]any `json:"custom_details,omitempty"`
+}
+
+// PagerDutyLink is a link shown with an incident.
+type PagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text,omitempty"`
+}
+
+// NewPagerDuty creates a new PagerDuty channel instance.
+func NewPagerDuty(config PagerDutyConfig, client *http.Client) *PagerDuty {
+	if config.Source == "" {
+		config.Source = incidentSource
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = PagerDutyAPIURL
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+	return &PagerDuty{
+		config: config,
+		name:   senderName(config.Name, "PagerDuty"),
+		client: client,
+	}
+}
+
+// Send sends the action of a message as an event to each routing key.
+func (p *PagerDuty) Send(ctx context.Context, message Message) error {
+	routingKeys := message.Recipients
+	if len(routingKeys) == 0 {
+		if p.config.RoutingKey == "" {
+			return Permanent(ErrNoRoutingKey)
+		}
+		routingKeys = []string{p.config.RoutingKey}
+	}
+
+	dedupKey := messageIncidentKey(ctx, message, pagerDutyMaxDedupKey)
+	var errs []error
+	for _, routingKey := range routingKeys {
+		if err := p.sendEvent(ctx, pagerDutyEvent(routingKey, dedupKey, p.config.Source, message)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", shortToken(routingKey), err))
+		}
+	}
+	if err := JoinErrors(errs...); err != nil {
+		return err
+	}
+	log.Printf("pagerduty message sent: %s", message)
+
+	return nil
+}
+
+// GetName returns the name of the PagerDuty sender.
+func (p *PagerDuty) GetName() string {
+	return p.name
+}
+
+// sendEvent sends an event to the Events API.
+func (p *PagerDuty) sendEvent(ctx context.Context, event PagerDutyEvent) error {
+	// Create a JSON request body.
+	reqBody, err := json.Marshal(event)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	// Send the POST request to the enqueue endpoint.
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.BaseURL+"/v2/enqueue", bytes.NewReader(reqBody))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Retryable(err)
+	}
+	defer resp.Body.Close()
+
+	// Classify failures so the notifier knows whether to retry.
+	return CheckResponse(resp)
+}
+
+// pagerDutyEvent converts a message to an event. Acknowledge and resolve events only
+// name the incident, the other fields are only used when triggering one.
+func pagerDutyEvent(routingKey, dedupKey, source string, message Message) PagerDutyEvent {
+	event := PagerDutyEvent{
+		RoutingKey:  routingKey,
+		EventAction: string(message.ActionOrDefault()),
+		DedupKey:    dedupKey,
+	}
+	if message.ActionOrDefault() != ActionTrigger {
+		return event
+	}
+
+	summary := message.Title
+	if summary == "" {
+		summary = message.Text()
+	}
+	event.Payload = &PagerDutyPayload{
+		Summary: truncate(summary, pagerDutyMaxSummary),
+		Source:  source,
+		// The severities of messages match those of PagerDuty.
+		Severity: string(message.SeverityOrDefault()),
+	}
+	if len(message.Tags) > 0 {
+		event.Payload.Class = message.Tags[0]
+	}
+
+	// Pass the body, tags and metadata on as details of the incident.
+	details := map[string]any{}
+	if text := message.Text(); text != summary {
+		details["body"] = text
+	}
+	if len(message.Tags) > 0 {
+		details["tags"] = message.Tags
+	}
+	for key, value := range message.Metadata {
+		details[key] = value
+	}
+	if len(details) > 0 {
+		event.Payload.CustomDetails = details
+	}
+
+	for _, link := range message.Links {
+		event.Links = append(event.Links, PagerDutyLink{Href: link.URL, Text: link.Title})
+	}
+	return event
+}
@@ -0,0 +1,142 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPagerDuty_Send(t *testing.T) {
+	tests := []struct {
+		name            string
+		notificationID  string
+		message         Message
+		status          int
+		response        string
+		expectEvents    []PagerDutyEvent
+		expectErr       string
+		expectPermanent bool
+	}{
+		{
+			name: "Triggering an incident returns no error",
+			message: Message{
+				Title:          "Disk full",
+				Body:           "Disk of db-1 is full",
+				Severity:       SeverityCritical,
+				Tags:           []string{"disk"},
+				Metadata:       map[string]string{"host": "db-1"},
+				Links:          []Link{{Title: "Dashboard", URL: "https://grafana.example.com"}},
+				CorrelationKey: "disk-db-1",
+			},
+			status: http.StatusAccepted,
+			expectEvents: []PagerDutyEvent{{
+				RoutingKey:  "routing-key",
+				EventAction: "trigger",
+				DedupKey:    "disk-db-1",
+				Payload: &PagerDutyPayload{
+					Summary:       "Disk full",
+					Source:        "db-1.example.com",
+					Severity:      "critical",
+					Class:         "disk",
+					CustomDetails: map[string]any{"body": "Disk of db-1 is full", "tags": []any{"disk"}, "host": "db-1"},
+				},
+				Links: []PagerDutyLink{{Href: "https://grafana.example.com", Text: "Dashboard"}},
+			}},
+		},
+		{
+			name:    "Resolving an incident only sends its dedup key",
+			message: Message{Body: "Disk is fine again", Action: ActionResolve, CorrelationKey: "disk-db-1"},
+			status:  http.StatusAccepted,
+			expectEvents: []PagerDutyEvent{{
+				RoutingKey:  "routing-key",
+				EventAction: "resolve",
+				DedupKey:    "disk-db-1",
+			}},
+		},
+		{
+			name:    "Recipients override the routing key",
+			message: Message{Body: "Disk full", Recipients: []string{"team-a", "team-b"}},
+			status:  http.StatusAccepted,
+			expectEvents: []PagerDutyEvent{
+				{RoutingKey: "team-a", EventAction: "trigger", Payload: &PagerDutyPayload{Summary: "Disk full", Source: "db-1.example.com", Severity: "info"}},
+				{RoutingKey: "team-b", EventAction: "trigger", Payload: &PagerDutyPayload{Summary: "Disk full", Source: "db-1.example.com", Severity: "info"}},
+			},
+		},
+		{
+			name:           "Triggering without a correlation key uses the notification ID",
+			notificationID: "n-1",
+			message:        Message{Body: "Disk full"},
+			status:         http.StatusAccepted,
+			expectEvents: []PagerDutyEvent{
+				{RoutingKey: "routing-key", EventAction: "trigger", DedupKey: "n-1", Payload: &PagerDutyPayload{Summary: "Disk full", Source: "db-1.example.com", Severity: "info"}},
+			},
+		},
+		{
+			name:            "Sending an invalid event returns permanent error",
+			message:         Message{Body: "Disk full"},
+			status:          http.StatusBadRequest,
+			response:        `{"status":"invalid event","message":"Event object is invalid"}`,
+			expectErr:       `routing-key: request failed: {"status":"invalid event","message":"Event object is invalid"}`,
+			expectPermanent: true,
+		},
+		{
+			name:      "Sending during an outage returns retryable error",
+			message:   Message{Body: "Disk full"},
+			status:    http.StatusInternalServerError,
+			expectErr: "routing-key: request failed: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []PagerDutyEvent
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "/v2/enqueue", req.URL.Path)
+				assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+				var event PagerDutyEvent
+				assert.NoError(t, json.NewDecoder(req.Body).Decode(&event))
+				events = append(events, event)
+				rw.WriteHeader(tt.status)
+				_, _ = rw.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			p := NewPagerDuty(PagerDutyConfig{RoutingKey: "routing-key", Source: "db-1.example.com", BaseURL: server.URL}, server.Client())
+			err := p.Send(WithNotificationID(context.Background(), tt.notificationID), tt.message)
+			if tt.expectEvents != nil {
+				assert.Equal(t, tt.expectEvents, events)
+			}
+			if tt.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expectErr)
+			assert.Equal(t, tt.expectPermanent, IsPermanent(err))
+		})
+	}
+}
+
+func TestPagerDuty_SendWithoutRoutingKey(t *testing.T) {
+	p := NewPagerDuty(PagerDutyConfig{}, http.DefaultClient)
+
+	err := p.Send(context.Background(), Message{Body: "Disk full"})
+	assert.ErrorIs(t, err, ErrNoRoutingKey)
+	assert.True(t, IsPermanent(err))
+}
+
+func TestIncidentKey(t *testing.T) {
+	assert.Equal(t, "disk-db-1", incidentKey("disk-db-1", pagerDutyMaxDedupKey))
+
+	// Long keys are hashed to the same short key
+	long := strings.Repeat("disk-", 100)
+	key := incidentKey(long, pagerDutyMaxDedupKey)
+	require.Len(t, key, 64)
+	assert.Equal(t, key, incidentKey(long, pagerDutyMaxDedupKey))
+	assert.NotEqual(t, key, incidentKey(long+"x", pagerDutyMaxDedupKey))
+}
@@ -0,0 +1,89 @@
+package channel
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Progress records the targets of a notification that were delivered, such as the
+// recipients of a message sent to several of them. Senders skip these targets when the
+// notification is retried, so a failure of one target does not resend to the others.
+// A nil Progress records nothing.
+type Progress struct {
+	mu   sync.Mutex
+	done map[string]bool
+}
+
+// NewProgress creates a Progress of the targets delivered in earlier attempts.
+func NewProgress(done []string) *Progress {
+	p := &Progress{done: make(map[string]bool, len(done))}
+	for _, target := range done {
+		p.done[target] = true
+	}
+	return p
+}
+
+// Done reports whether a target was delivered.
+func (p *Progress) Done(target string) bool {
+	if p == nil {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.done[target]
+}
+
+// Mark records a target as delivered.
+func (p *Progress) Mark(target string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done[target] = true
+}
+
+// Targets returns the delivered targets in sorted order.
+func (p *Progress) Targets() []string {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	targets := make([]string, 0, len(p.done))
+	for target := range p.done {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+// progressKey is the context key of the progress.
+type progressKey struct{}
+
+// WithProgress returns a context carrying the progress of the notification being sent.
+func WithProgress(ctx context.Context, progress *Progress) context.Context {
+	return context.WithValue(ctx, progressKey{}, progress)
+}
+
+// ProgressFrom returns the progress of the notification being sent, or nil if unknown.
+// Senders delivering to several targets use it to skip the ones delivered before.
+func ProgressFrom(ctx context.Context) *Progress {
+	progress, _ := ctx.Value(progressKey{}).(*Progress)
+	return progress
+}
+
+// sendOnce calls send unless the target was delivered in an earlier attempt, and
+// records the target as delivered once send succeeds.
+func sendOnce(ctx context.Context, target string, send func() error) error {
+	progress := ProgressFrom(ctx)
+	if progress.Done(target) {
+		return nil
+	}
+	if err := send(); err != nil {
+		return err
+	}
+	progress.Mark(target)
+	return nil
+}
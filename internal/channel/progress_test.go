@@ -0,0 +1,35 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendOnce(t *testing.T) {
+	progress := NewProgress([]string{"a"})
+	ctx := WithProgress(context.Background(), progress)
+	var sent []string
+	send := func(target string, err error) error {
+		return sendOnce(ctx, target, func() error {
+			sent = append(sent, target)
+			return err
+		})
+	}
+
+	// Delivered targets are skipped, failed ones are not recorded
+	assert.NoError(t, send("a", nil))
+	assert.NoError(t, send("b", nil))
+	assert.Error(t, send("c", errors.New("failed")))
+	assert.Equal(t, []string{"b", "c"}, sent)
+	assert.Equal(t, []string{"a", "b"}, progress.Targets())
+
+	// Without a progress every target is sent
+	assert.NoError(t, sendOnce(context.Background(), "a", func() error {
+		sent = append(sent, "a")
+		return nil
+	}))
+	assert.Equal(t, []string{"b", "c", "a"}, sent)
+}
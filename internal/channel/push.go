@@ -0,0 +1,229 @@
+package channel
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNoDeviceTokens   = errors.New("message has no device tokens")
+	ErrTokenInvalidated = errors.New("device token was invalidated")
+)
+
+// InvalidToken is a device token a push provider reported as no longer valid, for
+// example because the app was uninstalled.
+type InvalidToken struct {
+	Channel       string    `json:"channel"`
+	Token         string    `json:"token"`
+	Reason        string    `json:"reason"`
+	InvalidatedAt time.Time `json:"invalidated_at"`
+}
+
+// TokenStore keeps the device tokens push channels must no longer send to.
+type TokenStore interface {
+	// Invalidate records a device token as invalid.
+	Invalidate(token InvalidToken) error
+	// IsInvalid reports whether a device token of a channel was invalidated.
+	IsInvalid(channelName, token string) (bool, error)
+	// List returns all invalidated tokens ordered by channel and token.
+	List() ([]InvalidToken, error)
+	// Delete forgets an invalidated token, so it is sent to again.
+	Delete(channelName, token string) error
+}
+
+// MemoryTokenStore is an in-memory TokenStore. Its contents are lost when the process exits.
+type MemoryTokenStore struct {
+	mu     sync.RWMutex
+	tokens map[string]InvalidToken
+}
+
+// NewMemoryTokenStore creates a new MemoryTokenStore.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{
+		tokens: make(map[string]InvalidToken),
+	}
+}
+
+// Invalidate records a device token as invalid.
+func (s *MemoryTokenStore) Invalidate(token InvalidToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[TokenKey(token.Channel, token.Token)] = token
+	return nil
+}
+
+// IsInvalid reports whether a device token of a channel was invalidated.
+func (s *MemoryTokenStore) IsInvalid(channelName, token string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, found := s.tokens[TokenKey(channelName, token)]
+	return found, nil
+}
+
+// List returns all invalidated tokens ordered by channel and token.
+func (s *MemoryTokenStore) List() ([]InvalidToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.tokens))
+	for key := range s.tokens {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	tokens := make([]InvalidToken, len(keys))
+	for i, key := range keys {
+		tokens[i] = s.tokens[key]
+	}
+	return tokens, nil
+}
+
+// Delete forgets an invalidated token.
+func (s *MemoryTokenStore) Delete(channelName, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, TokenKey(channelName, token))
+	return nil
+}
+
+// TokenKey returns the key identifying a device token of a channel in a TokenStore.
+func TokenKey(channelName, token string) string {
+	return strings.ToLower(channelName) + "\x00" + token
+}
+
+// pushTokens returns the device tokens of a message that were not invalidated.
+func pushTokens(store TokenStore, channelName string, message Message) ([]string, error) {
+	if len(message.Recipients) == 0 {
+		return nil, Permanent(ErrNoDeviceTokens)
+	}
+	var tokens []string
+	for _, token := range message.Recipients {
+		token = strings.TrimSpace(token)
+		invalid, err := store.IsInvalid(channelName, token)
+		if err != nil {
+			return nil, Retryable(err)
+		}
+		if !invalid {
+			tokens = append(tokens, token)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, Permanent(fmt.Errorf("%w: all device tokens were invalidated", ErrTokenInvalidated))
+	}
+	return tokens, nil
+}
+
+// sendToTokens sends a message to each device token with send, joining the errors.
+// Retries skip the tokens that already got the message.
+func sendToTokens(ctx context.Context, tokens []string, send func(ctx context.Context, token string) error) error {
+	var errs []error
+	for _, token := range tokens {
+		err := sendOnce(ctx, token, func() error {
+			return send(ctx, token)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", shortToken(token), err))
+		}
+	}
+	return JoinErrors(errs...)
+}
+
+// invalidateToken records a device token reported as invalid by the provider and returns
+// the permanent error to report for it.
+func invalidateToken(store TokenStore, channelName, token, reason string) error {
+	err := store.Invalidate(InvalidToken{Channel: channelName, Token: token, Reason: reason, InvalidatedAt: time.Now()})
+	if err != nil {
+		return Retryable(fmt.Errorf("invalidating device token: %w", err))
+	}
+	return Permanent(fmt.Errorf("%w: %s", ErrTokenInvalidated, reason))
+}
+
+// shortToken shortens a device token for logs and errors.
+func shortToken(token string) string {
+	if len(token) <= 12 {
+		return token
+	}
+	return token[:8] + "…"
+}
+
+// signJWT returns a JSON Web Token with the given header and claims, signed with
+// RS256 for RSA keys and ES256 for ECDSA P-256 keys.
+func signJWT(key crypto.Signer, header, claims map[string]any) (string, error) {
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		header["alg"] = "RS256"
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return "", errors.New("ECDSA keys must use the P-256 curve")
+		}
+		header["alg"] = "ES256"
+	default:
+		return "", fmt.Errorf("unsupported key type %T", key)
+	}
+	header["typ"] = "JWT"
+
+	encodedHeader, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	encodedClaims, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(encodedHeader) + "." + base64.RawURLEncoding.EncodeToString(encodedClaims)
+	digest := sha256.Sum256([]byte(unsigned))
+
+	var signature []byte
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:]); err != nil {
+			return "", err
+		}
+	case *ecdsa.PrivateKey:
+		// JWTs use the fixed size concatenation of r and s rather than ASN.1.
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			return "", err
+		}
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey parses a PEM encoded PKCS #8 private key, as found in Google service
+// account files and APNs .p8 files.
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		// Older keys may still be PKCS #1 encoded RSA keys.
+		if rsaKey, rsaErr := x509.ParsePKCS1PrivateKey(block.Bytes); rsaErr == nil {
+			return rsaKey, nil
+		}
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+	return signer, nil
+}
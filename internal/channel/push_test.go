@@ -0,0 +1,224 @@
+package channel
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryTokenStore(t *testing.T) {
+	store := NewMemoryTokenStore()
+	assert.NoError(t, store.Invalidate(InvalidToken{Channel: "FCM", Token: "b", Reason: "UNREGISTERED"}))
+	assert.NoError(t, store.Invalidate(InvalidToken{Channel: "APNs", Token: "a", Reason: "Unregistered"}))
+
+	// Channel names are case insensitive
+	invalid, err := store.IsInvalid("fcm", "b")
+	assert.NoError(t, err)
+	assert.True(t, invalid)
+	invalid, err = store.IsInvalid("FCM", "a")
+	assert.NoError(t, err)
+	assert.False(t, invalid)
+
+	tokens, err := store.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []InvalidToken{
+		{Channel: "APNs", Token: "a", Reason: "Unregistered"},
+		{Channel: "FCM", Token: "b", Reason: "UNREGISTERED"},
+	}, tokens)
+
+	assert.NoError(t, store.Delete("FCM", "b"))
+	invalid, err = store.IsInvalid("FCM", "b")
+	assert.NoError(t, err)
+	assert.False(t, invalid)
+}
+
+func TestPushTokens(t *testing.T) {
+	store := NewMemoryTokenStore()
+	assert.NoError(t, store.Invalidate(InvalidToken{Channel: "FCM", Token: "old"}))
+
+	tests := []struct {
+		name         string
+		recipients   []string
+		expectTokens []string
+		expectErr    error
+	}{
+		{
+			name:         "Invalidated tokens are skipped",
+			recipients:   []string{" new ", "old"},
+			expectTokens: []string{"new"},
+		},
+		{
+			name:       "Message without tokens returns error",
+			recipients: nil,
+			expectErr:  ErrNoDeviceTokens,
+		},
+		{
+			name:       "Message with only invalidated tokens returns error",
+			recipients: []string{"old"},
+			expectErr:  ErrTokenInvalidated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := pushTokens(store, "FCM", Message{Body: "Hello", Recipients: tt.recipients})
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+				assert.True(t, IsPermanent(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectTokens, tokens)
+		})
+	}
+}
+
+func TestSendToTokens(t *testing.T) {
+	err := sendToTokens(context.Background(), []string{"ok", "0123456789abcdef"}, func(ctx context.Context, token string) error {
+		if token == "ok" {
+			return nil
+		}
+		return Retryable(errors.New("unavailable"))
+	})
+
+	// Failed tokens are shortened in the error
+	assert.EqualError(t, err, "01234567…: unavailable")
+	assert.False(t, IsPermanent(err))
+}
+
+func TestSignJWT(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		key       crypto.Signer
+		expectAlg string
+		expectErr string
+	}{
+		{
+			name:      "RSA keys sign with RS256",
+			key:       rsaKey,
+			expectAlg: "RS256",
+		},
+		{
+			name:      "ECDSA keys sign with ES256",
+			key:       ecKey,
+			expectAlg: "ES256",
+		},
+		{
+			name:      "ECDSA keys of other curves return error",
+			key:       p384Key,
+			expectErr: "ECDSA keys must use the P-256 curve",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jwt, err := signJWT(tt.key, map[string]any{"kid": "key"}, map[string]any{"iss": "team"})
+			if tt.expectErr != "" {
+				assert.EqualError(t, err, tt.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+
+			header, claims := decodeJWT(t, jwt)
+			assert.Equal(t, tt.expectAlg, header["alg"])
+			assert.Equal(t, "key", header["kid"])
+			assert.Equal(t, "team", claims["iss"])
+			assert.True(t, verifyJWT(t, tt.key.Public(), jwt))
+		})
+	}
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		data      []byte
+		expectErr bool
+	}{
+		{
+			name: "PKCS #8 keys are parsed",
+			data: pemKey(t, rsaKey),
+		},
+		{
+			name: "PKCS #1 keys are parsed",
+			data: pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}),
+		},
+		{
+			name:      "Keys that are not PEM encoded return error",
+			data:      []byte("not a key"),
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := parsePrivateKey(tt.data)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, rsaKey.Equal(key))
+		})
+	}
+}
+
+// pemKey encodes a private key as PKCS #8 PEM.
+func pemKey(t *testing.T, key crypto.Signer) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+// decodeJWT returns the header and claims of a JWT.
+func decodeJWT(t *testing.T, jwt string) (map[string]any, map[string]any) {
+	parts := strings.Split(jwt, ".")
+	require.Len(t, parts, 3)
+	var header, claims map[string]any
+	for i, v := range []*map[string]any{&header, &claims} {
+		data, err := base64.RawURLEncoding.DecodeString(parts[i])
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, v))
+	}
+	return header, claims
+}
+
+// verifyJWT checks the signature of a JWT signed with RS256 or ES256.
+func verifyJWT(t *testing.T, key crypto.PublicKey, jwt string) bool {
+	i := strings.LastIndex(jwt, ".")
+	signature, err := base64.RawURLEncoding.DecodeString(jwt[i+1:])
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(jwt[:i]))
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	case *ecdsa.PublicKey:
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		return len(signature) == 64 && ecdsa.Verify(key, digest[:], r, s)
+	}
+	return false
+}
@@ -0,0 +1,350 @@
+package channel
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+var (
+	ErrUnknownChannelType = errors.New("unknown channel type")
+	ErrNoChannelName      = errors.New("channel requires a name")
+	ErrNoInbox            = errors.New("channel requires an inbox")
+)
+
+// Dependencies are the services shared by the senders a Registry builds.
+type Dependencies struct {
+	Client        *http.Client      // Client of channels calling HTTP APIs.
+	Tokens        TokenStore        // Device tokens of the FCM and APNs channels.
+	Subscriptions SubscriptionStore // Subscriptions of the WebPush channel.
+	Inbox         *Inbox            // Inboxes of the InApp channel.
+}
+
+// Factory builds the sender of a named channel from its settings.
+type Factory func(name string, settings map[string]any, deps Dependencies) (Sender, error)
+
+// Registry builds senders of named channels by their type, so several channels of
+// one type, such as a Slack channel per team, can be configured side by side.
+type Registry struct {
+	factories map[string]Factory
+}
+
+// NewRegistry creates a registry of the built-in channel types. Settings are decoded
+// into the config of the type, with keys in snake case such as webhook_url.
+func NewRegistry() *Registry {
+	r := &Registry{factories: make(map[string]Factory)}
+	r.Register("slack", newSlackFromSettings)
+	r.Register("email", newEmailFromSettings)
+	r.Register("teams", newTeamsFromSettings)
+	r.Register("discord", newDiscordFromSettings)
+	r.Register("webhook", newWebhookFromSettings)
+	r.Register("sms", newSMSFromSettings)
+	r.Register("fcm", newFCMFromSettings)
+	r.Register("apns", newAPNsFromSettings)
+	r.Register("webpush", newWebPushFromSettings)
+	r.Register("pagerduty", newPagerDutyFromSettings)
+	r.Register("opsgenie", newOpsgenieFromSettings)
+	r.Register("telegram", newTelegramFromSettings)
+	r.Register("mattermost", newMattermostFromSettings)
+	r.Register("matrix", newMatrixFromSettings)
+	r.Register("amqp", newAMQPFromSettings)
+	r.Register("nats", newNATSFromSettings)
+	r.Register("kafka", newKafkaFromSettings)
+	r.Register("file", newFileFromSettings)
+	r.Register("syslog", newSyslogFromSettings)
+	r.Register("inapp", newInAppFromSettings)
+	return r
+}
+
+// Register adds or replaces the factory of a channel type. Types are case insensitive.
+func (r *Registry) Register(channelType string, factory Factory) {
+	r.factories[strings.ToLower(channelType)] = factory
+}
+
+// Types returns the registered channel types in sorted order.
+func (r *Registry) Types() []string {
+	types := make([]string, 0, len(r.factories))
+	for channelType := range r.factories {
+		types = append(types, channelType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Build builds the sender of a named channel of the given type.
+func (r *Registry) Build(name, channelType string, settings map[string]any, deps Dependencies) (Sender, error) {
+	if name == "" {
+		return nil, ErrNoChannelName
+	}
+	factory, ok := r.factories[strings.ToLower(channelType)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownChannelType, channelType)
+	}
+	if deps.Client == nil {
+		deps.Client = http.DefaultClient
+	}
+	sender, err := factory(name, settings, deps)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return sender, nil
+}
+
+// decodeSettings decodes settings into a config. Keys match fields ignoring case and
+// underscores, durations and comma separated lists may be given as strings, and
+// unknown keys are an error, so typos do not go unnoticed.
+func decodeSettings(settings map[string]any, config any) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+		ErrorUnused:      true,
+		WeaklyTypedInput: true,
+		MatchName: func(key, field string) bool {
+			return strings.EqualFold(strings.ReplaceAll(key, "_", ""), field)
+		},
+		Result: config,
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(settings)
+}
+
+func newSlackFromSettings(name string, settings map[string]any, deps Dependencies) (Sender, error) {
+	var config SlackConfig
+	if err := decodeSettings(settings, &config); err != nil {
+		return nil, err
+	}
+	config.Name = name
+	return NewSlackWithConfig(config, deps.Client), nil
+}
+
+func newEmailFromSettings(name string, settings map[string]any, _ Dependencies) (Sender, error) {
+	var config EmailConfig
+	if err := decodeSettings(settings, &config); err != nil {
+		return nil, err
+	}
+	config.Name = name
+	return NewEmail(config), nil
+}
+
+func newTeamsFromSettings(name string, settings map[string]any, deps Dependencies) (Sender, error) {
+	var config TeamsConfig
+	if err := decodeSettings(settings, &config); err != nil {
+		return nil, err
+	}
+	config.Name = name
+	return NewTeamsWithConfig(config, deps.Client), nil
+}
+
+func newDiscordFromSettings(name string, settings map[string]any, deps Dependencies) (Sender, error) {
+	var config DiscordConfig
+	if err := decodeSettings(settings, &config); err != nil {
+		return nil, err
+	}
+	config.Name = name
+	return NewDiscord(config, deps.Client), nil
+}
+
+func newWebhookFromSettings(name string, settings map[string]any, deps Dependencies) (Sender, error) {
+	var config WebhookConfig
+	if err := decodeSettings(settings, &config); err != nil {
+		return nil, err
+	}
+	config.Name = name
+	return NewWebhook(config, deps.Client)
+}
+
+func newSMSFromSettings(name string, settings map[string]any, deps Dependencies) (Sender, error) {
+	var config SMSConfig
+	if err := decodeSettings(settings, &config); err != nil {
+		return nil, err
+	}
+	config.Name = name
+	return NewSMS(config, deps.Client)
+}
+
+// newFCMFromSettings reads the service account key from credentials_file.
+func newFCMFromSettings(name string, settings map[string]any, deps Dependencies) (Sender, error) {
+	var config struct {
+		FCMConfig       `mapstructure:",squash"`
+		CredentialsFile string
+	}
+	if err := decodeSettings(settings, &config); err != nil {
+		return nil, err
+	}
+	if config.CredentialsFile != "" {
+		credentials, err := os.ReadFile(config.CredentialsFile)
+		if err != nil {
+			return nil, err
+		}
+		config.CredentialsJSON = credentials
+	}
+	config.Name = name
+	return NewFCM(config.FCMConfig, deps.Client, deps.Tokens)
+}
+
+// newAPNsFromSettings reads the signing key from key_file.
+func newAPNsFromSettings(name string, settings map[string]any, deps Dependencies) (Sender, error) {
+	var config struct {
+		APNsConfig `mapstructure:",squash"`
+		KeyFile    string
+	}
+	if err := decodeSettings(settings, &config); err != nil {
+		return nil, err
+	}
+	if config.KeyFile != "" {
+		key, err := os.ReadFile(config.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.PrivateKey = key
+	}
+	config.Name = name
+	return NewAPNs(config.APNsConfig, deps.Client, deps.Tokens)
+}
+
+func newWebPushFromSettings(name string, settings map[string]any, deps Dependencies) (Sender, error) {
+	var config WebPushConfig
+	if err := decodeSettings(settings, &config); err != nil {
+		return nil, err
+	}
+	config.Name = name
+	return NewWebPush(config, deps.Client, deps.Subscriptions)
+}
+
+func newPagerDutyFromSettings(name string, settings map[string]any, deps Dependencies) (Sender, error) {
+	var config PagerDutyConfig
+	if err := decodeSettings(settings, &config); err != nil {
+		return nil, err
+	}
+	config.Name = name
+	return NewPagerDuty(config, deps.Client), nil
+}
+
+func newOpsgenieFromSettings(name string, settings map[string]any, deps Dependencies) (Sender, error) {
+	var config OpsgenieConfig
+	if err := decodeSettings(settings, &config); err != nil {
+		return nil, err
+	}
+	config.Name = name
+	return NewOpsgenie(config, deps.Client), nil
+}
+
+func newTelegramFromSettings(name string, settings map[string]any, deps Dependencies) (Sender, error) {
+	var config TelegramConfig
+	if err := decodeSettings(settings, &config); err != nil {
+		return nil, err
+	}
+	config.Name = name
+	return NewTelegram(config, deps.Client)
+}
+
+func newMattermostFromSettings(name string, settings map[string]any, deps Dependencies) (Sender, error) {
+	var config MattermostConfig
+	if err := decodeSettings(settings, &config); err != nil {
+		return nil, err
+	}
+	config.Name = name
+	return NewMattermost(config, deps.Client), nil
+}
+
+func newMatrixFromSettings(name string, settings map[string]any, deps Dependencies) (Sender, error) {
+	var config MatrixConfig
+	if err := decodeSettings(settings, &config); err != nil {
+		return nil, err
+	}
+	config.Name = name
+	return NewMatrix(config, deps.Client), nil
+}
+
+func newAMQPFromSettings(name string, settings map[string]any, _ Dependencies) (Sender, error) {
+	var config AMQPConfig
+	if err := decodeSettings(settings, &config); err != nil {
+		return nil, err
+	}
+	config.Name = name
+	return NewAMQP(config), nil
+}
+
+func newNATSFromSettings(name string, settings map[string]any, _ Dependencies) (Sender, error) {
+	var config NATSConfig
+	if err := decodeSettings(settings, &config); err != nil {
+		return nil, err
+	}
+	config.Name = name
+	return NewNATS(config), nil
+}
+
+func newKafkaFromSettings(name string, settings map[string]any, _ Dependencies) (Sender, error) {
+	var config KafkaConfig
+	if err := decodeSettings(settings, &config); err != nil {
+		return nil, err
+	}
+	config.Name = name
+	return NewKafka(config), nil
+}
+
+func newFileFromSettings(name string, settings map[string]any, _ Dependencies) (Sender, error) {
+	var config FileConfig
+	if err := decodeSettings(settings, &config); err != nil {
+		return nil, err
+	}
+	config.Name = name
+	return NewFile(config)
+}
+
+// newSyslogFromSettings trusts the certificate authorities in tls_ca_file for TLS.
+func newSyslogFromSettings(name string, settings map[string]any, _ Dependencies) (Sender, error) {
+	var config struct {
+		SyslogConfig `mapstructure:",squash"`
+		TLSCAFile    string
+	}
+	if err := decodeSettings(settings, &config); err != nil {
+		return nil, err
+	}
+	if config.TLSCAFile != "" {
+		tlsConfig, err := loadTLSRoots(config.TLSCAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.TLS = tlsConfig
+	}
+	config.Name = name
+	return NewSyslog(config.SyslogConfig)
+}
+
+// loadTLSRoots returns a TLS config trusting the certificate authorities in a PEM file.
+func loadTLSRoots(caFile string) (*tls.Config, error) {
+	ca, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+	return &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}, nil
+}
+
+// newInAppFromSettings adds notifications to the inboxes of the dependencies. It has
+// no settings of its own.
+func newInAppFromSettings(name string, settings map[string]any, deps Dependencies) (Sender, error) {
+	var config struct{}
+	if err := decodeSettings(settings, &config); err != nil {
+		return nil, err
+	}
+	if deps.Inbox == nil {
+		return nil, ErrNoInbox
+	}
+	return &InApp{name: name, inbox: deps.Inbox}, nil
+}
@@ -0,0 +1,170 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Build(t *testing.T) {
+	tests := []struct {
+		name        string
+		channelName string
+		channelType string
+		settings    map[string]any
+		check       func(t *testing.T, sender Sender)
+		expectErr   error
+		expectText  string
+	}{
+		{
+			name:        "Slack settings are decoded from snake case",
+			channelName: "slack-ops",
+			channelType: "slack",
+			settings: map[string]any{
+				"bot_token": "xoxb-token",
+				"channel":   "C123",
+				"cache_ttl": "5m",
+			},
+			check: func(t *testing.T, sender Sender) {
+				slack := sender.(*Slack)
+				assert.Equal(t, "xoxb-token", slack.config.BotToken)
+				assert.Equal(t, "C123", slack.config.Channel)
+				assert.Equal(t, 5*time.Minute, slack.config.CacheTTL)
+			},
+		},
+		{
+			name:        "Types are case insensitive and lists may be comma separated",
+			channelName: "email-billing",
+			channelType: "Email",
+			settings: map[string]any{
+				"host": "smtp.example.com",
+				"port": 587,
+				"from": "billing@example.com",
+				"to":   "finance@example.com,cfo@example.com",
+			},
+			check: func(t *testing.T, sender Sender) {
+				email := sender.(*Email)
+				assert.Equal(t, "587", email.config.Port)
+				assert.Equal(t, []string{"finance@example.com", "cfo@example.com"}, email.config.To)
+			},
+		},
+		{
+			name:        "Teams channel without settings of its own",
+			channelName: "teams-dev",
+			channelType: "teams",
+			settings:    map[string]any{"webhook_url": "https://example.webhook.office.com/hook"},
+			check: func(t *testing.T, sender Sender) {
+				assert.Equal(t, "https://example.webhook.office.com/hook", sender.(*Teams).webhookURL)
+			},
+		},
+		{
+			name:        "Unknown settings are an error",
+			channelName: "slack-ops",
+			channelType: "slack",
+			settings:    map[string]any{"webhok_url": "https://hooks.slack.com/services/T/B/X"},
+			expectText:  "slack-ops: 1 error(s) decoding:\n\n* '' has invalid keys: webhok_url",
+		},
+		{
+			name:        "Errors of the constructor are returned",
+			channelName: "audit",
+			channelType: "file",
+			settings:    map[string]any{},
+			expectErr:   ErrNoFilePath,
+		},
+		{
+			name:        "Invalid settings are rejected by the constructor",
+			channelName: "sms-oncall",
+			channelType: "sms",
+			settings:    map[string]any{"max_parts": -1},
+			expectErr:   ErrInvalidSMSMaxParts,
+		},
+		{
+			name:        "Missing files are reported",
+			channelName: "fcm-app",
+			channelType: "fcm",
+			settings:    map[string]any{"credentials_file": filepath.Join(t.TempDir(), "missing.json")},
+			expectText:  "no such file or directory",
+		},
+		{
+			name:        "InApp channel requires an inbox",
+			channelName: "inbox",
+			channelType: "inapp",
+			expectErr:   ErrNoInbox,
+		},
+		{
+			name:        "Unknown type is an error",
+			channelName: "carrier-pigeon",
+			channelType: "pigeon",
+			expectErr:   ErrUnknownChannelType,
+		},
+		{
+			name:        "Name is required",
+			channelType: "slack",
+			expectErr:   ErrNoChannelName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender, err := NewRegistry().Build(tt.channelName, tt.channelType, tt.settings, Dependencies{})
+
+			switch {
+			case tt.expectErr != nil:
+				assert.ErrorIs(t, err, tt.expectErr)
+			case tt.expectText != "":
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectText)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tt.channelName, sender.GetName())
+				tt.check(t, sender)
+			}
+		})
+	}
+}
+
+func TestRegistry_BuildInApp(t *testing.T) {
+	inbox := NewInbox(NewMemoryInboxStore())
+	sender, err := NewRegistry().Build("inbox", "InApp", nil, Dependencies{Inbox: inbox})
+	require.NoError(t, err)
+	assert.Equal(t, "inbox", sender.GetName())
+	assert.Same(t, inbox, sender.(*InApp).inbox)
+
+	// The channel has no settings
+	_, err = NewRegistry().Build("inbox", "inapp", map[string]any{"store": "redis"}, Dependencies{Inbox: inbox})
+	assert.Error(t, err)
+}
+
+// namedSender is a sender of a custom channel type.
+type namedSender struct {
+	name string
+}
+
+func (s namedSender) GetName() string                     { return s.name }
+func (s namedSender) Send(context.Context, Message) error { return nil }
+
+func TestRegistry_Register(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("Custom", func(name string, settings map[string]any, deps Dependencies) (Sender, error) {
+		if settings["fail"] == true {
+			return nil, errors.New("failed")
+		}
+		// Missing dependencies default to the shared HTTP client
+		assert.Equal(t, http.DefaultClient, deps.Client)
+		return namedSender{name: name}, nil
+	})
+
+	sender, err := registry.Build("custom-1", "custom", nil, Dependencies{})
+	require.NoError(t, err)
+	assert.Equal(t, "custom-1", sender.GetName())
+	_, err = registry.Build("custom-2", "custom", map[string]any{"fail": true}, Dependencies{})
+	assert.EqualError(t, err, "custom-2: failed")
+	assert.Contains(t, registry.Types(), "custom")
+	assert.Contains(t, registry.Types(), "slack")
+}
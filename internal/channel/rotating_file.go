@@ -0,0 +1,237 @@
+package channel
+
+import (
+	"compress/gzip"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotationTimeFormat is the time in the names of rotated files. It sorts in time order.
+const rotationTimeFormat = "2006-01-02T15-04-05.000"
+
+// rotatingFile is an append-only file that is rotated once it would grow beyond a
+// size or a new period starts. Rotated files are renamed after the time of their
+// rotation, such as notifications-2024-05-01T12-00-00.000.log, and optionally
+// compressed with gzip in the background.
+type rotatingFile struct {
+	path       string
+	maxSize    int64         // Size in bytes a file may reach, unlimited when 0.
+	period     time.Duration // Length of the periods files cover, unlimited when 0.
+	maxBackups int           // Number of rotated files kept, all when 0.
+	compress   bool
+	now        func() time.Time
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time // Start of the period the file covers.
+	closed bool
+
+	// Compression of rotated files and the removal of old ones run one at a time.
+	housekeeping sync.Mutex
+	wg           sync.WaitGroup
+}
+
+// open opens the file, appending to an existing one.
+func (f *rotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	// A file left from an earlier run covers the period of its last write.
+	f.opened = f.now()
+	if f.size > 0 {
+		f.opened = info.ModTime()
+	}
+	return nil
+}
+
+// Write appends p to the file, rotating it first if needed, and syncs it to disk.
+// It fails with os.ErrClosed once the file is closed.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	if f.needsRotation(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+	return n, f.file.Sync()
+}
+
+// needsRotation reports whether writing n bytes requires a new file. Files are never
+// left empty, so a write larger than the maximum size gets a file of its own.
+func (f *rotatingFile) needsRotation(n int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.maxSize > 0 && f.size+n > f.maxSize {
+		return true
+	}
+	return f.period > 0 && !f.now().Truncate(f.period).Equal(f.opened.Truncate(f.period))
+}
+
+// rotate renames the file and opens a new one. Rotated files are compressed in the
+// background, so writes do not wait for it. Compressing and removing old files only
+// logs errors, as the new file can be written either way.
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	rotated := f.rotatedName(f.now())
+	if err := os.Rename(f.path, rotated); err != nil {
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	if !f.compress {
+		f.housekeep(rotated)
+		return nil
+	}
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		f.housekeep(rotated)
+	}()
+	return nil
+}
+
+// housekeep compresses a rotated file if enabled and removes the oldest rotated files
+// beyond the number to keep.
+func (f *rotatingFile) housekeep(rotated string) {
+	f.housekeeping.Lock()
+	defer f.housekeeping.Unlock()
+	if f.compress {
+		if err := compressFile(rotated); err != nil {
+			log.Printf("error: failed to compress %s: %v\n", rotated, err)
+		}
+	}
+	if err := f.removeBackups(); err != nil {
+		log.Printf("error: failed to remove rotated files of %s: %v\n", f.path, err)
+	}
+}
+
+// rotatedName returns the name of the file rotated at t.
+func (f *rotatingFile) rotatedName(t time.Time) string {
+	ext := filepath.Ext(f.path)
+	return strings.TrimSuffix(f.path, ext) + "-" + t.UTC().Format(rotationTimeFormat) + ext
+}
+
+// backups returns the rotated files, oldest first.
+func (f *rotatingFile) backups() ([]string, error) {
+	ext := filepath.Ext(f.path)
+	prefix := strings.TrimSuffix(filepath.Base(f.path), ext) + "-"
+	entries, err := os.ReadDir(filepath.Dir(f.path))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".gz")
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		if _, err := time.Parse(rotationTimeFormat, stamp); err != nil {
+			continue
+		}
+		names = append(names, filepath.Join(filepath.Dir(f.path), entry.Name()))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// removeBackups removes the oldest rotated files beyond the number to keep.
+func (f *rotatingFile) removeBackups() error {
+	if f.maxBackups <= 0 {
+		return nil
+	}
+	names, err := f.backups()
+	if err != nil || len(names) <= f.maxBackups {
+		return err
+	}
+	for _, name := range names[:len(names)-f.maxBackups] {
+		if err := os.Remove(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the file and waits for the compression of rotated files.
+func (f *rotatingFile) Close() error {
+	defer f.wg.Wait()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// compressFile replaces a file with a gzip compressed copy named name.gz.
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	// Write to a temporary file, so an interrupted compression leaves no broken archive.
+	tmp := name + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, name+".gz")
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Remove(name)
+}
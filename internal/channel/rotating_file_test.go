@@ -0,0 +1,174 @@
+package channel
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile_Write(t *testing.T) {
+	start := time.Date(2024, 5, 1, 23, 59, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		maxSize      int64
+		period       time.Duration
+		maxBackups   int
+		writes       []string
+		advance      time.Duration // Time passing after each write.
+		expectFile   string
+		expectBackup []string
+	}{
+		{
+			name:       "Writes without limits are appended to one file",
+			writes:     []string{"one\n", "two\n", "three\n"},
+			expectFile: "one\ntwo\nthree\n",
+		},
+		{
+			name:       "File is rotated before it grows beyond the maximum size",
+			maxSize:    8,
+			writes:     []string{"one\n", "two\n", "three\n"},
+			advance:    time.Second,
+			expectFile: "three\n",
+			expectBackup: []string{
+				"notifications-2024-05-01T23-59-02.000.log",
+			},
+		},
+		{
+			name:       "Writes larger than the maximum size get a file of their own",
+			maxSize:    4,
+			writes:     []string{"one\n", "three\n", "two\n"},
+			advance:    time.Second,
+			expectFile: "two\n",
+			expectBackup: []string{
+				"notifications-2024-05-01T23-59-01.000.log",
+				"notifications-2024-05-01T23-59-02.000.log",
+			},
+		},
+		{
+			name:       "File is rotated when a new period starts",
+			period:     24 * time.Hour,
+			writes:     []string{"one\n", "two\n", "three\n"},
+			advance:    40 * time.Second,
+			expectFile: "three\n",
+			expectBackup: []string{
+				"notifications-2024-05-02T00-00-20.000.log",
+			},
+		},
+		{
+			name:       "Oldest rotated files are removed",
+			maxSize:    4,
+			maxBackups: 1,
+			writes:     []string{"one\n", "two\n", "six\n"},
+			advance:    time.Second,
+			expectFile: "six\n",
+			expectBackup: []string{
+				"notifications-2024-05-01T23-59-02.000.log",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			now := start
+			f := &rotatingFile{
+				path:       filepath.Join(dir, "notifications.log"),
+				maxSize:    tt.maxSize,
+				period:     tt.period,
+				maxBackups: tt.maxBackups,
+				now:        func() time.Time { return now },
+			}
+			defer f.Close()
+
+			for _, write := range tt.writes {
+				n, err := f.Write([]byte(write))
+				require.NoError(t, err)
+				assert.Equal(t, len(write), n)
+				now = now.Add(tt.advance)
+			}
+
+			content, err := os.ReadFile(f.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectFile, string(content))
+			backups, err := f.backups()
+			require.NoError(t, err)
+			var names []string
+			for _, backup := range backups {
+				names = append(names, filepath.Base(backup))
+			}
+			assert.Equal(t, tt.expectBackup, names)
+		})
+	}
+}
+
+func TestRotatingFile_Compress(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	f := &rotatingFile{
+		path:     filepath.Join(dir, "notifications.log"),
+		maxSize:  4,
+		compress: true,
+		now:      func() time.Time { return now },
+	}
+	_, err := f.Write([]byte("one\n"))
+	require.NoError(t, err)
+	_, err = f.Write([]byte("two\n"))
+	require.NoError(t, err)
+
+	// The rotated file is replaced by a compressed copy in the background, which
+	// closing waits for
+	require.NoError(t, f.Close())
+	rotated := filepath.Join(dir, "notifications-2024-05-01T12-00-00.000.log")
+	assert.NoFileExists(t, rotated)
+	archive, err := os.Open(rotated + ".gz")
+	require.NoError(t, err)
+	defer archive.Close()
+	zr, err := gzip.NewReader(archive)
+	require.NoError(t, err)
+	content, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, "one\n", string(content))
+}
+
+func TestRotatingFile_ExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notifications.log")
+	require.NoError(t, os.WriteFile(path, []byte("old\n"), 0o640))
+	lastWrite := time.Date(2024, 4, 30, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(path, lastWrite, lastWrite))
+
+	// A file of an earlier period is rotated on the first write
+	f := &rotatingFile{
+		path:   path,
+		period: 24 * time.Hour,
+		now:    func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) },
+	}
+	defer f.Close()
+	_, err := f.Write([]byte("new\n"))
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new\n", string(content))
+	content, err = os.ReadFile(filepath.Join(dir, "notifications-2024-05-01T12-00-00.000.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "old\n", string(content))
+}
+
+func TestRotatingFile_WriteAfterClose(t *testing.T) {
+	f := &rotatingFile{path: filepath.Join(t.TempDir(), "notifications.log"), now: time.Now}
+	_, err := f.Write([]byte("one\n"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// The file is not reopened once closed
+	_, err = f.Write([]byte("two\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+	assert.NoError(t, f.Close())
+}
@@ -1,7 +1,17 @@
 package channel
 
+import "context"
+
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Sender
 type Sender interface {
 	GetName() string
-	Send(s string) error
+	Send(ctx context.Context, message Message) error
+}
+
+// senderName returns the configured name of a sender, or the name of its channel type.
+func senderName(name, defaultName string) string {
+	if name == "" {
+		return defaultName
+	}
+	return name
 }
@@ -0,0 +1,183 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	ErrSlackNoTarget = errors.New("slack message has no target channel")
+)
+
+// slackResponse holds the fields shared by all Web API responses.
+type slackResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// postMessageResponse is the response of chat.postMessage.
+type postMessageResponse struct {
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+}
+
+// lookupByEmailResponse is the response of users.lookupByEmail.
+type lookupByEmailResponse struct {
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+}
+
+// conversationsListResponse is a page of conversations.list.
+type conversationsListResponse struct {
+	Channels []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"channels"`
+	Metadata struct {
+		NextCursor string `json:"next_cursor"`
+	} `json:"response_metadata"`
+}
+
+// resolve turns a target into a channel or user ID. Targets are either IDs, channel
+// names starting with "#" or email addresses of users, who receive a direct message.
+func (s *Slack) resolve(ctx context.Context, target string) (string, error) {
+	target = strings.TrimSpace(target)
+	switch {
+	case target == "":
+		return "", Permanent(ErrSlackNoTarget)
+	case strings.HasPrefix(target, "#"):
+		return s.lookupChannel(ctx, strings.ToLower(strings.TrimPrefix(target, "#")))
+	case strings.Contains(target, "@"):
+		return s.lookupUser(ctx, strings.ToLower(target))
+	default:
+		return target, nil
+	}
+}
+
+// lookupUser returns the ID of the user with the given email address.
+func (s *Slack) lookupUser(ctx context.Context, email string) (string, error) {
+	key := "user:" + email
+	if id, found := s.lookups.get(key); found {
+		return id, nil
+	}
+
+	var result lookupByEmailResponse
+	if err := s.callAPI(ctx, http.MethodGet, "users.lookupByEmail", url.Values{"email": {email}}, nil, &result); err != nil {
+		return "", err
+	}
+	s.lookups.set(key, result.User.ID)
+	return result.User.ID, nil
+}
+
+// lookupChannel returns the ID of the channel with the given name. All channels seen
+// while paging through the list are cached, so later lookups are usually served from
+// the cache.
+func (s *Slack) lookupChannel(ctx context.Context, name string) (string, error) {
+	key := "channel:" + name
+	if id, found := s.lookups.get(key); found {
+		return id, nil
+	}
+
+	query := url.Values{
+		"types":            {"public_channel,private_channel"},
+		"exclude_archived": {"true"},
+		"limit":            {"1000"},
+	}
+	for {
+		var page conversationsListResponse
+		if err := s.callAPI(ctx, http.MethodGet, "conversations.list", query, nil, &page); err != nil {
+			return "", err
+		}
+		for _, channel := range page.Channels {
+			s.lookups.set("channel:"+strings.ToLower(channel.Name), channel.ID)
+		}
+		if id, found := s.lookups.get(key); found {
+			return id, nil
+		}
+		if page.Metadata.NextCursor == "" {
+			return "", Permanent(fmt.Errorf("slack channel not found: #%s", name))
+		}
+		query.Set("cursor", page.Metadata.NextCursor)
+	}
+}
+
+// callAPI calls a Web API method, sending body as JSON or query as URL parameters,
+// and decodes a successful response into result.
+func (s *Slack) callAPI(ctx context.Context, httpMethod, method string, query url.Values, body any, result any) error {
+	endpoint := strings.TrimSuffix(s.config.APIURL, "/") + "/" + method
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return Permanent(err)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, httpMethod, endpoint, reqBody)
+	if err != nil {
+		return Permanent(err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+	req.Header.Set("Authorization", "Bearer "+s.config.BotToken)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return Retryable(err)
+	}
+	defer resp.Body.Close()
+
+	if err := CheckResponse(resp); err != nil {
+		return err
+	}
+
+	// The Web API reports failures in the body of a 200 response.
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return Retryable(err)
+	}
+	var status slackResponse
+	if err := json.Unmarshal(b, &status); err != nil {
+		return Retryable(fmt.Errorf("decoding slack response: %w", err))
+	}
+	if !status.OK {
+		return slackAPIError(status.Error)
+	}
+	if err := json.Unmarshal(b, result); err != nil {
+		return Retryable(fmt.Errorf("decoding slack response: %w", err))
+	}
+	return nil
+}
+
+// slackRetryableErrors are Web API errors that may succeed when retried.
+var slackRetryableErrors = map[string]bool{
+	"internal_error":      true,
+	"fatal_error":         true,
+	"service_unavailable": true,
+	"request_timeout":     true,
+}
+
+// slackAPIError classifies an error reported by the Web API.
+func slackAPIError(code string) error {
+	err := fmt.Errorf("slack api error: %s", code)
+	switch {
+	case code == "ratelimited":
+		return RateLimited(err, 0)
+	case slackRetryableErrors[code]:
+		return Retryable(err)
+	default:
+		return Permanent(err)
+	}
+}
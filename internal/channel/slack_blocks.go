@@ -0,0 +1,199 @@
+package channel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Block Kit limits, see https://api.slack.com/reference/block-kit/blocks.
+const (
+	slackMaxBlocks        = 50
+	slackMaxHeaderLength  = 150
+	slackMaxSectionLength = 3000
+	slackMaxFieldLength   = 2000
+	slackMaxFields        = 10
+	slackMaxContext       = 10
+	slackMaxActions       = 25
+	slackMaxButtonLength  = 75
+)
+
+// slackSeverityEmoji maps severities to the emoji prefixed to the message title.
+var slackSeverityEmoji = map[Severity]string{
+	SeverityWarning:  ":warning:",
+	SeverityError:    ":x:",
+	SeverityCritical: ":rotating_light:",
+}
+
+// slackSeverityColor maps severities to the colour of the attachment bar.
+var slackSeverityColor = map[Severity]string{
+	SeverityInfo:     "#439FE0",
+	SeverityWarning:  "#DAA038",
+	SeverityError:    "#D40E0D",
+	SeverityCritical: "#8B0000",
+}
+
+// SlackBlock is a Block Kit layout block.
+type SlackBlock struct {
+	Type     string      `json:"type"`
+	Text     *SlackText  `json:"text,omitempty"`
+	Fields   []SlackText `json:"fields,omitempty"`
+	Elements []any       `json:"elements,omitempty"` // SlackText for context blocks, SlackButton for actions blocks.
+}
+
+// SlackText is a Block Kit text object.
+type SlackText struct {
+	Type  string `json:"type"` // Either "plain_text" or "mrkdwn".
+	Text  string `json:"text"`
+	Emoji bool   `json:"emoji,omitempty"`
+}
+
+// SlackButton is a Block Kit button element opening a URL.
+type SlackButton struct {
+	Type string    `json:"type"`
+	Text SlackText `json:"text"`
+	URL  string    `json:"url"`
+}
+
+// SlackAttachment is a secondary attachment, used to show a coloured bar next to the blocks.
+type SlackAttachment struct {
+	Color  string       `json:"color,omitempty"`
+	Blocks []SlackBlock `json:"blocks,omitempty"`
+}
+
+// slackAttachments renders a message as blocks inside an attachment coloured by severity.
+func slackAttachments(message Message) []SlackAttachment {
+	return []SlackAttachment{{
+		Color:  slackSeverityColor[message.SeverityOrDefault()],
+		Blocks: slackBlocks(message),
+	}}
+}
+
+// slackBlocks renders a message as Block Kit blocks: a header with the title, sections
+// with the body and metadata, buttons for links and a context line with severity and tags.
+func slackBlocks(message Message) []SlackBlock {
+	var blocks []SlackBlock
+
+	// Show the title as header, prefixed with an emoji for urgent messages.
+	if message.Title != "" {
+		title := message.Title
+		if emoji := slackSeverityEmoji[message.Severity]; emoji != "" {
+			title = emoji + " " + title
+		}
+		blocks = append(blocks, SlackBlock{
+			Type: "header",
+			Text: &SlackText{Type: "plain_text", Text: truncate(title, slackMaxHeaderLength), Emoji: true},
+		})
+	}
+
+	// Split long bodies over several sections.
+	for _, chunk := range splitText(slackBody(message), slackMaxSectionLength) {
+		blocks = append(blocks, SlackBlock{
+			Type: "section",
+			Text: &SlackText{Type: "mrkdwn", Text: chunk},
+		})
+	}
+
+	// Show metadata as two-column fields, ten per section.
+	var fields []SlackText
+	for _, key := range message.MetadataKeys() {
+		text := fmt.Sprintf("*%s*\n%s", slackEscape(key), slackEscape(message.Metadata[key]))
+		fields = append(fields, SlackText{Type: "mrkdwn", Text: truncate(text, slackMaxFieldLength)})
+	}
+	for len(fields) > 0 {
+		n := len(fields)
+		if n > slackMaxFields {
+			n = slackMaxFields
+		}
+		blocks = append(blocks, SlackBlock{Type: "section", Fields: fields[:n]})
+		fields = fields[n:]
+	}
+
+	// Turn links and attachments available by URL into buttons.
+	var buttons []any
+	for _, link := range slackLinks(message) {
+		if len(buttons) == slackMaxActions {
+			break
+		}
+		buttons = append(buttons, SlackButton{
+			Type: "button",
+			Text: SlackText{Type: "plain_text", Text: truncate(link.title(), slackMaxButtonLength), Emoji: true},
+			URL:  link.URL,
+		})
+	}
+	if len(buttons) > 0 {
+		blocks = append(blocks, SlackBlock{Type: "actions", Elements: buttons})
+	}
+
+	// Finish with the severity and tags.
+	context := []any{SlackText{Type: "mrkdwn", Text: "Severity: *" + string(message.SeverityOrDefault()) + "*"}}
+	if tags := slackTags(message); tags != "" {
+		context = append(context, SlackText{Type: "mrkdwn", Text: tags})
+	}
+	if len(context) > slackMaxContext {
+		context = context[:slackMaxContext]
+	}
+	blocks = append(blocks, SlackBlock{Type: "context", Elements: context})
+
+	if len(blocks) > slackMaxBlocks {
+		blocks = blocks[:slackMaxBlocks]
+	}
+	return blocks
+}
+
+// slackBody returns the body in mrkdwn, preferring the Markdown body as Slack renders a subset of it.
+func slackBody(message Message) string {
+	if message.Markdown != "" {
+		return message.Markdown
+	}
+	return slackEscape(message.Body)
+}
+
+// slackLinks returns the links of a message followed by attachments available by URL.
+func slackLinks(message Message) []Link {
+	links := append([]Link(nil), message.Links...)
+	for _, attachment := range message.Attachments {
+		if attachment.URL != "" {
+			links = append(links, Link{Title: attachment.Filename, URL: attachment.URL})
+		}
+	}
+	return links
+}
+
+// slackTags renders the tags of a message as inline code.
+func slackTags(message Message) string {
+	tags := make([]string, len(message.Tags))
+	for i, tag := range message.Tags {
+		tags[i] = "`" + slackEscape(tag) + "`"
+	}
+	return strings.Join(tags, " ")
+}
+
+// truncate shortens text to at most max runes, marking the cut with an ellipsis.
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// splitText splits text into chunks of at most max runes, preferring to break at newlines.
+func splitText(text string, max int) []string {
+	var chunks []string
+	runes := []rune(text)
+	for len(runes) > max {
+		cut := max
+		for i := max - 1; i > max/2; i-- {
+			if runes[i] == '\n' {
+				cut = i + 1
+				break
+			}
+		}
+		chunks = append(chunks, string(runes[:cut]))
+		runes = runes[cut:]
+	}
+	if len(runes) > 0 {
+		chunks = append(chunks, string(runes))
+	}
+	return chunks
+}
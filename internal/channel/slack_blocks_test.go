@@ -0,0 +1,71 @@
+package channel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlackBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  Message
+		expected []SlackBlock
+	}{
+		{
+			name:    "Plain body",
+			message: Message{Body: "a < b"},
+			expected: []SlackBlock{
+				{Type: "section", Text: &SlackText{Type: "mrkdwn", Text: "a &lt; b"}},
+				{Type: "context", Elements: []any{SlackText{Type: "mrkdwn", Text: "Severity: *info*"}}},
+			},
+		},
+		{
+			name: "Rich message",
+			message: Message{
+				Title:       "Disk almost full",
+				Markdown:    "Only *5%* left",
+				Severity:    SeverityCritical,
+				Tags:        []string{"prod"},
+				Metadata:    map[string]string{"host": "db-1"},
+				Links:       []Link{{Title: "Dashboard", URL: "https://example.com/d"}},
+				Attachments: []Attachment{{Filename: "graph.png", URL: "https://example.com/g.png"}},
+			},
+			expected: []SlackBlock{
+				{Type: "header", Text: &SlackText{Type: "plain_text", Text: ":rotating_light: Disk almost full", Emoji: true}},
+				{Type: "section", Text: &SlackText{Type: "mrkdwn", Text: "Only *5%* left"}},
+				{Type: "section", Fields: []SlackText{{Type: "mrkdwn", Text: "*host*\ndb-1"}}},
+				{Type: "actions", Elements: []any{
+					SlackButton{Type: "button", Text: SlackText{Type: "plain_text", Text: "Dashboard", Emoji: true}, URL: "https://example.com/d"},
+					SlackButton{Type: "button", Text: SlackText{Type: "plain_text", Text: "graph.png", Emoji: true}, URL: "https://example.com/g.png"},
+				}},
+				{Type: "context", Elements: []any{
+					SlackText{Type: "mrkdwn", Text: "Severity: *critical*"},
+					SlackText{Type: "mrkdwn", Text: "`prod`"},
+				}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, slackBlocks(tt.message))
+		})
+	}
+}
+
+func TestSlackBlocks_Limits(t *testing.T) {
+	// Long titles are truncated and long bodies split over several sections
+	blocks := slackBlocks(Message{
+		Title: strings.Repeat("t", 200),
+		Body:  strings.Repeat("line\n", 1000),
+	})
+	assert.Len(t, []rune(blocks[0].Text.Text), slackMaxHeaderLength)
+	assert.Equal(t, "section", blocks[1].Type)
+	assert.Equal(t, "section", blocks[2].Type)
+	for _, block := range blocks[1:3] {
+		assert.LessOrEqual(t, len(block.Text.Text), slackMaxSectionLength)
+		assert.True(t, strings.HasSuffix(block.Text.Text, "\n"))
+	}
+}
@@ -2,63 +2,272 @@ package channel
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 )
 
+// SlackAPIURL is the base URL of the Slack Web API used when a bot token is configured.
+const SlackAPIURL = "https://slack.com/api"
+
+// slackThreadLimit is the number of correlation keys whose thread is remembered.
+const slackThreadLimit = 10000
+
+// SlackConfig holds the settings of the Slack channel. Messages are posted through
+// the Web API when BotToken is set and to the incoming webhook otherwise.
+type SlackConfig struct {
+	WebhookURL string        // Incoming webhook URL.
+	BotToken   string        // Bot token used for the Web API, enables threads and recipients.
+	Channel    string        // Default target for messages without recipients in Web API mode.
+	Username   string        // Optional username override.
+	IconEmoji  string        // Optional icon emoji override, such as ":robot_face:".
+	IconURL    string        // Optional icon image override.
+	APIURL     string        // Base URL of the Web API, defaults to SlackAPIURL.
+	CacheTTL   time.Duration // How long channel and user lookups are cached, defaults to an hour.
+	Name       string        // Channel name, defaults to Slack.
+}
+
 // Slack represents a Slack channel for sending messages.
 type Slack struct {
-	webhookURL string       // The webhook URL for sending messages to Slack.
-	name       string       // The name of the sender.
-	client     *http.Client // HTTP client for making requests.
+	config  SlackConfig
+	name    string       // The name of the sender.
+	client  *http.Client // HTTP client for making requests.
+	threads *slackThreads
+	lookups *lookupCache
 }
 
 // PostMessageRequest is the structure used to define the JSON request for posting a message to Slack.
 type PostMessageRequest struct {
-	Text string `json:"text"` // The text content of the message.
+	Channel     string            `json:"channel,omitempty"`     // Channel or user ID, only used by the Web API.
+	Text        string            `json:"text"`                  // The text content of the message, the fallback when blocks are set.
+	Attachments []SlackAttachment `json:"attachments,omitempty"` // Block Kit layout of the message, coloured by severity.
+	ThreadTS    string            `json:"thread_ts,omitempty"`   // Timestamp of the message to reply to.
+	Username    string            `json:"username,omitempty"`
+	IconEmoji   string            `json:"icon_emoji,omitempty"`
+	IconURL     string            `json:"icon_url,omitempty"`
 }
 
-// NewSlack creates a new Slack channel instance.
+// NewSlack creates a new Slack channel instance posting to an incoming webhook.
 func NewSlack(webhookURL string, client *http.Client) *Slack {
+	return NewSlackWithConfig(SlackConfig{WebhookURL: webhookURL}, client)
+}
+
+// NewSlackWithConfig creates a new Slack channel instance from its settings.
+func NewSlackWithConfig(config SlackConfig, client *http.Client) *Slack {
+	if config.APIURL == "" {
+		config.APIURL = SlackAPIURL
+	}
+	if config.CacheTTL == 0 {
+		config.CacheTTL = time.Hour
+	}
 	return &Slack{
-		webhookURL: webhookURL,
-		name:       "Slack",
-		client:     client,
+		config:  config,
+		name:    senderName(config.Name, "Slack"),
+		client:  client,
+		threads: newSlackThreads(slackThreadLimit),
+		lookups: newLookupCache(config.CacheTTL),
 	}
 }
 
-// Send sends a message to the Slack channel.
-func (s *Slack) Send(message string) error {
-	// Create a JSON request body.
-	reqBody, err := json.Marshal(PostMessageRequest{
-		Text: message,
-	})
+// Send sends a message to the Slack channel. With the Web API the message is posted
+// to each of its recipients, or the configured channel when it has none, and messages
+// sharing a correlation key are posted as replies in the thread of the first one.
+// Recipients the message was posted to in an earlier attempt are skipped.
+func (s *Slack) Send(ctx context.Context, message Message) error {
+	request := PostMessageRequest{
+		Text:        slackText(message),
+		Attachments: slackAttachments(message),
+		Username:    s.config.Username,
+		IconEmoji:   s.config.IconEmoji,
+		IconURL:     s.config.IconURL,
+	}
+	if s.config.BotToken == "" {
+		if len(message.Recipients) > 0 {
+			return Permanent(fmt.Errorf("%w: slack recipients require a bot token", ErrRecipientsNotSupported))
+		}
+		return s.postWebhook(ctx, request, message)
+	}
+
+	targets := message.Recipients
+	if len(targets) == 0 {
+		targets = []string{s.config.Channel}
+	}
+	var errs []error
+	for _, target := range targets {
+		err := sendOnce(ctx, target, func() error {
+			return s.sendTo(ctx, target, request, message)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", target, err))
+		}
+	}
+	return JoinErrors(errs...)
+}
+
+// GetName returns the name of the Slack sender.
+func (s *Slack) GetName() string {
+	return s.name
+}
+
+// sendTo posts a message to a single target with chat.postMessage.
+func (s *Slack) sendTo(ctx context.Context, target string, request PostMessageRequest, message Message) error {
+	channelID, err := s.resolve(ctx, target)
 	if err != nil {
 		return err
 	}
+	request.Channel = channelID
+
+	// Reply in the thread started by an earlier message with the same correlation key.
+	threadKey := ""
+	if message.CorrelationKey != "" {
+		threadKey = channelID + "\x00" + message.CorrelationKey
+	}
+	thread, threaded := s.threads.get(threadKey)
+	if threaded {
+		request.Channel = thread.Channel
+		request.ThreadTS = thread.TS
+	}
+
+	var result postMessageResponse
+	if err := s.callAPI(ctx, http.MethodPost, "chat.postMessage", nil, request, &result); err != nil {
+		return err
+	}
+
+	// Remember the first message of a correlation key as the root of its thread.
+	if !threaded {
+		s.threads.set(threadKey, slackThread{Channel: result.Channel, TS: result.TS})
+	}
+	log.Printf("slack message sent to %s: %s", target, message)
+
+	return nil
+}
+
+// postWebhook posts a message to the incoming webhook.
+func (s *Slack) postWebhook(ctx context.Context, request PostMessageRequest, message Message) error {
+	// Create a JSON request body.
+	reqBody, err := json.Marshal(request)
+	if err != nil {
+		return Permanent(err)
+	}
 
 	// Send the POST request to the Slack webhook URL.
-	resp, err := s.client.Post(s.webhookURL, "application/json", bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.WebhookURL, bytes.NewBuffer(reqBody))
 	if err != nil {
-		return err
+		return Permanent(err)
 	}
-	if resp.StatusCode != http.StatusOK {
-		b, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return err
-		}
-		return fmt.Errorf("request failed: %s", string(b))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return Retryable(err)
 	}
-	log.Printf("slack message sent: %s", message)
 	defer resp.Body.Close() // Close the response body when done with it.
 
+	// Classify failures so the notifier knows whether to retry.
+	if err := CheckResponse(resp); err != nil {
+		return err
+	}
+	log.Printf("slack message sent: %s", message)
+
 	return nil
 }
 
-// GetName returns the name of the Slack sender.
-func (s *Slack) GetName() string {
-	return s.name
+// slackThread identifies the first message of a thread.
+type slackThread struct {
+	Channel string
+	TS      string
+}
+
+// slackThreads remembers the thread of each correlation key, forgetting
+// the oldest keys once the limit is reached.
+type slackThreads struct {
+	mu      sync.Mutex
+	limit   int
+	threads map[string]slackThread
+	order   []string
+}
+
+// newSlackThreads creates a new slackThreads remembering up to limit keys.
+func newSlackThreads(limit int) *slackThreads {
+	return &slackThreads{
+		limit:   limit,
+		threads: make(map[string]slackThread),
+	}
+}
+
+// get returns the thread of a correlation key.
+func (t *slackThreads) get(key string) (slackThread, bool) {
+	if key == "" {
+		return slackThread{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	thread, found := t.threads[key]
+	return thread, found
+}
+
+// set records the thread of a correlation key.
+func (t *slackThreads) set(key string, thread slackThread) {
+	if key == "" || thread.TS == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, found := t.threads[key]; !found {
+		t.order = append(t.order, key)
+	}
+	t.threads[key] = thread
+	for len(t.order) > t.limit {
+		delete(t.threads, t.order[0])
+		t.order = t.order[1:]
+	}
+}
+
+// slackText renders a message in Slack's mrkdwn format. It is used as the notification
+// text and as the fallback for clients that cannot show blocks.
+func slackText(message Message) string {
+	var lines []string
+
+	// Start with the title, prefixed with an emoji for urgent messages.
+	title := slackEscape(message.Title)
+	if title != "" {
+		title = "*" + title + "*"
+	}
+	if emoji := slackSeverityEmoji[message.Severity]; emoji != "" {
+		title = strings.TrimSpace(emoji + " " + title)
+	}
+	if title != "" {
+		lines = append(lines, title)
+	}
+
+	// Prefer the Markdown body as Slack renders a subset of it.
+	if body := slackBody(message); body != "" {
+		lines = append(lines, body)
+	}
+
+	// Append metadata, links and attachments that can be linked to.
+	for _, key := range message.MetadataKeys() {
+		lines = append(lines, fmt.Sprintf("*%s:* %s", slackEscape(key), slackEscape(message.Metadata[key])))
+	}
+	for _, link := range message.Links {
+		lines = append(lines, fmt.Sprintf("<%s|%s>", link.URL, slackEscape(link.title())))
+	}
+	for _, attachment := range message.Attachments {
+		if attachment.URL != "" {
+			lines = append(lines, fmt.Sprintf(":paperclip: <%s|%s>", attachment.URL, slackEscape(attachment.Filename)))
+		}
+	}
+	if tags := slackTags(message); tags != "" {
+		lines = append(lines, tags)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// slackEscape escapes the characters Slack treats as control sequences.
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
 }
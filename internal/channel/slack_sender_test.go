@@ -1,21 +1,27 @@
 package channel
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestSlack_Send(t *testing.T) {
 	tests := []struct {
-		name       string
-		webhookURL string
-		setup      func(client http.Client)
-		server     *httptest.Server
-		expectErr  error
+		name             string
+		webhookURL       string
+		setup            func(client http.Client)
+		server           *httptest.Server
+		expectErr        error
+		expectPermanent  bool
+		expectRetryAfter time.Duration
 	}{
 		{
 			name: "Sending message to Slack returns no error",
@@ -32,7 +38,26 @@ func TestSlack_Send(t *testing.T) {
 				rw.WriteHeader(http.StatusBadRequest)
 				rw.Write([]byte(`invalid_token`)) // send data to our test client
 			})),
-			expectErr: fmt.Errorf("request failed: invalid_token"),
+			expectErr:       fmt.Errorf("request failed: invalid_token"),
+			expectPermanent: true,
+		},
+		{
+			name: "Sending message to Slack when rate limited returns retry after",
+			server: httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Retry-After", "30")
+				rw.WriteHeader(http.StatusTooManyRequests)
+				rw.Write([]byte(`rate_limited`))
+			})),
+			expectErr:        fmt.Errorf("request failed: rate_limited"),
+			expectRetryAfter: 30 * time.Second,
+		},
+		{
+			name: "Sending message to Slack when unavailable returns retryable error",
+			server: httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusServiceUnavailable)
+				rw.Write([]byte(`service_unavailable`))
+			})),
+			expectErr: fmt.Errorf("request failed: service_unavailable"),
 		},
 	}
 
@@ -40,14 +65,208 @@ func TestSlack_Send(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			s := NewSlack(tt.server.URL, tt.server.Client())
 
-			err := s.Send("Hello")
+			err := s.Send(context.Background(), Message{Body: "Hello"})
 			if tt.expectErr != nil {
 				assert.EqualError(t, err, tt.expectErr.Error())
+				assert.Equal(t, tt.expectPermanent, IsPermanent(err))
+				retryAfter, _ := RetryAfter(err)
+				assert.Equal(t, tt.expectRetryAfter, retryAfter)
 			}
 		})
 	}
 }
 
+func TestSlack_SendWebAPI(t *testing.T) {
+	tests := []struct {
+		name            string
+		response        string
+		expectErr       string
+		expectPermanent bool
+		expectRateLimit bool
+	}{
+		{
+			name:     "Posting message returns no error",
+			response: `{"ok": true, "ts": "1700000000.000100"}`,
+		},
+		{
+			name:            "Posting to unknown channel returns permanent error",
+			response:        `{"ok": false, "error": "channel_not_found"}`,
+			expectErr:       "C123: slack api error: channel_not_found",
+			expectPermanent: true,
+		},
+		{
+			name:            "Posting when rate limited returns rate limited error",
+			response:        `{"ok": false, "error": "ratelimited"}`,
+			expectErr:       "C123: slack api error: ratelimited",
+			expectRateLimit: true,
+		},
+		{
+			name:      "Posting during an outage returns retryable error",
+			response:  `{"ok": false, "error": "service_unavailable"}`,
+			expectErr: "C123: slack api error: service_unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request PostMessageRequest
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "/chat.postMessage", req.URL.Path)
+				assert.Equal(t, "Bearer xoxb-token", req.Header.Get("Authorization"))
+				assert.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+				rw.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			s := NewSlackWithConfig(SlackConfig{BotToken: "xoxb-token", Channel: "C123", APIURL: server.URL}, server.Client())
+			err := s.Send(context.Background(), Message{Title: "Deploy", Body: "Hello", Severity: SeverityWarning})
+			assert.Equal(t, "C123", request.Channel)
+			assert.Equal(t, "#DAA038", request.Attachments[0].Color)
+			if tt.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expectErr)
+			assert.Equal(t, tt.expectPermanent, IsPermanent(err))
+			var rateLimited *RateLimitedError
+			assert.Equal(t, tt.expectRateLimit, errors.As(err, &rateLimited))
+		})
+	}
+}
+
+func TestSlack_SendThreads(t *testing.T) {
+	var requests []PostMessageRequest
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var request PostMessageRequest
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+		requests = append(requests, request)
+		fmt.Fprintf(rw, `{"ok": true, "channel": "C123", "ts": "1700000000.00000%d"}`, len(requests))
+	}))
+	defer server.Close()
+
+	s := NewSlackWithConfig(SlackConfig{BotToken: "xoxb-token", Channel: "C123", APIURL: server.URL}, server.Client())
+
+	// Send an incident, an update to it and an unrelated message
+	assert.NoError(t, s.Send(context.Background(), Message{Body: "Database down", CorrelationKey: "incident-1"}))
+	assert.NoError(t, s.Send(context.Background(), Message{Body: "Database recovered", CorrelationKey: "incident-1"}))
+	assert.NoError(t, s.Send(context.Background(), Message{Body: "Deploy finished"}))
+
+	// Only the update is posted as a reply in the thread of the first message
+	assert.Len(t, requests, 3)
+	assert.Equal(t, "", requests[0].ThreadTS)
+	assert.Equal(t, "1700000000.000001", requests[1].ThreadTS)
+	assert.Equal(t, "", requests[2].ThreadTS)
+}
+
+func TestSlack_SendRecipients(t *testing.T) {
+	var posted []string
+	calls := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		calls[req.URL.Path]++
+		switch req.URL.Path {
+		case "/users.lookupByEmail":
+			if req.URL.Query().Get("email") == "alice@example.com" {
+				rw.Write([]byte(`{"ok": true, "user": {"id": "U111"}}`))
+				return
+			}
+			rw.Write([]byte(`{"ok": false, "error": "users_not_found"}`))
+		case "/conversations.list":
+			// Serve the channels over two pages
+			if req.URL.Query().Get("cursor") == "" {
+				rw.Write([]byte(`{"ok": true, "channels": [{"id": "C111", "name": "general"}], "response_metadata": {"next_cursor": "next"}}`))
+				return
+			}
+			rw.Write([]byte(`{"ok": true, "channels": [{"id": "C222", "name": "alerts"}], "response_metadata": {"next_cursor": ""}}`))
+		case "/chat.postMessage":
+			var request PostMessageRequest
+			assert.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+			posted = append(posted, request.Channel)
+			fmt.Fprintf(rw, `{"ok": true, "channel": %q, "ts": "1700000000.000001"}`, request.Channel)
+		}
+	}))
+	defer server.Close()
+
+	s := NewSlackWithConfig(SlackConfig{BotToken: "xoxb-token", Channel: "C000", APIURL: server.URL}, server.Client())
+
+	// Messages without recipients go to the configured channel
+	assert.NoError(t, s.Send(context.Background(), Message{Body: "Hello"}))
+	assert.Equal(t, []string{"C000"}, posted)
+
+	// Recipients are resolved by ID, channel name and email address
+	posted = nil
+	message := Message{Body: "Hello", Recipients: []string{"C999", "#alerts", "alice@example.com"}}
+	assert.NoError(t, s.Send(context.Background(), message))
+	assert.Equal(t, []string{"C999", "C222", "U111"}, posted)
+
+	// Lookups are served from the cache the second time
+	assert.NoError(t, s.Send(context.Background(), message))
+	assert.Equal(t, 2, calls["/conversations.list"])
+	assert.Equal(t, 1, calls["/users.lookupByEmail"])
+	_ = s.Send(context.Background(), Message{Body: "Hello", Recipients: []string{"#general"}})
+	assert.Equal(t, 2, calls["/conversations.list"])
+
+	// Unknown users fail permanently, unless other recipients may still succeed
+	err := s.Send(context.Background(), Message{Body: "Hello", Recipients: []string{"bob@example.com"}})
+	assert.EqualError(t, err, "bob@example.com: slack api error: users_not_found")
+	assert.True(t, IsPermanent(err))
+	err = s.Send(context.Background(), Message{Body: "Hello", Recipients: []string{"#missing", "bob@example.com"}})
+	assert.True(t, IsPermanent(err))
+}
+
+func TestSlack_SendProgress(t *testing.T) {
+	var posted []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var request PostMessageRequest
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+		posted = append(posted, request.Channel)
+		if request.Channel == "C222" && len(posted) < 3 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(rw, `{"ok": true, "channel": %q, "ts": "1700000000.000001"}`, request.Channel)
+	}))
+	defer server.Close()
+
+	s := NewSlackWithConfig(SlackConfig{BotToken: "xoxb-token", APIURL: server.URL}, server.Client())
+	progress := NewProgress(nil)
+	ctx := WithProgress(context.Background(), progress)
+	message := Message{Body: "Hello", Recipients: []string{"C111", "C222"}}
+
+	// The first attempt fails for one recipient only
+	err := s.Send(ctx, message)
+	assert.Error(t, err)
+	assert.False(t, IsPermanent(err))
+	assert.Equal(t, []string{"C111"}, progress.Targets())
+
+	// The retry skips the recipient that already got the message
+	assert.NoError(t, s.Send(ctx, message))
+	assert.Equal(t, []string{"C111", "C222", "C222"}, posted)
+	assert.Equal(t, []string{"C111", "C222"}, progress.Targets())
+}
+
+func TestSlack_SendRecipientsWithWebhook(t *testing.T) {
+	s := NewSlack("http://localhost", http.DefaultClient)
+
+	// Webhooks are bound to a single channel
+	err := s.Send(context.Background(), Message{Body: "Hello", Recipients: []string{"C123"}})
+	assert.ErrorIs(t, err, ErrRecipientsNotSupported)
+	assert.True(t, IsPermanent(err))
+}
+
+func TestSlackThreads_Limit(t *testing.T) {
+	threads := newSlackThreads(2)
+	threads.set("a", slackThread{Channel: "C1", TS: "1"})
+	threads.set("b", slackThread{Channel: "C1", TS: "2"})
+	threads.set("c", slackThread{Channel: "C1", TS: "3"})
+
+	// The oldest key is forgotten
+	_, found := threads.get("a")
+	assert.False(t, found)
+	thread, found := threads.get("c")
+	assert.True(t, found)
+	assert.Equal(t, "3", thread.TS)
+}
+
 type MockClient struct {
 	DoFunc func(req *http.Request) (*http.Response, error)
 }
@@ -55,3 +274,42 @@ type MockClient struct {
 func (m *MockClient) Do(req *http.Request) (*http.Response, error) {
 	return m.DoFunc(req)
 }
+
+func TestSlackText(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  Message
+		expected string
+	}{
+		{
+			name:     "Plain body is escaped",
+			message:  Message{Body: "a < b"},
+			expected: "a &lt; b",
+		},
+		{
+			name:     "Markdown body is preferred",
+			message:  Message{Title: "Deploy", Body: "plain", Markdown: "*done*"},
+			expected: "*Deploy*\n*done*",
+		},
+		{
+			name: "Severity, metadata, links and tags are rendered",
+			message: Message{
+				Title:       "Disk almost full",
+				Body:        "Only 5% left",
+				Severity:    SeverityCritical,
+				Tags:        []string{"prod"},
+				Metadata:    map[string]string{"region": "eu", "host": "db-1"},
+				Links:       []Link{{Title: "Dashboard", URL: "https://example.com/d"}},
+				Attachments: []Attachment{{Filename: "graph.png", URL: "https://example.com/g.png"}},
+			},
+			expected: ":rotating_light: *Disk almost full*\nOnly 5% left\n*host:* db-1\n*region:* eu\n" +
+				"<https://example.com/d|Dashboard>\n:paperclip: <https://example.com/g.png|graph.png>\n`prod`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, slackText(tt.message))
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package channel
+
+import "strings"
+
+// SMSEncoding is the character encoding of an SMS.
+type SMSEncoding string
+
+// Encodings used for SMS. GSM-7 is used when all characters are in the GSM 03.38
+// alphabet and UCS-2 otherwise, which fits fewer characters in a segment.
+const (
+	EncodingGSM7 SMSEncoding = "GSM-7"
+	EncodingUCS2 SMSEncoding = "UCS-2"
+)
+
+// Segment sizes in septets for GSM-7 and UTF-16 code units for UCS-2. Messages of more
+// than one segment lose some space in each segment to the concatenation header.
+const (
+	gsm7SingleSegment = 160
+	gsm7MultiSegment  = 153
+	ucs2SingleSegment = 70
+	ucs2MultiSegment  = 67
+)
+
+// gsm7Basic is the GSM 03.38 basic character set, taking one septet each.
+const gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// gsm7Extension is the GSM 03.38 extension table, taking two septets each.
+const gsm7Extension = "\f^{}\\[~]|€"
+
+// SMSSegments returns the encoding of a text and the number of segments it is sent in.
+func SMSSegments(text string) (SMSEncoding, int) {
+	encoding := smsEncoding(text)
+	counter := newSMSCounter(encoding)
+	for _, r := range text {
+		counter.add(r)
+	}
+	return encoding, counter.segments()
+}
+
+// smsEncoding returns GSM-7 if all characters of the text can be encoded in it.
+func smsEncoding(text string) SMSEncoding {
+	for _, r := range text {
+		if !strings.ContainsRune(gsm7Basic, r) && !strings.ContainsRune(gsm7Extension, r) {
+			return EncodingUCS2
+		}
+	}
+	return EncodingGSM7
+}
+
+// smsSplit splits a text into parts of at most maxSegments segments each, preferring to
+// break at whitespace. All parts use the encoding of the whole text. Parts are trimmed,
+// and those left empty are dropped.
+func smsSplit(text string, maxSegments int) []string {
+	encoding := smsEncoding(text)
+	var parts []string
+	runes := []rune(text)
+	for len(runes) > 0 {
+		// Take as many characters as fit into the segments.
+		counter := newSMSCounter(encoding)
+		n := 0
+		for n < len(runes) {
+			counter.add(runes[n])
+			if counter.segments() > maxSegments {
+				break
+			}
+			n++
+		}
+
+		// Break at the last whitespace in the second half of a part that does not end the text.
+		if n < len(runes) {
+			for i := n - 1; i > n/2; i-- {
+				if runes[i] == ' ' || runes[i] == '\n' {
+					n = i + 1
+					break
+				}
+			}
+		}
+		if part := strings.TrimSpace(string(runes[:n])); part != "" {
+			parts = append(parts, part)
+		}
+		runes = runes[n:]
+	}
+	return parts
+}
+
+// smsCounter counts the segments of a text character by character. Characters never
+// span two segments, so a segment may end with unused space.
+type smsCounter struct {
+	encoding      SMSEncoding
+	single, multi int // Size of a single segment and of each segment of a concatenated message.
+	total         int // Size of all characters.
+	count         int // Number of segments when concatenated.
+	used          int // Size used in the last segment when concatenated.
+}
+
+// newSMSCounter creates a new smsCounter for an encoding.
+func newSMSCounter(encoding SMSEncoding) *smsCounter {
+	if encoding == EncodingGSM7 {
+		return &smsCounter{encoding: encoding, single: gsm7SingleSegment, multi: gsm7MultiSegment}
+	}
+	return &smsCounter{encoding: encoding, single: ucs2SingleSegment, multi: ucs2MultiSegment}
+}
+
+// add counts a character.
+func (c *smsCounter) add(r rune) {
+	size := 1
+	if c.encoding == EncodingGSM7 && strings.ContainsRune(gsm7Extension, r) {
+		size = 2 // Escape and character.
+	} else if c.encoding == EncodingUCS2 && r > 0xFFFF {
+		size = 2 // Surrogate pair.
+	}
+	c.total += size
+	if c.count == 0 || c.used+size > c.multi {
+		c.count++
+		c.used = 0
+	}
+	c.used += size
+}
+
+// segments returns the number of segments of the characters counted so far.
+func (c *smsCounter) segments() int {
+	if c.total <= c.single {
+		if c.total == 0 {
+			return 0
+		}
+		return 1
+	}
+	return c.count
+}
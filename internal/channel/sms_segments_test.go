@@ -0,0 +1,96 @@
+package channel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSMSSegments(t *testing.T) {
+	tests := []struct {
+		name             string
+		text             string
+		expectedEncoding SMSEncoding
+		expectedSegments int
+	}{
+		{name: "Empty text", text: "", expectedEncoding: EncodingGSM7, expectedSegments: 0},
+		{name: "Short text", text: "Disk full on db-1 @ 95%", expectedEncoding: EncodingGSM7, expectedSegments: 1},
+		{name: "Full single GSM-7 segment", text: strings.Repeat("a", 160), expectedEncoding: EncodingGSM7, expectedSegments: 1},
+		{name: "Two GSM-7 segments", text: strings.Repeat("a", 161), expectedEncoding: EncodingGSM7, expectedSegments: 2},
+		{name: "Full two GSM-7 segments", text: strings.Repeat("a", 306), expectedEncoding: EncodingGSM7, expectedSegments: 2},
+		{name: "Three GSM-7 segments", text: strings.Repeat("a", 307), expectedEncoding: EncodingGSM7, expectedSegments: 3},
+		{name: "Extension characters take two septets", text: strings.Repeat("€", 80), expectedEncoding: EncodingGSM7, expectedSegments: 1},
+		{name: "Extension characters do not span segments", text: strings.Repeat("€", 81), expectedEncoding: EncodingGSM7, expectedSegments: 2},
+		{name: "Accented GSM-7 characters", text: "Grüße àè", expectedEncoding: EncodingGSM7, expectedSegments: 1},
+		{name: "Characters outside GSM-7", text: "ç", expectedEncoding: EncodingUCS2, expectedSegments: 1},
+		{name: "Full single UCS-2 segment", text: strings.Repeat("ж", 70), expectedEncoding: EncodingUCS2, expectedSegments: 1},
+		{name: "Two UCS-2 segments", text: strings.Repeat("ж", 71), expectedEncoding: EncodingUCS2, expectedSegments: 2},
+		{name: "Surrogate pairs take two code units", text: strings.Repeat("😀", 35), expectedEncoding: EncodingUCS2, expectedSegments: 1},
+		{name: "Surrogate pairs do not span segments", text: strings.Repeat("😀", 36), expectedEncoding: EncodingUCS2, expectedSegments: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoding, segments := SMSSegments(tt.text)
+			assert.Equal(t, tt.expectedEncoding, encoding)
+			assert.Equal(t, tt.expectedSegments, segments)
+		})
+	}
+}
+
+func TestSMSSplit(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		maxSegments int
+		expected    []string
+	}{
+		{
+			name:        "Short text is not split",
+			text:        "Hello",
+			maxSegments: 1,
+			expected:    []string{"Hello"},
+		},
+		{
+			name:        "Long text is split into full parts",
+			text:        strings.Repeat("a", 400),
+			maxSegments: 2,
+			expected:    []string{strings.Repeat("a", 306), strings.Repeat("a", 94)},
+		},
+		{
+			name:        "Long text is split at whitespace",
+			text:        strings.Repeat("word ", 40),
+			maxSegments: 1,
+			expected:    []string{strings.TrimSpace(strings.Repeat("word ", 32)), strings.TrimSpace(strings.Repeat("word ", 8))},
+		},
+		{
+			name:        "Parts left empty by whitespace are dropped",
+			text:        "a" + strings.Repeat(" ", 400) + "b",
+			maxSegments: 1,
+			expected:    []string{"a", "b"},
+		},
+		{
+			name:        "Whitespace only text has no parts",
+			text:        strings.Repeat(" \n", 200),
+			maxSegments: 1,
+		},
+		{
+			name:        "Text with characters outside GSM-7 is split into UCS-2 parts",
+			text:        "ç" + strings.Repeat("a", 99),
+			maxSegments: 1,
+			expected:    []string{"ç" + strings.Repeat("a", 69), strings.Repeat("a", 30)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := smsSplit(tt.text, tt.maxSegments)
+			assert.Equal(t, tt.expected, parts)
+			for _, part := range parts {
+				_, segments := SMSSegments(part)
+				assert.LessOrEqual(t, segments, tt.maxSegments)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package channel
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TwilioAPIURL is the base URL of the Twilio REST API.
+const TwilioAPIURL = "https://api.twilio.com"
+
+// smsMaxSegments is the number of segments Twilio recommends to send in one message.
+const smsMaxSegments = 10
+
+var (
+	ErrInvalidPhoneNumber = errors.New("invalid phone number")
+	ErrInvalidSMSMaxParts = errors.New("sms max parts must be at least 1")
+)
+
+// e164Pattern matches phone numbers in E.164 format, such as +14155550100.
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// phoneNumberFormatting are the characters commonly used to format phone numbers.
+var phoneNumberFormatting = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+
+// twilioFinalStatuses are the message statuses that do not change anymore.
+var twilioFinalStatuses = map[string]bool{
+	"delivered":   true,
+	"undelivered": true,
+	"failed":      true,
+	"canceled":    true,
+	"read":        true,
+}
+
+// SMSConfig holds the settings of the SMS channel.
+type SMSConfig struct {
+	AccountSID string   // Twilio account SID.
+	AuthToken  string   // Twilio auth token, also used to verify status callbacks.
+	From       string   // Sender phone number, or the SID of a messaging service starting with "MG".
+	To         []string // Default recipients for messages without recipients.
+	BaseURL    string   // Base URL of a Twilio compatible API, defaults to TwilioAPIURL.
+	MaxParts   int      // Number of messages a long text is split into before it is truncated, defaults to 3.
+
+	// StatusCallbackURL is the public URL of the service's callback endpoint for this
+	// channel, such as https://notify.example.com/callbacks/SMS. Delivery statuses are
+	// only reported when it is set.
+	StatusCallbackURL string
+	Name              string // Channel name, defaults to SMS.
+}
+
+// SMS represents an SMS channel sending text messages through the Twilio Messages API.
+type SMS struct {
+	config SMSConfig
+	name   string       // The name of the sender.
+	client *http.Client // HTTP client for making requests.
+}
+
+// twilioMessage is the response of the Messages API.
+type twilioMessage struct {
+	SID    string `json:"sid"`
+	Status string `json:"status"`
+}
+
+// NewSMS creates a new SMS channel instance.
+func NewSMS(config SMSConfig, client *http.Client) (*SMS, error) {
+	if config.BaseURL == "" {
+		config.BaseURL = TwilioAPIURL
+	}
+	if config.MaxParts == 0 {
+		config.MaxParts = 3
+	}
+	if config.MaxParts < 1 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSMSMaxParts, config.MaxParts)
+	}
+	return &SMS{
+		config: config,
+		name:   senderName(config.Name, "SMS"),
+		client: client,
+	}, nil
+}
+
+// Send sends a message as text to each of its recipients, or the configured numbers
+// when it has none. Texts longer than ten segments are split into several messages.
+func (s *SMS) Send(ctx context.Context, message Message) error {
+	to, err := s.recipients(message)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	// Split long texts, truncating what does not fit into the allowed number of messages.
+	// The cut is marked with dots, which keep a GSM-7 text in GSM-7. They replace the end
+	// of the last part, unless it is short enough to have room for them.
+	parts := smsSplit(smsText(message), smsMaxSegments)
+	if len(parts) == 0 {
+		return Permanent(ErrEmptyMessage)
+	}
+	if len(parts) > s.config.MaxParts {
+		parts = parts[:s.config.MaxParts]
+		last := []rune(parts[len(parts)-1])
+		if len(last) > 3 {
+			last = last[:len(last)-3]
+		}
+		parts[len(parts)-1] = string(last) + "..."
+	}
+
+	// Retries skip the parts each recipient already got, as every text is charged.
+	var errs []error
+	for _, recipient := range to {
+		for i, part := range parts {
+			err := sendOnce(ctx, fmt.Sprintf("%s#%d", recipient, i+1), func() error {
+				return s.sendTo(ctx, recipient, part)
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", recipient, err))
+				break
+			}
+		}
+	}
+	if err := JoinErrors(errs...); err != nil {
+		return err
+	}
+	log.Printf("sms message sent: %s", message)
+
+	return nil
+}
+
+// GetName returns the name of the SMS sender.
+func (s *SMS) GetName() string {
+	return s.name
+}
+
+// HandleCallback verifies a status callback signed by Twilio and returns the delivery report.
+func (s *SMS) HandleCallback(req *http.Request) (DeliveryReport, error) {
+	if err := req.ParseForm(); err != nil {
+		return DeliveryReport{}, fmt.Errorf("%w: %v", ErrInvalidCallback, err)
+	}
+
+	// Twilio signs the URL it was given, which is the configured URL with our query parameters.
+	callbackURL, err := url.Parse(s.config.StatusCallbackURL)
+	if err != nil || s.config.StatusCallbackURL == "" {
+		return DeliveryReport{}, fmt.Errorf("%w: no status callback url configured", ErrInvalidCallback)
+	}
+	callbackURL.RawQuery = req.URL.RawQuery
+	if !validTwilioSignature(s.config.AuthToken, callbackURL.String(), req.PostForm, req.Header.Get("X-Twilio-Signature")) {
+		return DeliveryReport{}, ErrInvalidCallbackSignature
+	}
+
+	report := DeliveryReport{
+		NotificationID: req.URL.Query().Get("notification"),
+		MessageID:      req.PostForm.Get("MessageSid"),
+		Recipient:      req.PostForm.Get("To"),
+		Status:         req.PostForm.Get("MessageStatus"),
+	}
+	if report.NotificationID == "" || report.MessageID == "" || report.Status == "" {
+		return DeliveryReport{}, fmt.Errorf("%w: missing notification, MessageSid or MessageStatus", ErrInvalidCallback)
+	}
+	report.Final = twilioFinalStatuses[report.Status]
+	if code := req.PostForm.Get("ErrorCode"); code != "" {
+		report.Error = "twilio error " + code
+	}
+	return report, nil
+}
+
+// recipients returns the phone numbers to send a message to in E.164 format.
+func (s *SMS) recipients(message Message) ([]string, error) {
+	to := message.Recipients
+	if len(to) == 0 {
+		to = s.config.To
+	}
+	if len(to) == 0 {
+		return nil, fmt.Errorf("%w: no recipients", ErrInvalidPhoneNumber)
+	}
+
+	numbers := make([]string, len(to))
+	for i, recipient := range to {
+		number := phoneNumberFormatting.Replace(strings.TrimSpace(recipient))
+		if !e164Pattern.MatchString(number) {
+			return nil, fmt.Errorf("%w: %q is not in E.164 format", ErrInvalidPhoneNumber, recipient)
+		}
+		numbers[i] = number
+	}
+	return numbers, nil
+}
+
+// sendTo sends a single text message with the Messages API.
+func (s *SMS) sendTo(ctx context.Context, to, text string) error {
+	form := url.Values{
+		"To":   {to},
+		"Body": {text},
+	}
+	if strings.HasPrefix(s.config.From, "MG") {
+		form.Set("MessagingServiceSid", s.config.From)
+	} else {
+		form.Set("From", s.config.From)
+	}
+	if callbackURL := s.statusCallbackURL(ctx); callbackURL != "" {
+		form.Set("StatusCallback", callbackURL)
+	}
+
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", strings.TrimSuffix(s.config.BaseURL, "/"), url.PathEscape(s.config.AccountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(s.config.AccountSID, s.config.AuthToken)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return Retryable(err)
+	}
+	defer resp.Body.Close()
+
+	// Classify failures so the notifier knows whether to retry.
+	if err := CheckResponse(resp); err != nil {
+		return err
+	}
+
+	// The message was accepted, so a response that cannot be decoded is only logged
+	// rather than retried, which would send the message twice.
+	var result twilioMessage
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		log.Printf("error decoding twilio response: %v", err)
+	}
+	encoding, segments := SMSSegments(text)
+	log.Printf("sms %s to %s %s: %d %s segments", result.SID, to, result.Status, segments, encoding)
+
+	return nil
+}
+
+// statusCallbackURL returns the callback URL for the notification being sent, or "" if
+// delivery statuses are not reported.
+func (s *SMS) statusCallbackURL(ctx context.Context) string {
+	id := NotificationID(ctx)
+	if s.config.StatusCallbackURL == "" || id == "" {
+		return ""
+	}
+	callbackURL, err := url.Parse(s.config.StatusCallbackURL)
+	if err != nil {
+		log.Printf("invalid sms status callback url: %v", err)
+		return ""
+	}
+	query := callbackURL.Query()
+	query.Set("notification", id)
+	callbackURL.RawQuery = query.Encode()
+	return callbackURL.String()
+}
+
+// smsText renders a message as plain text, prefixing urgent messages with their severity.
+func smsText(message Message) string {
+	text := message.Text()
+	if message.Title != "" && text != message.Title {
+		text = message.Title + "\n" + text
+	}
+	switch message.Severity {
+	case SeverityError, SeverityCritical:
+		text = "[" + strings.ToUpper(string(message.Severity)) + "] " + text
+	}
+	return text
+}
+
+// validTwilioSignature checks the signature of a Twilio request in constant time.
+func validTwilioSignature(authToken, requestURL string, form url.Values, signature string) bool {
+	return hmac.Equal([]byte(twilioSignature(authToken, requestURL, form)), []byte(signature))
+}
+
+// twilioSignature returns the signature of a Twilio request: the base64 encoded
+// HMAC-SHA1 of the URL followed by the sorted form parameters and their values.
+func twilioSignature(authToken, requestURL string, form url.Values) string {
+	keys := make([]string, 0, len(form))
+	for key := range form {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := hmac.New(sha1.New, []byte(authToken))
+	h.Write([]byte(requestURL))
+	for _, key := range keys {
+		for _, value := range form[key] {
+			h.Write([]byte(key + value))
+		}
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,292 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMS_Send(t *testing.T) {
+	tests := []struct {
+		name            string
+		config          SMSConfig
+		message         Message
+		status          int
+		response        string
+		expectedForms   []url.Values
+		expectErr       string
+		expectPermanent bool
+		expectRateLimit bool
+	}{
+		{
+			name:     "Sending to the configured numbers",
+			config:   SMSConfig{From: "+15005550006", To: []string{"+14155550100", "+14155550101"}},
+			message:  Message{Title: "Disk full", Body: "Only 5% left", Severity: SeverityCritical},
+			status:   http.StatusCreated,
+			response: `{"sid": "SM123", "status": "queued"}`,
+			expectedForms: []url.Values{
+				{"From": {"+15005550006"}, "To": {"+14155550100"}, "Body": {"[CRITICAL] Disk full\nOnly 5% left"}},
+				{"From": {"+15005550006"}, "To": {"+14155550101"}, "Body": {"[CRITICAL] Disk full\nOnly 5% left"}},
+			},
+		},
+		{
+			name:     "Sending to formatted recipients through a messaging service",
+			config:   SMSConfig{From: "MG123", To: []string{"+14155550100"}},
+			message:  Message{Body: "Hello", Recipients: []string{"+44 (20) 7946-0018"}},
+			status:   http.StatusCreated,
+			response: `{"sid": "SM123", "status": "accepted"}`,
+			expectedForms: []url.Values{
+				{"MessagingServiceSid": {"MG123"}, "To": {"+442079460018"}, "Body": {"Hello"}},
+			},
+		},
+		{
+			name:     "Long texts are split into several messages",
+			config:   SMSConfig{From: "+15005550006", To: []string{"+14155550100"}},
+			message:  Message{Body: strings.Repeat("a", 1600)},
+			status:   http.StatusCreated,
+			response: `{"sid": "SM123", "status": "queued"}`,
+			expectedForms: []url.Values{
+				{"From": {"+15005550006"}, "To": {"+14155550100"}, "Body": {strings.Repeat("a", 1530)}},
+				{"From": {"+15005550006"}, "To": {"+14155550100"}, "Body": {strings.Repeat("a", 70)}},
+			},
+		},
+		{
+			name:     "Texts longer than the allowed parts are truncated",
+			config:   SMSConfig{From: "+15005550006", To: []string{"+14155550100"}, MaxParts: 1},
+			message:  Message{Body: strings.Repeat("a", 1600)},
+			status:   http.StatusCreated,
+			response: `{"sid": "SM123", "status": "queued"}`,
+			expectedForms: []url.Values{
+				{"From": {"+15005550006"}, "To": {"+14155550100"}, "Body": {strings.Repeat("a", 1527) + "..."}},
+			},
+		},
+		{
+			name:     "Short last parts keep their text when truncated",
+			config:   SMSConfig{From: "+15005550006", To: []string{"+14155550100"}, MaxParts: 1},
+			message:  Message{Body: "ok" + strings.Repeat(" ", 1600) + "done"},
+			status:   http.StatusCreated,
+			response: `{"sid": "SM123", "status": "queued"}`,
+			expectedForms: []url.Values{
+				{"From": {"+15005550006"}, "To": {"+14155550100"}, "Body": {"ok..."}},
+			},
+		},
+		{
+			name:            "Whitespace only texts return permanent error",
+			config:          SMSConfig{From: "+15005550006", To: []string{"+14155550100"}},
+			message:         Message{Body: strings.Repeat(" ", 1600)},
+			expectErr:       "message has no content",
+			expectPermanent: true,
+		},
+		{
+			name:            "Invalid numbers return permanent error",
+			config:          SMSConfig{From: "+15005550006"},
+			message:         Message{Body: "Hello", Recipients: []string{"0041 79 123"}},
+			expectErr:       `invalid phone number: "0041 79 123" is not in E.164 format`,
+			expectPermanent: true,
+		},
+		{
+			name:            "Missing recipients return permanent error",
+			config:          SMSConfig{From: "+15005550006"},
+			message:         Message{Body: "Hello"},
+			expectErr:       "invalid phone number: no recipients",
+			expectPermanent: true,
+		},
+		{
+			name:     "Rejected messages return permanent error",
+			config:   SMSConfig{From: "+15005550006", To: []string{"+14155550100"}},
+			message:  Message{Body: "Hello"},
+			status:   http.StatusBadRequest,
+			response: `{"code": 21610, "message": "Attempt to send to unsubscribed recipient"}`,
+			expectedForms: []url.Values{
+				{"From": {"+15005550006"}, "To": {"+14155550100"}, "Body": {"Hello"}},
+			},
+			expectErr:       `+14155550100: request failed: {"code": 21610, "message": "Attempt to send to unsubscribed recipient"}`,
+			expectPermanent: true,
+		},
+		{
+			name:     "Rate limited messages return rate limited error",
+			config:   SMSConfig{From: "+15005550006", To: []string{"+14155550100"}},
+			message:  Message{Body: "Hello"},
+			status:   http.StatusTooManyRequests,
+			response: `{"code": 20429, "message": "Too Many Requests"}`,
+			expectedForms: []url.Values{
+				{"From": {"+15005550006"}, "To": {"+14155550100"}, "Body": {"Hello"}},
+			},
+			expectErr:       `+14155550100: request failed: {"code": 20429, "message": "Too Many Requests"}`,
+			expectRateLimit: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var forms []url.Values
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "/2010-04-01/Accounts/AC123/Messages.json", req.URL.Path)
+				user, password, _ := req.BasicAuth()
+				assert.Equal(t, "AC123", user)
+				assert.Equal(t, "token", password)
+				assert.NoError(t, req.ParseForm())
+				forms = append(forms, req.PostForm)
+				rw.WriteHeader(tt.status)
+				rw.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			tt.config.AccountSID, tt.config.AuthToken, tt.config.BaseURL = "AC123", "token", server.URL
+			s, err := NewSMS(tt.config, server.Client())
+			require.NoError(t, err)
+			err = s.Send(context.Background(), tt.message)
+			assert.Equal(t, tt.expectedForms, forms)
+			if tt.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expectErr)
+			assert.Equal(t, tt.expectPermanent, IsPermanent(err))
+			var rateLimited *RateLimitedError
+			assert.Equal(t, tt.expectRateLimit, errors.As(err, &rateLimited))
+		})
+	}
+}
+
+func TestSMS_SendProgress(t *testing.T) {
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.NoError(t, req.ParseForm())
+		sent = append(sent, req.PostForm.Get("To"))
+
+		// The second part to the second number fails once
+		if len(sent) == 4 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.WriteHeader(http.StatusCreated)
+		rw.Write([]byte(`{"sid": "SM123", "status": "queued"}`))
+	}))
+	defer server.Close()
+
+	s, err := NewSMS(SMSConfig{AccountSID: "AC123", From: "+15005550006", To: []string{"+14155550100", "+14155550101"}, BaseURL: server.URL}, server.Client())
+	require.NoError(t, err)
+	progress := NewProgress(nil)
+	ctx := WithProgress(context.Background(), progress)
+	message := Message{Body: strings.Repeat("a", 1600)}
+
+	err = s.Send(ctx, message)
+	assert.Error(t, err)
+	assert.False(t, IsPermanent(err))
+	assert.Equal(t, []string{"+14155550100#1", "+14155550100#2", "+14155550101#1"}, progress.Targets())
+
+	// The retry only sends the part that failed
+	assert.NoError(t, s.Send(ctx, message))
+	assert.Equal(t, []string{"+14155550100", "+14155550100", "+14155550101", "+14155550101", "+14155550101"}, sent)
+}
+
+func TestSMS_SendStatusCallback(t *testing.T) {
+	var callback string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		callback = req.FormValue("StatusCallback")
+		rw.WriteHeader(http.StatusCreated)
+		rw.Write([]byte(`{"sid": "SM123", "status": "queued"}`))
+	}))
+	defer server.Close()
+
+	s, err := NewSMS(SMSConfig{
+		From:              "+15005550006",
+		To:                []string{"+14155550100"},
+		BaseURL:           server.URL,
+		StatusCallbackURL: "https://notify.example.com/callbacks/SMS",
+	}, server.Client())
+	require.NoError(t, err)
+
+	// The callback URL refers to the notification being sent
+	ctx := WithNotificationID(context.Background(), "n-1")
+	assert.NoError(t, s.Send(ctx, Message{Body: "Hello"}))
+	assert.Equal(t, "https://notify.example.com/callbacks/SMS?notification=n-1", callback)
+}
+
+func TestSMS_HandleCallback(t *testing.T) {
+	form := url.Values{
+		"AccountSid":    {"AC123"},
+		"MessageSid":    {"SM123"},
+		"MessageStatus": {"delivered"},
+		"To":            {"+14155550100"},
+	}
+	tests := []struct {
+		name      string
+		form      url.Values
+		signature string
+		expected  DeliveryReport
+		expectErr error
+	}{
+		{
+			name:      "Signed callback returns the report",
+			form:      form,
+			signature: "ya0UrqwxpnHRZwI3i7avSEwyvWg=",
+			expected: DeliveryReport{
+				NotificationID: "n-1",
+				MessageID:      "SM123",
+				Recipient:      "+14155550100",
+				Status:         "delivered",
+				Final:          true,
+			},
+		},
+		{
+			name:      "Missing signature is rejected",
+			form:      form,
+			expectErr: ErrInvalidCallbackSignature,
+		},
+		{
+			name: "Tampered callback is rejected",
+			form: url.Values{
+				"AccountSid":    {"AC123"},
+				"MessageSid":    {"SM123"},
+				"MessageStatus": {"failed"},
+				"To":            {"+14155550100"},
+			},
+			signature: "ya0UrqwxpnHRZwI3i7avSEwyvWg=",
+			expectErr: ErrInvalidCallbackSignature,
+		},
+	}
+
+	s, err := NewSMS(SMSConfig{AuthToken: "token", StatusCallbackURL: "https://notify.example.com/callbacks/SMS"}, http.DefaultClient)
+	require.NoError(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The request reaches the service through a proxy under another host name
+			req := httptest.NewRequest(http.MethodPost, "http://internal:8087/callbacks/SMS?notification=n-1", strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("X-Twilio-Signature", tt.signature)
+
+			report, err := s.HandleCallback(req)
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, report)
+		})
+	}
+}
+
+func TestSMS_HandleCallbackFailed(t *testing.T) {
+	form := url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"undelivered"}, "ErrorCode": {"30003"}}
+	s, err := NewSMS(SMSConfig{AuthToken: "token", StatusCallbackURL: "https://notify.example.com/callbacks/SMS"}, http.DefaultClient)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/callbacks/SMS?notification=n-1", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Twilio-Signature", twilioSignature("token", "https://notify.example.com/callbacks/SMS?notification=n-1", form))
+
+	// Failed deliveries carry the Twilio error code
+	report, err := s.HandleCallback(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "undelivered", report.Status)
+	assert.True(t, report.Final)
+	assert.Equal(t, "twilio error 30003", report.Error)
+}
@@ -0,0 +1,310 @@
+package channel
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Networks supported by the Syslog channel.
+const (
+	SyslogUDP  = "udp"
+	SyslogTCP  = "tcp"
+	SyslogTLS  = "tls"
+	SyslogUnix = "unix" // The local syslog socket.
+)
+
+const (
+	// syslogMaxDatagram is the size of the largest message sent over UDP or the local
+	// socket. Servers such as rsyslog accept 8 KiB by default.
+	syslogMaxDatagram = 8192
+
+	// syslogEnterpriseID is the enterprise number of the structured data, the one
+	// reserved for documentation by RFC 5612.
+	syslogEnterpriseID = "32473"
+
+	syslogDialTimeout = 10 * time.Second
+)
+
+// syslogSockets are the paths of the local syslog socket on common systems.
+var syslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// syslogFacilities are the facility codes by name.
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogSeverities are the syslog severities of message severities.
+var syslogSeverities = map[Severity]int{
+	SeverityCritical: 2,
+	SeverityError:    3,
+	SeverityWarning:  4,
+	SeverityInfo:     6,
+}
+
+var (
+	ErrInvalidSyslogNetwork  = errors.New("invalid syslog network")
+	ErrInvalidSyslogFacility = errors.New("invalid syslog facility")
+	ErrNoSyslogSocket        = errors.New("no local syslog socket found")
+)
+
+// SyslogConfig holds the settings of the Syslog channel.
+type SyslogConfig struct {
+	Network  string      // One of udp, tcp, tls or unix, defaults to unix.
+	Address  string      // Address of the server, or path of the local socket.
+	Facility string      // Facility of messages, such as local0, defaults to user.
+	AppName  string      // Application name of messages, defaults to notification-service.
+	Hostname string      // Hostname of messages, defaults to the hostname of the system.
+	TLS      *tls.Config // Settings of TLS connections, defaults to the system roots.
+	Name     string      // Channel name, defaults to Syslog.
+}
+
+// Syslog represents a channel sending notifications as RFC 5424 messages to a syslog
+// server or the local syslog daemon. TCP and TLS use octet counting framing (RFC 6587,
+// RFC 5425), so messages may span several lines. Recipients are ignored.
+type Syslog struct {
+	config   SyslogConfig
+	name     string // The name of the sender.
+	facility int
+	hostname string
+	dial     func(ctx context.Context) (net.Conn, error)
+	now      func() time.Time // Clock used for the message timestamp.
+
+	// Connection opened on the first send and reopened after a failed write.
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslog creates a new Syslog channel instance. The connection is opened on the first send.
+func NewSyslog(config SyslogConfig) (*Syslog, error) {
+	if config.Network == "" {
+		config.Network = SyslogUnix
+	}
+	if config.Facility == "" {
+		config.Facility = "user"
+	}
+	if config.AppName == "" {
+		config.AppName = "notification-service"
+	}
+	facility, ok := syslogFacilities[config.Facility]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSyslogFacility, config.Facility)
+	}
+	s := &Syslog{
+		config:   config,
+		name:     senderName(config.Name, "Syslog"),
+		facility: facility,
+		hostname: config.Hostname,
+		now:      time.Now,
+	}
+	if s.hostname == "" {
+		s.hostname, _ = os.Hostname()
+	}
+
+	dialer := &net.Dialer{Timeout: syslogDialTimeout}
+	switch config.Network {
+	case SyslogUDP, SyslogTCP:
+		s.dial = func(ctx context.Context) (net.Conn, error) {
+			return dialer.DialContext(ctx, config.Network, config.Address)
+		}
+	case SyslogTLS:
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: config.TLS}
+		s.dial = func(ctx context.Context) (net.Conn, error) {
+			return tlsDialer.DialContext(ctx, "tcp", config.Address)
+		}
+	case SyslogUnix:
+		s.dial = func(ctx context.Context) (net.Conn, error) {
+			return dialSyslogSocket(ctx, dialer, config.Address)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSyslogNetwork, config.Network)
+	}
+	return s, nil
+}
+
+// Send writes a message to syslog.
+func (s *Syslog) Send(ctx context.Context, message Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		conn, err := s.dial(ctx)
+		if err != nil {
+			return syslogError(err)
+		}
+		s.conn = conn
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = s.conn.SetWriteDeadline(deadline)
+	}
+	if _, err := s.conn.Write(s.frame(s.format(ctx, message))); err != nil {
+		// The connection may be broken, so the next send opens a new one.
+		s.conn.Close()
+		s.conn = nil
+		return syslogError(err)
+	}
+	log.Printf("syslog message sent: %s", message)
+
+	return nil
+}
+
+// GetName returns the name of the Syslog sender.
+func (s *Syslog) GetName() string {
+	return s.name
+}
+
+// Close closes the connection.
+func (s *Syslog) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// format returns a message in the RFC 5424 format, such as
+//
+//	<131>1 2024-05-01T12:00:00.000000Z host notification-service 42 notification [notification@32473 id="n-1" severity="error"] Disk full: Disk of db-1 is full
+func (s *Syslog) format(ctx context.Context, message Message) string {
+	severity := message.SeverityOrDefault()
+	header := fmt.Sprintf("<%d>1 %s %s %s %d notification ",
+		s.facility*8+syslogSeverities[severity],
+		s.now().UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogHeaderField(s.hostname, 255),
+		syslogHeaderField(s.config.AppName, 48),
+		os.Getpid(),
+	)
+
+	// Structured data lets pipelines filter without parsing the text.
+	var data strings.Builder
+	data.WriteString("[notification@" + syslogEnterpriseID)
+	params := []string{"id", NotificationID(ctx), "severity", string(severity), "correlationKey", message.CorrelationKey}
+	if len(message.Tags) > 0 {
+		params = append(params, "tags", strings.Join(message.Tags, ","))
+	}
+	for i := 0; i < len(params); i += 2 {
+		if params[i+1] != "" {
+			fmt.Fprintf(&data, " %s=\"%s\"", params[i], syslogParamEscaper.Replace(params[i+1]))
+		}
+	}
+	data.WriteString("]")
+	if len(message.Metadata) > 0 {
+		data.WriteString("[metadata@" + syslogEnterpriseID)
+		for _, key := range message.MetadataKeys() {
+			fmt.Fprintf(&data, " %s=\"%s\"", syslogParamName(key), syslogParamEscaper.Replace(message.Metadata[key]))
+		}
+		data.WriteString("]")
+	}
+
+	text := message.Text()
+	if message.Title != "" && message.Title != text {
+		text = message.Title + ": " + text
+	}
+	return header + data.String() + " " + text
+}
+
+// frame frames a message for the network. Streams prefix messages with their length,
+// datagrams carry one message each and are truncated to a size servers accept.
+func (s *Syslog) frame(msg string) []byte {
+	switch s.config.Network {
+	case SyslogTCP, SyslogTLS:
+		return []byte(strconv.Itoa(len(msg)) + " " + msg)
+	case SyslogUnix:
+		if addr := s.conn.RemoteAddr(); addr != nil && addr.Network() == "unix" {
+			// Stream sockets of the local daemon separate messages by newlines.
+			return []byte(strings.ReplaceAll(truncateBytes(msg, syslogMaxDatagram-1), "\n", " ") + "\n")
+		}
+	}
+	return []byte(truncateBytes(msg, syslogMaxDatagram))
+}
+
+// dialSyslogSocket connects to the local syslog socket at path, or at a common path
+// when empty, as datagram or stream socket.
+func dialSyslogSocket(ctx context.Context, dialer *net.Dialer, path string) (net.Conn, error) {
+	paths := syslogSockets
+	if path != "" {
+		paths = []string{path}
+	}
+	err := ErrNoSyslogSocket
+	for _, path := range paths {
+		for _, network := range []string{"unixgram", "unix"} {
+			var conn net.Conn
+			if conn, err = dialer.DialContext(ctx, network, path); err == nil {
+				return conn, nil
+			}
+		}
+	}
+	return nil, err
+}
+
+// syslogParamEscaper escapes the characters RFC 5424 requires in parameter values.
+var syslogParamEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// syslogParamName returns a valid parameter name, replacing the characters RFC 5424
+// does not allow and truncating it to 32 characters.
+func syslogParamName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, name)
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
+
+// syslogHeaderField returns a valid header field of printable ASCII characters of at
+// most max characters, or the nil value "-" when empty.
+func syslogHeaderField(value string, max int) string {
+	value = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return -1
+		}
+		return r
+	}, value)
+	if len(value) > max {
+		value = value[:max]
+	}
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// truncateBytes truncates text to at most max bytes without splitting a character.
+func truncateBytes(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	for max > 0 && !utf8.RuneStart(text[max]) {
+		max--
+	}
+	return text[:max]
+}
+
+// syslogError classifies an error of the connection. Certificates that fail to verify
+// are permanent, other errors such as an unavailable server are retried.
+func syslogError(err error) error {
+	var certErr *tls.CertificateVerificationError
+	if errors.As(err, &certErr) {
+		return Permanent(err)
+	}
+	return Retryable(err)
+}
@@ -0,0 +1,154 @@
+package channel
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslog_Format(t *testing.T) {
+	tests := []struct {
+		name     string
+		facility string
+		message  Message
+		expected string
+	}{
+		{
+			name:     "Message with all fields",
+			facility: "local0",
+			message: Message{
+				Title:          "Disk full",
+				Body:           "Disk of db-1 is full",
+				Severity:       SeverityError,
+				Tags:           []string{"disk", "db"},
+				Metadata:       map[string]string{"host": "db-1", "path name": `C:\data "main"]`},
+				CorrelationKey: "disk-db-1",
+			},
+			expected: `<131>1 2024-05-01T12:00:00.000000Z host notification-service %d notification ` +
+				`[notification@32473 id="n-1" severity="error" correlationKey="disk-db-1" tags="disk,db"]` +
+				`[metadata@32473 host="db-1" path_name="C:\\data \"main\"\]"] Disk full: Disk of db-1 is full`,
+		},
+		{
+			name:     "Message without title",
+			message:  Message{Body: "Disk full"},
+			expected: `<14>1 2024-05-01T12:00:00.000000Z host notification-service %d notification [notification@32473 id="n-1" severity="info"] Disk full`,
+		},
+		{
+			name:     "Critical message",
+			facility: "daemon",
+			message:  Message{Title: "Disk full", Severity: SeverityCritical},
+			expected: `<26>1 2024-05-01T12:00:00.000000Z host notification-service %d notification [notification@32473 id="n-1" severity="critical"] Disk full`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender, err := NewSyslog(SyslogConfig{Network: SyslogUDP, Facility: tt.facility, Hostname: "host"})
+			require.NoError(t, err)
+			sender.now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+
+			actual := sender.format(WithNotificationID(context.Background(), "n-1"), tt.message)
+			assert.Equal(t, fmt.Sprintf(tt.expected, os.Getpid()), actual)
+		})
+	}
+}
+
+func TestSyslog_SendUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+	sender, err := NewSyslog(SyslogConfig{Network: SyslogUDP, Address: conn.LocalAddr().String()})
+	require.NoError(t, err)
+	defer sender.Close()
+
+	// Each message is one datagram, truncated to the maximum size
+	require.NoError(t, sender.Send(context.Background(), Message{Body: "Disk\nfull"}))
+	require.NoError(t, sender.Send(context.Background(), Message{Body: strings.Repeat("ä", syslogMaxDatagram)}))
+
+	buf := make([]byte, 2*syslogMaxDatagram)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(string(buf[:n]), "] Disk\nfull"))
+	n, _, err = conn.ReadFrom(buf)
+	require.NoError(t, err)
+	// Truncation does not split characters, so one byte may be left over
+	assert.GreaterOrEqual(t, n, syslogMaxDatagram-1)
+	assert.LessOrEqual(t, n, syslogMaxDatagram)
+	assert.True(t, utf8.Valid(buf[:n]))
+}
+
+func TestSyslog_SendTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	sender, err := NewSyslog(SyslogConfig{Network: SyslogTCP, Address: listener.Addr().String()})
+	require.NoError(t, err)
+	defer sender.Close()
+
+	// Messages are framed with their length
+	require.NoError(t, sender.Send(context.Background(), Message{Body: "Disk\nfull"}))
+	require.NoError(t, sender.Send(context.Background(), Message{Body: "Disk usage is back to 60%"}))
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reader := bufio.NewReader(conn)
+	for _, body := range []string{"Disk\nfull", "Disk usage is back to 60%"} {
+		length, err := reader.ReadString(' ')
+		require.NoError(t, err)
+		n, err := strconv.Atoi(strings.TrimSpace(length))
+		require.NoError(t, err)
+		msg := make([]byte, n)
+		_, err = reader.Read(msg)
+		require.NoError(t, err)
+		assert.True(t, strings.HasSuffix(string(msg), "] "+body))
+	}
+}
+
+func TestSyslog_SendUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	conn, err := net.ListenPacket("unixgram", path)
+	require.NoError(t, err)
+	defer conn.Close()
+	sender, err := NewSyslog(SyslogConfig{Address: path})
+	require.NoError(t, err)
+	defer sender.Close()
+
+	require.NoError(t, sender.Send(context.Background(), Message{Body: "Disk full"}))
+
+	buf := make([]byte, syslogMaxDatagram)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(buf[:n]), "<14>1 "))
+	assert.True(t, strings.HasSuffix(string(buf[:n]), "] Disk full"))
+}
+
+func TestSyslog_SendError(t *testing.T) {
+	// Unavailable servers are retried
+	sender, err := NewSyslog(SyslogConfig{Address: filepath.Join(t.TempDir(), "missing")})
+	require.NoError(t, err)
+	err = sender.Send(context.Background(), Message{Body: "Disk full"})
+	require.Error(t, err)
+	assert.False(t, IsPermanent(err))
+}
+
+func TestNewSyslog(t *testing.T) {
+	_, err := NewSyslog(SyslogConfig{Network: "http"})
+	assert.ErrorIs(t, err, ErrInvalidSyslogNetwork)
+	_, err = NewSyslog(SyslogConfig{Facility: "local9"})
+	assert.ErrorIs(t, err, ErrInvalidSyslogFacility)
+}
@@ -0,0 +1,186 @@
+package channel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Adaptive Card limits. Teams rejects cards larger than about 28 KB and shows at most six actions.
+const (
+	teamsCardVersion    = "1.4"
+	teamsMaxTextLength  = 20000
+	teamsMaxFactLength  = 500
+	teamsMaxActions     = 6
+	teamsMaxTitleLength = 250
+)
+
+// teamsSeverityStyle maps severities to the style of the container holding the title.
+var teamsSeverityStyle = map[Severity]string{
+	SeverityInfo:     "accent",
+	SeverityWarning:  "warning",
+	SeverityError:    "attention",
+	SeverityCritical: "attention",
+}
+
+// teamsSeverityColor maps severities to the colour of the title text.
+var teamsSeverityColor = map[Severity]string{
+	SeverityWarning:  "Warning",
+	SeverityError:    "Attention",
+	SeverityCritical: "Attention",
+}
+
+// TeamsPayload is the request posted to a Teams incoming webhook or Workflows URL.
+type TeamsPayload struct {
+	Type        string            `json:"type"`
+	Attachments []TeamsAttachment `json:"attachments"`
+}
+
+// TeamsAttachment wraps an Adaptive Card in a message.
+type TeamsAttachment struct {
+	ContentType string       `json:"contentType"`
+	Content     AdaptiveCard `json:"content"`
+}
+
+// AdaptiveCard is an Adaptive Card, see https://adaptivecards.io/explorer/AdaptiveCard.html.
+type AdaptiveCard struct {
+	Schema  string            `json:"$schema"`
+	Type    string            `json:"type"`
+	Version string            `json:"version"`
+	Body    []AdaptiveElement `json:"body"`
+	Actions []AdaptiveAction  `json:"actions,omitempty"`
+	MSTeams map[string]string `json:"msteams,omitempty"` // Teams specific settings, such as the card width.
+}
+
+// AdaptiveElement is a card element. Only the fields of its Type are set: TextBlock,
+// Container or FactSet.
+type AdaptiveElement struct {
+	Type     string            `json:"type"`
+	Text     string            `json:"text,omitempty"`
+	Size     string            `json:"size,omitempty"`
+	Weight   string            `json:"weight,omitempty"`
+	Color    string            `json:"color,omitempty"`
+	IsSubtle bool              `json:"isSubtle,omitempty"`
+	Wrap     bool              `json:"wrap,omitempty"`
+	Spacing  string            `json:"spacing,omitempty"`
+	Style    string            `json:"style,omitempty"`
+	Bleed    bool              `json:"bleed,omitempty"`
+	Items    []AdaptiveElement `json:"items,omitempty"`
+	Facts    []AdaptiveFact    `json:"facts,omitempty"`
+}
+
+// AdaptiveFact is a key value pair of a FactSet.
+type AdaptiveFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// AdaptiveAction is an Action.OpenUrl button.
+type AdaptiveAction struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// teamsPayload wraps the card of a message in a webhook request.
+func teamsPayload(message Message) TeamsPayload {
+	return TeamsPayload{
+		Type: "message",
+		Attachments: []TeamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content:     teamsCard(message),
+		}},
+	}
+}
+
+// teamsCard renders a message as an Adaptive Card: a container coloured by severity with
+// the title, the body, metadata as facts, tags and buttons for links.
+func teamsCard(message Message) AdaptiveCard {
+	severity := message.SeverityOrDefault()
+
+	// Show the title and severity in a container styled by severity.
+	var header []AdaptiveElement
+	if message.Title != "" {
+		header = append(header, AdaptiveElement{
+			Type:   "TextBlock",
+			Text:   truncate(message.Title, teamsMaxTitleLength),
+			Size:   "Large",
+			Weight: "Bolder",
+			Color:  teamsSeverityColor[severity],
+			Wrap:   true,
+		})
+	}
+	header = append(header, AdaptiveElement{
+		Type:     "TextBlock",
+		Text:     fmt.Sprintf("Severity: **%s**", severity),
+		Size:     "Small",
+		IsSubtle: true,
+		Spacing:  "None",
+		Wrap:     true,
+	})
+	body := []AdaptiveElement{{
+		Type:  "Container",
+		Style: teamsSeverityStyle[severity],
+		Bleed: true,
+		Items: header,
+	}}
+
+	// Prefer the Markdown body as TextBlocks render a subset of it.
+	if text := teamsBody(message); text != "" {
+		body = append(body, AdaptiveElement{
+			Type: "TextBlock",
+			Text: truncate(text, teamsMaxTextLength),
+			Wrap: true,
+		})
+	}
+
+	// Show metadata as facts.
+	var facts []AdaptiveFact
+	for _, key := range message.MetadataKeys() {
+		facts = append(facts, AdaptiveFact{Title: key, Value: truncate(message.Metadata[key], teamsMaxFactLength)})
+	}
+	if len(facts) > 0 {
+		body = append(body, AdaptiveElement{Type: "FactSet", Facts: facts})
+	}
+
+	if len(message.Tags) > 0 {
+		body = append(body, AdaptiveElement{
+			Type:     "TextBlock",
+			Text:     strings.Join(message.Tags, ", "),
+			Size:     "Small",
+			IsSubtle: true,
+			Wrap:     true,
+		})
+	}
+
+	// Turn links and attachments available by URL into buttons.
+	var actions []AdaptiveAction
+	links := append([]Link(nil), message.Links...)
+	for _, attachment := range message.Attachments {
+		if attachment.URL != "" {
+			links = append(links, Link{Title: attachment.Filename, URL: attachment.URL})
+		}
+	}
+	for _, link := range links {
+		if len(actions) == teamsMaxActions {
+			break
+		}
+		actions = append(actions, AdaptiveAction{Type: "Action.OpenUrl", Title: link.title(), URL: link.URL})
+	}
+
+	return AdaptiveCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: teamsCardVersion,
+		Body:    body,
+		Actions: actions,
+		MSTeams: map[string]string{"width": "Full"},
+	}
+}
+
+// teamsBody returns the body to show, preferring the Markdown body.
+func teamsBody(message Message) string {
+	if message.Markdown != "" {
+		return message.Markdown
+	}
+	return message.Body
+}
@@ -0,0 +1,77 @@
+package channel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeamsCard(t *testing.T) {
+	tests := []struct {
+		name            string
+		message         Message
+		expectedBody    []AdaptiveElement
+		expectedActions []AdaptiveAction
+	}{
+		{
+			name:    "Plain body",
+			message: Message{Body: "Hello"},
+			expectedBody: []AdaptiveElement{
+				{Type: "Container", Style: "accent", Bleed: true, Items: []AdaptiveElement{
+					{Type: "TextBlock", Text: "Severity: **info**", Size: "Small", IsSubtle: true, Spacing: "None", Wrap: true},
+				}},
+				{Type: "TextBlock", Text: "Hello", Wrap: true},
+			},
+		},
+		{
+			name: "Rich message",
+			message: Message{
+				Title:       "Disk almost full",
+				Body:        "Only 5% left",
+				Markdown:    "Only **5%** left",
+				Severity:    SeverityCritical,
+				Tags:        []string{"disk", "prod"},
+				Metadata:    map[string]string{"region": "eu-west-1", "host": "db-1"},
+				Links:       []Link{{Title: "Dashboard", URL: "https://example.com/d"}, {URL: "https://example.com/runbook"}},
+				Attachments: []Attachment{{Filename: "graph.png", URL: "https://example.com/g.png"}, {Filename: "inline.txt", Content: []byte("x")}},
+			},
+			expectedBody: []AdaptiveElement{
+				{Type: "Container", Style: "attention", Bleed: true, Items: []AdaptiveElement{
+					{Type: "TextBlock", Text: "Disk almost full", Size: "Large", Weight: "Bolder", Color: "Attention", Wrap: true},
+					{Type: "TextBlock", Text: "Severity: **critical**", Size: "Small", IsSubtle: true, Spacing: "None", Wrap: true},
+				}},
+				{Type: "TextBlock", Text: "Only **5%** left", Wrap: true},
+				{Type: "FactSet", Facts: []AdaptiveFact{{Title: "host", Value: "db-1"}, {Title: "region", Value: "eu-west-1"}}},
+				{Type: "TextBlock", Text: "disk, prod", Size: "Small", IsSubtle: true, Wrap: true},
+			},
+			expectedActions: []AdaptiveAction{
+				{Type: "Action.OpenUrl", Title: "Dashboard", URL: "https://example.com/d"},
+				{Type: "Action.OpenUrl", Title: "https://example.com/runbook", URL: "https://example.com/runbook"},
+				{Type: "Action.OpenUrl", Title: "graph.png", URL: "https://example.com/g.png"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := teamsCard(tt.message)
+			assert.Equal(t, "AdaptiveCard", card.Type)
+			assert.Equal(t, teamsCardVersion, card.Version)
+			assert.Equal(t, tt.expectedBody, card.Body)
+			assert.Equal(t, tt.expectedActions, card.Actions)
+		})
+	}
+}
+
+func TestTeamsCard_Limits(t *testing.T) {
+	var links []Link
+	for i := 0; i < 10; i++ {
+		links = append(links, Link{URL: "https://example.com"})
+	}
+	card := teamsCard(Message{Body: strings.Repeat("a", teamsMaxTextLength+10), Links: links})
+
+	// Long bodies are truncated and only the first links become buttons
+	assert.Len(t, []rune(card.Body[1].Text), teamsMaxTextLength)
+	assert.Len(t, card.Actions, teamsMaxActions)
+}
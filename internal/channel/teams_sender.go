@@ -0,0 +1,109 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+)
+
+// teamsErrorPattern matches the failures legacy incoming webhooks report in the body of a 200 response.
+var teamsErrorPattern = regexp.MustCompile(`returned HTTP error (\d{3})`)
+
+// Teams represents a Microsoft Teams channel posting Adaptive Cards to an incoming
+// webhook or a Workflows URL.
+type Teams struct {
+	webhookURL string
+	name       string       // The name of the sender.
+	client     *http.Client // HTTP client for making requests.
+}
+
+// TeamsConfig holds the settings of the Teams channel.
+type TeamsConfig struct {
+	WebhookURL string // Incoming webhook or Workflows URL.
+	Name       string // Channel name, defaults to Teams.
+}
+
+// NewTeams creates a new Teams channel instance.
+func NewTeams(webhookURL string, client *http.Client) *Teams {
+	return NewTeamsWithConfig(TeamsConfig{WebhookURL: webhookURL}, client)
+}
+
+// NewTeamsWithConfig creates a new Teams channel instance with the given settings.
+func NewTeamsWithConfig(config TeamsConfig, client *http.Client) *Teams {
+	return &Teams{
+		webhookURL: config.WebhookURL,
+		name:       senderName(config.Name, "Teams"),
+		client:     client,
+	}
+}
+
+// Send posts a message to the Teams webhook as an Adaptive Card.
+func (t *Teams) Send(ctx context.Context, message Message) error {
+	// Webhooks post to a fixed channel.
+	if len(message.Recipients) > 0 {
+		return Permanent(fmt.Errorf("%w: teams webhooks post to a fixed channel", ErrRecipientsNotSupported))
+	}
+
+	// Create a JSON request body.
+	reqBody, err := json.Marshal(teamsPayload(message))
+	if err != nil {
+		return Permanent(err)
+	}
+
+	// Send the POST request to the webhook URL.
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.webhookURL, bytes.NewReader(reqBody))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return Retryable(err)
+	}
+	defer resp.Body.Close()
+
+	// Classify failures so the notifier knows whether to retry.
+	if err := CheckResponse(resp); err != nil {
+		return err
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return Retryable(err)
+	}
+	if err := teamsBodyError(string(b)); err != nil {
+		return err
+	}
+	log.Printf("teams message sent: %s", message)
+
+	return nil
+}
+
+// GetName returns the name of the Teams sender.
+func (t *Teams) GetName() string {
+	return t.name
+}
+
+// teamsBodyError classifies a failure reported in the body of a successful response
+// by the status code it mentions, like CheckResponse does.
+func teamsBodyError(body string) error {
+	match := teamsErrorPattern.FindStringSubmatch(body)
+	if match == nil {
+		return nil
+	}
+	err := fmt.Errorf("request failed: %s", body)
+	code, _ := strconv.Atoi(match[1])
+	switch {
+	case code == http.StatusTooManyRequests:
+		return RateLimited(err, 0)
+	case code == http.StatusRequestTimeout || code >= 500:
+		return Retryable(err)
+	default:
+		return Permanent(err)
+	}
+}
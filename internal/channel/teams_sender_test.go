@@ -0,0 +1,122 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeams_Send(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		response         string
+		retryAfter       string
+		expectErr        string
+		expectPermanent  bool
+		expectRateLimit  bool
+		expectRetryAfter time.Duration
+	}{
+		{
+			name:     "Posting to an incoming webhook returns no error",
+			status:   http.StatusOK,
+			response: `1`,
+		},
+		{
+			name:   "Posting to a Workflows URL returns no error",
+			status: http.StatusAccepted,
+		},
+		{
+			name:            "Posting to a removed webhook returns permanent error",
+			status:          http.StatusNotFound,
+			response:        `Webhook not found`,
+			expectErr:       "request failed: Webhook not found",
+			expectPermanent: true,
+		},
+		{
+			name:             "Posting when rate limited returns retry after",
+			status:           http.StatusTooManyRequests,
+			retryAfter:       "10",
+			response:         `Too many requests`,
+			expectErr:        "request failed: Too many requests",
+			expectRateLimit:  true,
+			expectRetryAfter: 10 * time.Second,
+		},
+		{
+			name:            "Rate limiting reported in the response body returns rate limited error",
+			status:          http.StatusOK,
+			response:        `Webhook message delivery failed with error: Microsoft Teams endpoint returned HTTP error 429 with ContextId abc`,
+			expectErr:       "request failed: Webhook message delivery failed with error: Microsoft Teams endpoint returned HTTP error 429 with ContextId abc",
+			expectRateLimit: true,
+		},
+		{
+			name:            "Too large card reported in the response body returns permanent error",
+			status:          http.StatusOK,
+			response:        `Webhook message delivery failed with error: Microsoft Teams endpoint returned HTTP error 413 with ContextId abc`,
+			expectErr:       "request failed: Webhook message delivery failed with error: Microsoft Teams endpoint returned HTTP error 413 with ContextId abc",
+			expectPermanent: true,
+		},
+		{
+			name:      "Posting during an outage returns retryable error",
+			status:    http.StatusBadGateway,
+			response:  `Bad gateway`,
+			expectErr: "request failed: Bad gateway",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload TeamsPayload
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+				assert.NoError(t, json.NewDecoder(req.Body).Decode(&payload))
+				if tt.retryAfter != "" {
+					rw.Header().Set("Retry-After", tt.retryAfter)
+				}
+				rw.WriteHeader(tt.status)
+				rw.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			s := NewTeams(server.URL, server.Client())
+			err := s.Send(context.Background(), Message{Title: "Deploy", Body: "Hello", Severity: SeverityWarning})
+			assert.Equal(t, "message", payload.Type)
+			assert.Equal(t, "application/vnd.microsoft.card.adaptive", payload.Attachments[0].ContentType)
+			assert.Equal(t, "warning", payload.Attachments[0].Content.Body[0].Style)
+			if tt.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expectErr)
+			assert.Equal(t, tt.expectPermanent, IsPermanent(err))
+			var rateLimited *RateLimitedError
+			assert.Equal(t, tt.expectRateLimit, errors.As(err, &rateLimited))
+			retryAfter, _ := RetryAfter(err)
+			assert.Equal(t, tt.expectRetryAfter, retryAfter)
+		})
+	}
+}
+
+func TestTeams_SendRecipients(t *testing.T) {
+	s := NewTeams("http://localhost", http.DefaultClient)
+
+	// Webhooks cannot post anywhere else
+	err := s.Send(context.Background(), Message{Body: "Hello", Recipients: []string{"alerts"}})
+	assert.ErrorIs(t, err, ErrRecipientsNotSupported)
+	assert.True(t, IsPermanent(err))
+}
+
+func TestTeams_GetName(t *testing.T) {
+	assert.Equal(t, "Teams", NewTeams("http://localhost", http.DefaultClient).GetName())
+}
+
+func TestTeams_GetNameWithConfig(t *testing.T) {
+	teams := NewTeamsWithConfig(TeamsConfig{WebhookURL: "http://localhost", Name: "teams-dev"}, http.DefaultClient)
+	assert.Equal(t, "teams-dev", teams.GetName())
+}
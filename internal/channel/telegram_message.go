@@ -0,0 +1,149 @@
+package channel
+
+import (
+	"html"
+	"strings"
+)
+
+// Telegram parse modes, see https://core.telegram.org/bots/api#formatting-options.
+const (
+	TelegramMarkdownV2 = "MarkdownV2"
+	TelegramHTML       = "HTML"
+)
+
+// Telegram message limits, counted in characters after parsing the formatting.
+const (
+	telegramMaxText    = 4096
+	telegramMaxTitle   = 256
+	telegramMaxDetails = 1024
+	telegramMaxButtons = 100
+)
+
+// telegramSeverityEmoji is shown before the title, as Telegram messages have no colours.
+var telegramSeverityEmoji = map[Severity]string{
+	SeverityInfo:     "ℹ️",
+	SeverityWarning:  "⚠️",
+	SeverityError:    "❌",
+	SeverityCritical: "🚨",
+}
+
+// telegramMarkdownEscaper escapes the characters MarkdownV2 reserves outside of entities.
+var telegramMarkdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "~", `\~`,
+	"`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`, "|", `\|`,
+	"{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+// TelegramMessage is the request body of sendMessage.
+type TelegramMessage struct {
+	ChatID      string                  `json:"chat_id"`
+	Text        string                  `json:"text"`
+	ParseMode   string                  `json:"parse_mode"`
+	ReplyMarkup *TelegramInlineKeyboard `json:"reply_markup,omitempty"`
+}
+
+// TelegramInlineKeyboard is a keyboard of buttons shown below a message.
+type TelegramInlineKeyboard struct {
+	InlineKeyboard [][]TelegramButton `json:"inline_keyboard"`
+}
+
+// TelegramButton is a button of an inline keyboard opening a URL.
+type TelegramButton struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
+// telegramMessages renders a message as one or more Telegram messages. The title starts
+// the first message and the details end the last one, which also carries the links as
+// buttons. Bodies too long for a single message are continued in further messages.
+func telegramMessages(message Message, parseMode string) []TelegramMessage {
+	f := telegramFormatter{parseMode: parseMode}
+
+	// Split the plain text, as the limit applies after parsing the formatting.
+	header := telegramSeverityEmoji[message.SeverityOrDefault()]
+	if message.Title != "" {
+		header += " " + truncate(message.Title, telegramMaxTitle)
+	}
+	details := telegramDetails(message)
+	budget := telegramMaxText - len([]rune(header)) - len([]rune(details)) - 4
+	chunks := splitText(message.Text(), budget)
+	if len(chunks) == 0 || message.Text() == message.Title {
+		chunks = []string{""}
+	}
+
+	messages := make([]TelegramMessage, len(chunks))
+	for i, chunk := range chunks {
+		var parts []string
+		if i == 0 {
+			parts = append(parts, f.bold(header))
+		}
+		if chunk != "" {
+			parts = append(parts, f.escape(chunk))
+		}
+		if i == len(chunks)-1 && details != "" {
+			parts = append(parts, f.escape(details))
+		}
+		messages[i] = TelegramMessage{Text: strings.Join(parts, "\n\n"), ParseMode: parseMode}
+	}
+	messages[len(messages)-1].ReplyMarkup = telegramKeyboard(message)
+	return messages
+}
+
+// telegramDetails returns the metadata and tags of a message as plain text lines.
+func telegramDetails(message Message) string {
+	var lines []string
+	for _, key := range message.MetadataKeys() {
+		lines = append(lines, key+": "+message.Metadata[key])
+	}
+	if len(message.Tags) > 0 {
+		lines = append(lines, "Tags: "+strings.Join(message.Tags, ", "))
+	}
+	return truncate(strings.Join(lines, "\n"), telegramMaxDetails)
+}
+
+// telegramKeyboard returns a button for each link and attachment URL, one per row.
+// Telegram only opens web links from buttons, so other URLs are left out.
+func telegramKeyboard(message Message) *TelegramInlineKeyboard {
+	links := append([]Link(nil), message.Links...)
+	for _, attachment := range message.Attachments {
+		if attachment.URL != "" {
+			links = append(links, Link{Title: attachment.Filename, URL: attachment.URL})
+		}
+	}
+
+	var keyboard [][]TelegramButton
+	for _, link := range links {
+		if !strings.HasPrefix(link.URL, "https://") && !strings.HasPrefix(link.URL, "http://") {
+			continue
+		}
+		if len(keyboard) == telegramMaxButtons {
+			break
+		}
+		keyboard = append(keyboard, []TelegramButton{{Text: link.title(), URL: link.URL}})
+	}
+	if len(keyboard) == 0 {
+		return nil
+	}
+	return &TelegramInlineKeyboard{InlineKeyboard: keyboard}
+}
+
+// telegramFormatter formats text for a parse mode.
+type telegramFormatter struct {
+	parseMode string
+}
+
+// escape escapes plain text so that it is shown as is.
+func (f telegramFormatter) escape(text string) string {
+	if f.parseMode == TelegramHTML {
+		return html.EscapeString(text)
+	}
+	return telegramMarkdownEscaper.Replace(text)
+}
+
+// bold formats plain text in bold.
+func (f telegramFormatter) bold(text string) string {
+	if f.parseMode == TelegramHTML {
+		return "<b>" + f.escape(text) + "</b>"
+	}
+	return "*" + f.escape(text) + "*"
+}
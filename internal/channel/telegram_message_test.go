@@ -0,0 +1,99 @@
+package channel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelegramMessages(t *testing.T) {
+	tests := []struct {
+		name          string
+		message       Message
+		parseMode     string
+		expectText    string
+		expectButtons [][]TelegramButton
+	}{
+		{
+			name: "MarkdownV2 message escapes reserved characters",
+			message: Message{
+				Title:    "Build #42 failed",
+				Body:     "Step (test) failed: expected 1.5 but got -2!",
+				Severity: SeverityError,
+				Metadata: map[string]string{"branch": "feature_x"},
+				Tags:     []string{"ci"},
+			},
+			parseMode:  TelegramMarkdownV2,
+			expectText: "*❌ Build \\#42 failed*\n\nStep \\(test\\) failed: expected 1\\.5 but got \\-2\\!\n\nbranch: feature\\_x\nTags: ci",
+		},
+		{
+			name: "HTML message escapes markup",
+			message: Message{
+				Title: "Deploy <prod>",
+				Body:  "Rolled back a & b",
+			},
+			parseMode:  TelegramHTML,
+			expectText: "<b>ℹ️ Deploy &lt;prod&gt;</b>\n\nRolled back a &amp; b",
+		},
+		{
+			name:       "Message without title starts with the severity",
+			message:    Message{Body: "Disk full", Severity: SeverityCritical},
+			parseMode:  TelegramMarkdownV2,
+			expectText: "*🚨*\n\nDisk full",
+		},
+		{
+			name: "Links and attachment URLs are buttons",
+			message: Message{
+				Title:       "Report",
+				Links:       []Link{{Title: "Dashboard", URL: "https://grafana.example.com"}, {URL: "mailto:ops@example.com"}},
+				Attachments: []Attachment{{Filename: "report.pdf", URL: "https://files.example.com/report.pdf"}},
+			},
+			parseMode:  TelegramMarkdownV2,
+			expectText: "*ℹ️ Report*",
+			expectButtons: [][]TelegramButton{
+				{{Text: "Dashboard", URL: "https://grafana.example.com"}},
+				{{Text: "report.pdf", URL: "https://files.example.com/report.pdf"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := telegramMessages(tt.message, tt.parseMode)
+			require.Len(t, messages, 1)
+			assert.Equal(t, tt.expectText, messages[0].Text)
+			assert.Equal(t, tt.parseMode, messages[0].ParseMode)
+			if tt.expectButtons == nil {
+				assert.Nil(t, messages[0].ReplyMarkup)
+				return
+			}
+			require.NotNil(t, messages[0].ReplyMarkup)
+			assert.Equal(t, tt.expectButtons, messages[0].ReplyMarkup.InlineKeyboard)
+		})
+	}
+}
+
+func TestTelegramMessages_LongBody(t *testing.T) {
+	message := Message{
+		Title:    "Log",
+		Body:     strings.Repeat("line\n", 2000),
+		Metadata: map[string]string{"host": "db-1"},
+		Links:    []Link{{URL: "https://logs.example.com"}},
+	}
+
+	// The body is continued in further messages, the last one has the details and buttons
+	messages := telegramMessages(message, TelegramHTML)
+	require.Len(t, messages, 3)
+	assert.True(t, strings.HasPrefix(messages[0].Text, "<b>ℹ️ Log</b>\n\nline\n"))
+	assert.Nil(t, messages[0].ReplyMarkup)
+	assert.True(t, strings.HasSuffix(messages[2].Text, "\n\nhost: db-1"))
+	assert.NotNil(t, messages[2].ReplyMarkup)
+	total := 0
+	for _, msg := range messages {
+		assert.LessOrEqual(t, len([]rune(msg.Text)), telegramMaxText+len("<b></b>"))
+		total += strings.Count(msg.Text, "line")
+	}
+	assert.Equal(t, 2000, total)
+}
@@ -0,0 +1,189 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TelegramAPIURL is the base URL of the Telegram Bot API.
+const TelegramAPIURL = "https://api.telegram.org"
+
+var (
+	ErrNoTelegramChats     = errors.New("telegram message has no chat ids")
+	ErrInvalidTelegramMode = errors.New("invalid telegram parse mode")
+)
+
+// TelegramConfig holds the settings of the Telegram channel.
+type TelegramConfig struct {
+	BotToken  string   // Token of the bot, as issued by @BotFather.
+	ChatIDs   []string // Chats messages are sent to without recipients.
+	ParseMode string   // TelegramMarkdownV2 (default) or TelegramHTML.
+	APIURL    string   // Base URL of the Bot API, defaults to TelegramAPIURL.
+	Name      string   // Channel name, defaults to Telegram.
+}
+
+// Telegram represents a channel sending messages with a Telegram bot. Recipients are
+// chat IDs, or @username of public channels, the bot is a member of.
+type Telegram struct {
+	config TelegramConfig
+	name   string       // The name of the sender.
+	client *http.Client // HTTP client for making requests.
+}
+
+// telegramResponse is the response of a Bot API method.
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+	Parameters  struct {
+		RetryAfter      int   `json:"retry_after"`
+		MigrateToChatID int64 `json:"migrate_to_chat_id"`
+	} `json:"parameters"`
+}
+
+// NewTelegram creates a new Telegram channel instance. It fails if the parse mode is invalid.
+func NewTelegram(config TelegramConfig, client *http.Client) (*Telegram, error) {
+	switch config.ParseMode {
+	case "":
+		config.ParseMode = TelegramMarkdownV2
+	case TelegramMarkdownV2, TelegramHTML:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTelegramMode, config.ParseMode)
+	}
+	if config.APIURL == "" {
+		config.APIURL = TelegramAPIURL
+	}
+	config.APIURL = strings.TrimSuffix(config.APIURL, "/")
+	return &Telegram{
+		config: config,
+		name:   senderName(config.Name, "Telegram"),
+		client: client,
+	}, nil
+}
+
+// Send sends a message to each chat. Long messages are sent as several messages.
+func (t *Telegram) Send(ctx context.Context, message Message) error {
+	chatIDs := message.Recipients
+	if len(chatIDs) == 0 {
+		chatIDs = t.config.ChatIDs
+	}
+	if len(chatIDs) == 0 {
+		return Permanent(ErrNoTelegramChats)
+	}
+
+	messages := telegramMessages(message, t.config.ParseMode)
+	var errs []error
+	for _, chatID := range chatIDs {
+		if err := t.sendToChat(ctx, chatID, messages); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", chatID, err))
+		}
+	}
+	if err := JoinErrors(errs...); err != nil {
+		return err
+	}
+	log.Printf("telegram message sent: %s", message)
+
+	return nil
+}
+
+// GetName returns the name of the Telegram sender.
+func (t *Telegram) GetName() string {
+	return t.name
+}
+
+// sendToChat sends the messages to a chat in order, skipping those the chat got in an
+// earlier attempt.
+func (t *Telegram) sendToChat(ctx context.Context, chatID string, messages []TelegramMessage) error {
+	target := chatID
+	for i, msg := range messages {
+		err := sendOnce(ctx, fmt.Sprintf("%s#%d", target, i+1), func() error {
+			msg.ChatID = chatID
+			response, err := t.sendMessage(ctx, msg)
+
+			// Groups upgraded to supergroups get a new ID, send there instead.
+			if migrateTo := response.Parameters.MigrateToChatID; err != nil && migrateTo != 0 {
+				log.Printf("telegram chat %s migrated to %d, update the configured chat id", chatID, migrateTo)
+				chatID = strconv.FormatInt(migrateTo, 10)
+				msg.ChatID = chatID
+				_, err = t.sendMessage(ctx, msg)
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendMessage calls the sendMessage method, returning the decoded response.
+func (t *Telegram) sendMessage(ctx context.Context, msg TelegramMessage) (telegramResponse, error) {
+	var response telegramResponse
+
+	// Create a JSON request body.
+	reqBody, err := json.Marshal(msg)
+	if err != nil {
+		return response, Permanent(err)
+	}
+
+	// Send the POST request to the method URL, which contains the bot token.
+	endpoint := t.config.APIURL + "/bot" + t.config.BotToken + "/sendMessage"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(reqBody))
+	if err != nil {
+		return response, Permanent(errors.New("invalid telegram api url"))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		// Keep the bot token out of errors and logs.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return response, Retryable(fmt.Errorf("telegram request failed: %w", err))
+	}
+	defer resp.Body.Close()
+
+	// The Bot API describes failures in the body, with the delay of rate limited requests.
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return response, Retryable(err)
+	}
+	if err := json.Unmarshal(b, &response); err != nil {
+		// Proxies in front of the API may answer with other bodies.
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return response, Retryable(fmt.Errorf("decoding telegram response: %w", err))
+		}
+		response = telegramResponse{Description: string(b)}
+	}
+	if response.OK {
+		return response, nil
+	}
+	return response, telegramAPIError(resp.StatusCode, response)
+}
+
+// telegramAPIError classifies an error reported by the Bot API.
+func telegramAPIError(status int, response telegramResponse) error {
+	if response.ErrorCode != 0 {
+		status = response.ErrorCode
+	}
+	err := fmt.Errorf("telegram api error: %s", response.Description)
+	switch {
+	case status == http.StatusTooManyRequests:
+		return RateLimited(err, time.Duration(response.Parameters.RetryAfter)*time.Second)
+	case status >= 500:
+		return Retryable(err)
+	default:
+		return Permanent(err)
+	}
+}
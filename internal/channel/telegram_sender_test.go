@@ -0,0 +1,174 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelegram_Send(t *testing.T) {
+	tests := []struct {
+		name             string
+		recipients       []string
+		status           int
+		response         string
+		expectChats      []string
+		expectErr        string
+		expectPermanent  bool
+		expectRetryAfter time.Duration
+	}{
+		{
+			name:        "Sending to the configured chats returns no error",
+			status:      http.StatusOK,
+			response:    `{"ok":true,"result":{"message_id":1}}`,
+			expectChats: []string{"-100123", "@ops"},
+		},
+		{
+			name:        "Recipients override the configured chats",
+			recipients:  []string{"42"},
+			status:      http.StatusOK,
+			response:    `{"ok":true,"result":{"message_id":1}}`,
+			expectChats: []string{"42"},
+		},
+		{
+			name:             "Sending when rate limited returns retry after",
+			recipients:       []string{"42"},
+			status:           http.StatusTooManyRequests,
+			response:         `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 7","parameters":{"retry_after":7}}`,
+			expectErr:        "42: telegram api error: Too Many Requests: retry after 7",
+			expectRetryAfter: 7 * time.Second,
+		},
+		{
+			name:            "Sending to a chat the bot was removed from returns permanent error",
+			recipients:      []string{"42"},
+			status:          http.StatusForbidden,
+			response:        `{"ok":false,"error_code":403,"description":"Forbidden: bot was kicked from the group chat"}`,
+			expectErr:       "42: telegram api error: Forbidden: bot was kicked from the group chat",
+			expectPermanent: true,
+		},
+		{
+			name:       "Sending during an outage returns retryable error",
+			recipients: []string{"42"},
+			status:     http.StatusBadGateway,
+			response:   `Bad gateway`,
+			expectErr:  "42: telegram api error: Bad gateway",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var chats []string
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "/botbot-token/sendMessage", req.URL.Path)
+				var msg TelegramMessage
+				assert.NoError(t, json.NewDecoder(req.Body).Decode(&msg))
+				assert.Equal(t, TelegramMarkdownV2, msg.ParseMode)
+				assert.Equal(t, "*⚠️ Disk full*\n\nDisk of db\\-1 is full", msg.Text)
+				chats = append(chats, msg.ChatID)
+				rw.WriteHeader(tt.status)
+				_, _ = rw.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			tg, err := NewTelegram(TelegramConfig{BotToken: "bot-token", ChatIDs: []string{"-100123", "@ops"}, APIURL: server.URL}, server.Client())
+			require.NoError(t, err)
+			err = tg.Send(context.Background(), Message{Title: "Disk full", Body: "Disk of db-1 is full", Severity: SeverityWarning, Recipients: tt.recipients})
+			if tt.expectChats != nil {
+				assert.Equal(t, tt.expectChats, chats)
+			}
+			if tt.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expectErr)
+			assert.Equal(t, tt.expectPermanent, IsPermanent(err))
+			retryAfter, _ := RetryAfter(err)
+			assert.Equal(t, tt.expectRetryAfter, retryAfter)
+		})
+	}
+}
+
+func TestTelegram_SendToMigratedChat(t *testing.T) {
+	var chats []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var msg TelegramMessage
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&msg))
+		chats = append(chats, msg.ChatID)
+		if msg.ChatID == "-123" {
+			rw.WriteHeader(http.StatusBadRequest)
+			_, _ = rw.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: group chat was upgraded to a supergroup chat","parameters":{"migrate_to_chat_id":-100123}}`))
+			return
+		}
+		_, _ = rw.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	defer server.Close()
+
+	tg, err := NewTelegram(TelegramConfig{BotToken: "bot-token", APIURL: server.URL}, server.Client())
+	require.NoError(t, err)
+
+	// The message is sent to the supergroup the group was upgraded to
+	err = tg.Send(context.Background(), Message{Body: "Disk full", Recipients: []string{"-123"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"-123", "-100123"}, chats)
+}
+
+func TestTelegram_SendProgress(t *testing.T) {
+	var chats []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var msg TelegramMessage
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&msg))
+		chats = append(chats, msg.ChatID)
+
+		// The second part to the second chat fails once
+		if len(chats) == 4 {
+			rw.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = rw.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	defer server.Close()
+
+	tg, err := NewTelegram(TelegramConfig{BotToken: "bot-token", ChatIDs: []string{"41", "42"}, APIURL: server.URL}, server.Client())
+	require.NoError(t, err)
+	progress := NewProgress(nil)
+	ctx := WithProgress(context.Background(), progress)
+	message := Message{Body: strings.Repeat("a", 5000)}
+
+	err = tg.Send(ctx, message)
+	assert.Error(t, err)
+	assert.False(t, IsPermanent(err))
+	assert.Equal(t, []string{"41#1", "41#2", "42#1"}, progress.Targets())
+
+	// The retry only sends the part that failed
+	assert.NoError(t, tg.Send(ctx, message))
+	assert.Equal(t, []string{"41", "41", "42", "42", "42"}, chats)
+}
+
+func TestTelegram_SendHidesBotToken(t *testing.T) {
+	tg, err := NewTelegram(TelegramConfig{BotToken: "secret-token", APIURL: "http://127.0.0.1:1"}, http.DefaultClient)
+	require.NoError(t, err)
+
+	err = tg.Send(context.Background(), Message{Body: "Disk full", Recipients: []string{"42"}})
+	require.Error(t, err)
+	assert.False(t, IsPermanent(err))
+	assert.False(t, strings.Contains(err.Error(), "secret-token"), err.Error())
+}
+
+func TestNewTelegram(t *testing.T) {
+	_, err := NewTelegram(TelegramConfig{BotToken: "bot-token", ParseMode: "Markdown"}, http.DefaultClient)
+	assert.ErrorIs(t, err, ErrInvalidTelegramMode)
+
+	// Messages need chats to be sent to
+	tg, err := NewTelegram(TelegramConfig{BotToken: "bot-token"}, http.DefaultClient)
+	require.NoError(t, err)
+	err = tg.Send(context.Background(), Message{Body: "Disk full"})
+	assert.ErrorIs(t, err, ErrNoTelegramChats)
+	assert.True(t, IsPermanent(err))
+}
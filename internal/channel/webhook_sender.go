@@ -0,0 +1,143 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/phgermanov/notification-service/pkg/webhook"
+)
+
+var (
+	ErrInvalidWebhook = errors.New("invalid webhook configuration")
+)
+
+// WebhookConfig holds the settings of the generic webhook channel.
+type WebhookConfig struct {
+	URL     string            // URL the messages are sent to.
+	Method  string            // HTTP method, one of POST (default), PUT or PATCH.
+	Headers map[string]string // Additional request headers.
+
+	// BodyTemplate is a text/template rendering the JSON body from the message, for example
+	// `{"text": {{json .Title}}}`. The json function encodes a value as JSON. Without a
+	// template the message itself is sent as JSON.
+	BodyTemplate string
+
+	// Secret signs requests with HMAC-SHA256, see the pkg/webhook package. Requests are
+	// not signed when it is empty.
+	Secret string
+	Name   string // Channel name, defaults to Webhook.
+}
+
+// Webhook represents a generic HTTP webhook channel.
+type Webhook struct {
+	config WebhookConfig
+	name   string             // The name of the sender.
+	client *http.Client       // HTTP client for making requests.
+	body   *template.Template // Parsed BodyTemplate, nil to send the message as JSON.
+	now    func() time.Time   // Clock used for the signature timestamp.
+}
+
+// NewWebhook creates a new Webhook channel instance. It fails if the method or the body template is invalid.
+func NewWebhook(config WebhookConfig, client *http.Client) (*Webhook, error) {
+	config.Method = strings.ToUpper(config.Method)
+	switch config.Method {
+	case "":
+		config.Method = http.MethodPost
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return nil, fmt.Errorf("%w: unsupported method %s", ErrInvalidWebhook, config.Method)
+	}
+	if config.URL == "" {
+		return nil, fmt.Errorf("%w: missing url", ErrInvalidWebhook)
+	}
+
+	w := &Webhook{
+		config: config,
+		name:   senderName(config.Name, "Webhook"),
+		client: client,
+		now:    time.Now,
+	}
+	if config.BodyTemplate != "" {
+		tmpl, err := template.New("body").Funcs(template.FuncMap{"json": webhookJSON}).Parse(config.BodyTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+		}
+		w.body = tmpl
+	}
+	return w, nil
+}
+
+// Send sends a message to the webhook. Recipients are passed on in the body for the receiver to handle.
+func (w *Webhook) Send(ctx context.Context, message Message) error {
+	// Render the JSON request body.
+	reqBody, err := w.render(message)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	// Create the request with the configured headers.
+	req, err := http.NewRequestWithContext(ctx, w.config.Method, w.config.URL, bytes.NewReader(reqBody))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range w.config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	// Sign the request so the receiver can verify it was sent by us.
+	if w.config.Secret != "" {
+		webhook.SignRequest(req, []byte(w.config.Secret), w.now(), reqBody)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return Retryable(err)
+	}
+	defer resp.Body.Close()
+
+	// Classify failures so the notifier knows whether to retry.
+	if err := CheckResponse(resp); err != nil {
+		return err
+	}
+	log.Printf("webhook message sent: %s", message)
+
+	return nil
+}
+
+// GetName returns the name of the Webhook sender.
+func (w *Webhook) GetName() string {
+	return w.name
+}
+
+// render renders the request body, checking that the template produced valid JSON.
+func (w *Webhook) render(message Message) ([]byte, error) {
+	if w.body == nil {
+		return json.Marshal(message)
+	}
+	var buf bytes.Buffer
+	if err := w.body.Execute(&buf, message); err != nil {
+		return nil, fmt.Errorf("rendering webhook body: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("rendering webhook body: invalid json: %s", buf.String())
+	}
+	return buf.Bytes(), nil
+}
+
+// webhookJSON encodes a value as JSON for use in body templates.
+func webhookJSON(value any) (string, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,175 @@
+package channel
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/phgermanov/notification-service/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWebhook(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    WebhookConfig
+		expectErr string
+	}{
+		{
+			name:   "Defaults to POST",
+			config: WebhookConfig{URL: "http://localhost"},
+		},
+		{
+			name:   "Accepts a lower case method",
+			config: WebhookConfig{URL: "http://localhost", Method: "put"},
+		},
+		{
+			name:      "Rejects GET",
+			config:    WebhookConfig{URL: "http://localhost", Method: "GET"},
+			expectErr: "unsupported method GET",
+		},
+		{
+			name:      "Rejects a missing URL",
+			config:    WebhookConfig{},
+			expectErr: "missing url",
+		},
+		{
+			name:      "Rejects a malformed template",
+			config:    WebhookConfig{URL: "http://localhost", BodyTemplate: `{"text": {{json .Title}`},
+			expectErr: "template: body:1:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewWebhook(tt.config, http.DefaultClient)
+			if tt.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidWebhook)
+			assert.ErrorContains(t, err, tt.expectErr)
+		})
+	}
+}
+
+func TestWebhook_Send(t *testing.T) {
+	message := Message{Title: "Disk \"full\"", Body: "Hello", Severity: SeverityWarning, Recipients: []string{"ops"}}
+	tests := []struct {
+		name            string
+		config          WebhookConfig
+		status          int
+		expectMethod    string
+		expectBody      string
+		expectHeaders   map[string]string
+		expectErr       string
+		expectPermanent bool
+	}{
+		{
+			name:         "Sends the message as JSON by default",
+			config:       WebhookConfig{},
+			status:       http.StatusOK,
+			expectMethod: http.MethodPost,
+			expectBody:   `{"title":"Disk \"full\"","body":"Hello","severity":"warning","recipients":["ops"]}`,
+			expectHeaders: map[string]string{
+				"Content-Type":          "application/json",
+				webhook.TimestampHeader: "",
+			},
+		},
+		{
+			name: "Renders the body template with headers",
+			config: WebhookConfig{
+				Method:       http.MethodPut,
+				Headers:      map[string]string{"authorization": "Bearer token"},
+				BodyTemplate: `{"text": {{json .Title}}, "level": {{json .SeverityOrDefault}}}`,
+			},
+			status:       http.StatusAccepted,
+			expectMethod: http.MethodPut,
+			expectBody:   `{"text": "Disk \"full\"", "level": "warning"}`,
+			expectHeaders: map[string]string{
+				"Authorization": "Bearer token",
+			},
+		},
+		{
+			name:            "Template producing invalid JSON returns permanent error",
+			config:          WebhookConfig{BodyTemplate: `{"text": {{.Title}}}`},
+			expectErr:       `rendering webhook body: invalid json: {"text": Disk "full"}`,
+			expectPermanent: true,
+		},
+		{
+			name:            "Template referencing unknown fields returns permanent error",
+			config:          WebhookConfig{BodyTemplate: `{"text": {{json .Subject}}}`},
+			expectErr:       `rendering webhook body: template: body:1:`,
+			expectPermanent: true,
+		},
+		{
+			name:         "Server error returns retryable error",
+			config:       WebhookConfig{},
+			status:       http.StatusInternalServerError,
+			expectMethod: http.MethodPost,
+			expectErr:    "request failed: oops",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var method, body string
+			var header http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				b, _ := io.ReadAll(req.Body)
+				method, body, header = req.Method, string(b), req.Header
+				rw.WriteHeader(tt.status)
+				if tt.status >= 300 {
+					rw.Write([]byte("oops"))
+				}
+			}))
+			defer server.Close()
+
+			tt.config.URL = server.URL
+			w, err := NewWebhook(tt.config, server.Client())
+			assert.NoError(t, err)
+
+			err = w.Send(context.Background(), message)
+			assert.Equal(t, tt.expectMethod, method)
+			if tt.expectBody != "" {
+				assert.JSONEq(t, tt.expectBody, body)
+			}
+			for key, value := range tt.expectHeaders {
+				assert.Equal(t, value, header.Get(key))
+			}
+			if tt.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.expectErr)
+			assert.Equal(t, tt.expectPermanent, IsPermanent(err))
+		})
+	}
+}
+
+func TestWebhook_SendSigned(t *testing.T) {
+	now := time.Now()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// The receiver verifies the signature with the shared secret
+		_, err := webhook.VerifyRequest(req, []byte("secret"), 0)
+		if err != nil {
+			rw.WriteHeader(http.StatusUnauthorized)
+			rw.Write([]byte(err.Error()))
+		}
+	}))
+	defer server.Close()
+
+	w, err := NewWebhook(WebhookConfig{URL: server.URL, Secret: "secret"}, server.Client())
+	assert.NoError(t, err)
+	w.now = func() time.Time { return now }
+	assert.NoError(t, w.Send(context.Background(), Message{Body: "Hello"}))
+
+	// Requests signed too long ago are rejected as replays
+	w.now = func() time.Time { return now.Add(-time.Hour) }
+	err = w.Send(context.Background(), Message{Body: "Hello"})
+	assert.EqualError(t, err, "request failed: webhook timestamp outside tolerance")
+	assert.True(t, IsPermanent(err))
+}
@@ -0,0 +1,280 @@
+package channel
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// webPushRecordSize is the record size of encrypted payloads. Push services must accept
+// payloads of up to 4096 bytes, which is sent as a single record.
+const webPushRecordSize = 4096
+
+// webPushMaxPayload is the size of the largest plaintext fitting into one record: the
+// record size less the header with the 65 byte key, the padding delimiter and the tag.
+const webPushMaxPayload = webPushRecordSize - (16 + 4 + 1 + 65) - 1 - 16
+
+var (
+	ErrInvalidSubscription  = errors.New("invalid push subscription")
+	ErrSubscriptionNotFound = errors.New("push subscription not found")
+	ErrNoSubscriptions      = errors.New("no push subscriptions")
+)
+
+// PushSubscription is a browser's Web Push subscription, as returned by
+// PushManager.subscribe(), registered for a user.
+type PushSubscription struct {
+	UserID    string               `json:"user_id"`
+	Endpoint  string               `json:"endpoint"`
+	Keys      PushSubscriptionKeys `json:"keys"`
+	CreatedAt time.Time            `json:"created_at"`
+}
+
+// PushSubscriptionKeys are the base64url encoded keys payloads are encrypted with.
+type PushSubscriptionKeys struct {
+	P256dh string `json:"p256dh"` // Public key of the browser.
+	Auth   string `json:"auth"`   // Authentication secret.
+}
+
+// Validate checks that the endpoint is an HTTPS URL and the keys can be decoded.
+func (s PushSubscription) Validate() error {
+	if s.UserID == "" {
+		return fmt.Errorf("%w: missing user id", ErrInvalidSubscription)
+	}
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return fmt.Errorf("%w: endpoint must be an https url", ErrInvalidSubscription)
+	}
+	if _, err := s.keys(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// subscriptionKeys are the decoded keys of a subscription.
+type subscriptionKeys struct {
+	public *ecdh.PublicKey
+	auth   []byte
+}
+
+// keys decodes the keys of a subscription.
+func (s PushSubscription) keys() (subscriptionKeys, error) {
+	public, err := decodeBase64URL(s.Keys.P256dh)
+	if err != nil {
+		return subscriptionKeys{}, fmt.Errorf("%w: p256dh is not base64url encoded", ErrInvalidSubscription)
+	}
+	key, err := ecdh.P256().NewPublicKey(public)
+	if err != nil {
+		return subscriptionKeys{}, fmt.Errorf("%w: p256dh is not a P-256 public key", ErrInvalidSubscription)
+	}
+	auth, err := decodeBase64URL(s.Keys.Auth)
+	if err != nil || len(auth) != 16 {
+		return subscriptionKeys{}, fmt.Errorf("%w: auth is not a 16 byte secret", ErrInvalidSubscription)
+	}
+	return subscriptionKeys{public: key, auth: auth}, nil
+}
+
+// SubscriptionStore keeps the Web Push subscriptions of users.
+type SubscriptionStore interface {
+	// Add registers a subscription, replacing one of the user with the same endpoint.
+	Add(subscription PushSubscription) error
+	// List returns the subscriptions of a user ordered by endpoint.
+	List(userID string) ([]PushSubscription, error)
+	// Remove unregisters a subscription. It returns ErrSubscriptionNotFound if the
+	// user has no subscription with the endpoint.
+	Remove(userID, endpoint string) error
+}
+
+// MemorySubscriptionStore is an in-memory SubscriptionStore. Its contents are lost when the process exits.
+type MemorySubscriptionStore struct {
+	mu            sync.RWMutex
+	subscriptions map[string]PushSubscription
+}
+
+// NewMemorySubscriptionStore creates a new MemorySubscriptionStore.
+func NewMemorySubscriptionStore() *MemorySubscriptionStore {
+	return &MemorySubscriptionStore{
+		subscriptions: make(map[string]PushSubscription),
+	}
+}
+
+// Add registers a subscription, replacing one of the user with the same endpoint.
+func (s *MemorySubscriptionStore) Add(subscription PushSubscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscriptions[SubscriptionKey(subscription.UserID, subscription.Endpoint)] = subscription
+	return nil
+}
+
+// List returns the subscriptions of a user ordered by endpoint.
+func (s *MemorySubscriptionStore) List(userID string) ([]PushSubscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Keys start with the user ID, so sorting them orders a user's subscriptions by endpoint.
+	prefix := SubscriptionKey(userID, "")
+	var keys []string
+	for key := range s.subscriptions {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	subscriptions := make([]PushSubscription, len(keys))
+	for i, key := range keys {
+		subscriptions[i] = s.subscriptions[key]
+	}
+	return subscriptions, nil
+}
+
+// Remove unregisters a subscription.
+func (s *MemorySubscriptionStore) Remove(userID, endpoint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := SubscriptionKey(userID, endpoint)
+	if _, found := s.subscriptions[key]; !found {
+		return ErrSubscriptionNotFound
+	}
+	delete(s.subscriptions, key)
+	return nil
+}
+
+// SubscriptionKey returns the key identifying a subscription of a user in a SubscriptionStore.
+func SubscriptionKey(userID, endpoint string) string {
+	return userID + "\x00" + endpoint
+}
+
+// parseVAPIDKey parses a base64url encoded P-256 private key, the format VAPID keys are
+// commonly generated in.
+func parseVAPIDKey(encoded string) (*ecdsa.PrivateKey, error) {
+	d, err := decodeBase64URL(encoded)
+	if err != nil {
+		return nil, errors.New("vapid private key is not base64url encoded")
+	}
+	key, err := ecdh.P256().NewPrivateKey(d)
+	if err != nil {
+		return nil, fmt.Errorf("vapid private key is not a P-256 key: %w", err)
+	}
+
+	// The uncompressed public key is 0x04 followed by the x and y coordinates.
+	public := key.PublicKey().Bytes()
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(public[1:33]),
+			Y:     new(big.Int).SetBytes(public[33:]),
+		},
+		D: new(big.Int).SetBytes(d),
+	}, nil
+}
+
+// vapidPublicKey returns the base64url encoded uncompressed public key of a VAPID key,
+// which browsers subscribe with as applicationServerKey.
+func vapidPublicKey(key *ecdsa.PrivateKey) string {
+	public := make([]byte, 65)
+	public[0] = 4
+	key.X.FillBytes(public[1:33])
+	key.Y.FillBytes(public[33:])
+	return base64.RawURLEncoding.EncodeToString(public)
+}
+
+// vapidAuthorization returns the Authorization header of RFC 8292 for a push service endpoint.
+func vapidAuthorization(key *ecdsa.PrivateKey, subject, endpoint string, now time.Time) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	jwt, err := signJWT(key, map[string]any{}, map[string]any{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": now.Add(12 * time.Hour).Unix(),
+		"sub": subject,
+	})
+	if err != nil {
+		return "", err
+	}
+	return "vapid t=" + jwt + ", k=" + vapidPublicKey(key), nil
+}
+
+// encryptWebPush encrypts a payload for a subscription as described in RFC 8291, using
+// a new ephemeral key and salt.
+func encryptWebPush(keys subscriptionKeys, plaintext []byte) ([]byte, error) {
+	ephemeral, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return encryptWebPushWith(keys, plaintext, ephemeral, salt)
+}
+
+// encryptWebPushWith encrypts a payload with the aes128gcm content coding of RFC 8188,
+// deriving the key from the ECDH secret of the ephemeral and the browser's key.
+func encryptWebPushWith(keys subscriptionKeys, plaintext []byte, ephemeral *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	if len(plaintext) > webPushMaxPayload {
+		return nil, fmt.Errorf("web push payload of %d bytes exceeds %d bytes", len(plaintext), webPushMaxPayload)
+	}
+	secret, err := ephemeral.ECDH(keys.public)
+	if err != nil {
+		return nil, err
+	}
+	serverPublic := ephemeral.PublicKey().Bytes()
+
+	// Combine the ECDH secret with the authentication secret.
+	keyInfo := append([]byte("WebPush: info\x00"), keys.public.Bytes()...)
+	keyInfo = append(keyInfo, serverPublic...)
+	ikm := hkdf(keys.auth, secret, keyInfo, 32)
+
+	// Derive the content encryption key and nonce.
+	cek := hkdf(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdf(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// The header holds the salt, record size and server key, followed by the single
+	// record ending with the delimiter of the last record.
+	body := make([]byte, 0, 16+4+1+len(serverPublic)+len(plaintext)+1+gcm.Overhead())
+	body = append(body, salt...)
+	body = binary.BigEndian.AppendUint32(body, webPushRecordSize)
+	body = append(body, byte(len(serverPublic)))
+	body = append(body, serverPublic...)
+	record := append(plaintext[:len(plaintext):len(plaintext)], 2)
+	return gcm.Seal(body, nonce, record, nil), nil
+}
+
+// hkdf derives a key of up to 32 bytes with HKDF-SHA-256 (RFC 5869).
+func hkdf(salt, ikm, info []byte, length int) []byte {
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(ikm)
+	expand := hmac.New(sha256.New, extract.Sum(nil))
+	expand.Write(info)
+	expand.Write([]byte{1})
+	return expand.Sum(nil)[:length]
+}
+
+// decodeBase64URL decodes base64url with or without padding, as browsers and key
+// generators differ.
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
@@ -0,0 +1,251 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// WebPushConfig holds the settings of the Web Push channel.
+type WebPushConfig struct {
+	// PrivateKey is the base64url encoded VAPID private key, as generated by the common
+	// Web Push libraries. Browsers subscribe with its public key.
+	PrivateKey string
+	// Subject is a mailto: or https: URL push services can contact the sender at.
+	Subject string
+	// TTL is how long push services keep messages for offline browsers, defaults to a day.
+	TTL time.Duration
+
+	// Name is the channel name, defaults to WebPush.
+	Name string
+}
+
+// WebPush represents a channel sending notifications to browsers with the Web Push
+// protocol. Recipients are user IDs, and messages are sent to all subscriptions
+// registered for the users.
+type WebPush struct {
+	config        WebPushConfig
+	name          string       // The name of the sender.
+	client        *http.Client // HTTP client for making requests.
+	subscriptions SubscriptionStore
+	key           *ecdsa.PrivateKey
+	now           func() time.Time // Clock used for the VAPID token expiry.
+}
+
+// WebPushPayload is the JSON payload the service worker of the site receives in its push event.
+type WebPushPayload struct {
+	Title    string            `json:"title,omitempty"`
+	Body     string            `json:"body,omitempty"`
+	Severity Severity          `json:"severity"`
+	Tag      string            `json:"tag,omitempty"` // Correlation key, for replacing earlier notifications.
+	Tags     []string          `json:"tags,omitempty"`
+	URL      string            `json:"url,omitempty"` // First link of the message.
+	Data     map[string]string `json:"data,omitempty"`
+}
+
+// NewWebPush creates a new Web Push channel instance. It fails if the VAPID key is invalid.
+func NewWebPush(config WebPushConfig, client *http.Client, subscriptions SubscriptionStore) (*WebPush, error) {
+	key, err := parseVAPIDKey(config.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if config.Subject == "" {
+		return nil, errors.New("vapid subject is required")
+	}
+	if config.TTL == 0 {
+		config.TTL = 24 * time.Hour
+	}
+	return &WebPush{
+		config:        config,
+		name:          senderName(config.Name, "WebPush"),
+		client:        client,
+		subscriptions: subscriptions,
+		key:           key,
+		now:           time.Now,
+	}, nil
+}
+
+// PublicKey returns the base64url encoded VAPID public key browsers subscribe with.
+func (w *WebPush) PublicKey() string {
+	return vapidPublicKey(w.key)
+}
+
+// Send sends a message to all subscriptions of its recipients. Subscriptions the push
+// service reports as expired are removed.
+func (w *WebPush) Send(ctx context.Context, message Message) error {
+	if len(message.Recipients) == 0 {
+		return Permanent(fmt.Errorf("%w: web push needs user ids as recipients", ErrNoSubscriptions))
+	}
+	payload, err := webPushPayload(message)
+	if err != nil {
+		return Permanent(err)
+	}
+	headers := webPushHeaders(message, w.config.TTL)
+
+	var errs []error
+	for _, userID := range message.Recipients {
+		if err := w.sendToUser(ctx, userID, payload, headers); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", userID, err))
+		}
+	}
+	if err := JoinErrors(errs...); err != nil {
+		return err
+	}
+	log.Printf("web push message sent: %s", message)
+
+	return nil
+}
+
+// GetName returns the name of the WebPush sender.
+func (w *WebPush) GetName() string {
+	return w.name
+}
+
+// sendToUser sends a payload to all subscriptions of a user. Retries skip the
+// subscriptions that already got it.
+func (w *WebPush) sendToUser(ctx context.Context, userID string, payload []byte, headers http.Header) error {
+	subscriptions, err := w.subscriptions.List(userID)
+	if err != nil {
+		return Retryable(err)
+	}
+	if len(subscriptions) == 0 {
+		return Permanent(ErrNoSubscriptions)
+	}
+
+	var errs []error
+	expired := 0
+	for _, subscription := range subscriptions {
+		var gone bool
+		err := sendOnce(ctx, subscription.Endpoint, func() (err error) {
+			gone, err = w.sendTo(ctx, subscription, payload, headers)
+			return err
+		})
+		if gone {
+			expired++
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if expired == len(subscriptions) {
+		return Permanent(fmt.Errorf("%w: all subscriptions expired", ErrNoSubscriptions))
+	}
+	return JoinErrors(errs...)
+}
+
+// sendTo sends an encrypted payload to a subscription. It reports whether the
+// subscription expired, in which case it is removed.
+func (w *WebPush) sendTo(ctx context.Context, subscription PushSubscription, payload []byte, headers http.Header) (bool, error) {
+	keys, err := subscription.keys()
+	if err != nil {
+		return false, Permanent(err)
+	}
+	body, err := encryptWebPush(keys, payload)
+	if err != nil {
+		return false, Permanent(err)
+	}
+	authorization, err := vapidAuthorization(w.key, w.config.Subject, subscription.Endpoint, w.now())
+	if err != nil {
+		return false, Permanent(err)
+	}
+
+	// Create the request with the encrypted payload.
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, Permanent(err)
+	}
+	for key, values := range headers {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Authorization", authorization)
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return false, Retryable(err)
+	}
+	defer resp.Body.Close()
+
+	// Remove subscriptions the browser unsubscribed from or that expired.
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		if err := w.subscriptions.Remove(subscription.UserID, subscription.Endpoint); err != nil && !errors.Is(err, ErrSubscriptionNotFound) {
+			log.Printf("error removing expired push subscription: %v", err)
+		}
+		log.Printf("removed expired push subscription of %s: %s", subscription.UserID, subscription.Endpoint)
+		return true, nil
+	}
+
+	// Classify failures so the notifier knows whether to retry.
+	return false, CheckResponse(resp)
+}
+
+// webPushPayload encodes a message for the service worker, shortening the body to fit
+// the payload size push services accept.
+func webPushPayload(message Message) ([]byte, error) {
+	payload := WebPushPayload{
+		Title:    message.Title,
+		Body:     message.Text(),
+		Severity: message.SeverityOrDefault(),
+		Tag:      message.CorrelationKey,
+		Tags:     message.Tags,
+	}
+	if len(message.Links) > 0 {
+		payload.URL = message.Links[0].URL
+	}
+	if message.Push != nil {
+		payload.Data = message.Push.Data
+	}
+
+	for {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		excess := len(data) - webPushMaxPayload
+		if excess <= 0 {
+			return data, nil
+		}
+		if payload.Body == "" {
+			return nil, fmt.Errorf("web push payload of %d bytes exceeds %d bytes", len(data), webPushMaxPayload)
+		}
+
+		// Shorten the body by the share of the excess its characters take, as escaped
+		// characters take more space in JSON, and check again.
+		runes := len([]rune(payload.Body))
+		encoded, _ := json.Marshal(payload.Body)
+		keep := runes - (excess*runes+len(encoded)-1)/len(encoded) - 1
+		if keep < 2 {
+			payload.Body = ""
+		} else {
+			payload.Body = truncate(payload.Body, keep)
+		}
+	}
+}
+
+// webPushHeaders returns the headers telling push services how to deliver a message.
+// Urgent messages wake up devices, and a pending message is replaced by a newer one with
+// the same correlation key. Topics are limited to 32 base64url characters, so the key is hashed.
+func webPushHeaders(message Message, ttl time.Duration) http.Header {
+	headers := http.Header{}
+	headers.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+	switch message.SeverityOrDefault() {
+	case SeverityError, SeverityCritical:
+		headers.Set("Urgency", "high")
+	default:
+		headers.Set("Urgency", "normal")
+	}
+	if message.CorrelationKey != "" {
+		sum := sha256.Sum256([]byte(message.CorrelationKey))
+		headers.Set("Topic", base64.RawURLEncoding.EncodeToString(sum[:])[:32])
+	}
+	return headers
+}
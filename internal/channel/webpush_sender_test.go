@@ -0,0 +1,182 @@
+package channel
+
+import (
+	"context"
+	"crypto/ecdh"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebPush_Send(t *testing.T) {
+	tests := []struct {
+		name            string
+		statuses        map[string]int // Response status by endpoint path.
+		expectErr       string
+		expectPermanent bool
+		expectLeft      int
+	}{
+		{
+			name:       "Sending to all subscriptions returns no error",
+			statuses:   map[string]int{"/laptop": http.StatusCreated, "/phone": http.StatusCreated},
+			expectLeft: 2,
+		},
+		{
+			name:       "Expired subscriptions are removed",
+			statuses:   map[string]int{"/laptop": http.StatusGone, "/phone": http.StatusCreated},
+			expectLeft: 1,
+		},
+		{
+			name:            "Sending when all subscriptions expired returns permanent error",
+			statuses:        map[string]int{"/laptop": http.StatusGone, "/phone": http.StatusNotFound},
+			expectErr:       "alice: no push subscriptions: all subscriptions expired",
+			expectPermanent: true,
+			expectLeft:      0,
+		},
+		{
+			name:       "Sending during an outage returns retryable error",
+			statuses:   map[string]int{"/laptop": http.StatusCreated, "/phone": http.StatusServiceUnavailable},
+			expectErr:  "alice: request failed: ",
+			expectLeft: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			browsers := map[string]*ecdh.PrivateKey{}
+			subscriptions := map[string]PushSubscription{}
+			var payloads []WebPushPayload
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "aes128gcm", req.Header.Get("Content-Encoding"))
+				assert.Equal(t, "86400", req.Header.Get("TTL"))
+				assert.Equal(t, "high", req.Header.Get("Urgency"))
+				assert.Len(t, req.Header.Get("Topic"), 32)
+				assert.True(t, strings.HasPrefix(req.Header.Get("Authorization"), "vapid t="))
+
+				// Decrypt the payload as the browser does
+				body, err := io.ReadAll(req.Body)
+				assert.NoError(t, err)
+				keys, err := subscriptions[req.URL.Path].keys()
+				assert.NoError(t, err)
+				plaintext, err := decryptWebPush(browsers[req.URL.Path], keys.auth, body)
+				assert.NoError(t, err)
+				var payload WebPushPayload
+				assert.NoError(t, json.Unmarshal(plaintext, &payload))
+				payloads = append(payloads, payload)
+				rw.WriteHeader(tt.statuses[req.URL.Path])
+			}))
+			defer server.Close()
+
+			store := NewMemorySubscriptionStore()
+			for _, path := range []string{"/laptop", "/phone"} {
+				browsers[path], subscriptions[path] = newTestSubscription(t, "alice", server.URL+path)
+				require.NoError(t, store.Add(subscriptions[path]))
+			}
+			w, err := NewWebPush(WebPushConfig{PrivateKey: newTestVAPIDKey(t), Subject: "mailto:ops@example.com"}, server.Client(), store)
+			require.NoError(t, err)
+
+			err = w.Send(context.Background(), Message{
+				Title:          "Disk full",
+				Body:           "Disk is full",
+				Severity:       SeverityCritical,
+				CorrelationKey: "disk",
+				Links:          []Link{{URL: "https://status.example.com"}},
+				Recipients:     []string{"alice"},
+			})
+			require.Len(t, payloads, 2)
+			assert.Equal(t, WebPushPayload{Title: "Disk full", Body: "Disk is full", Severity: SeverityCritical, Tag: "disk", URL: "https://status.example.com"}, payloads[0])
+			left, _ := store.List("alice")
+			assert.Len(t, left, tt.expectLeft)
+			if tt.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.expectErr)
+			assert.Equal(t, tt.expectPermanent, IsPermanent(err))
+		})
+	}
+}
+
+func TestWebPush_SendProgress(t *testing.T) {
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		sent = append(sent, req.URL.Path)
+
+		// The phone is unreachable once
+		if req.URL.Path == "/phone" && len(sent) == 2 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	store := NewMemorySubscriptionStore()
+	for _, path := range []string{"/laptop", "/phone"} {
+		_, subscription := newTestSubscription(t, "alice", server.URL+path)
+		require.NoError(t, store.Add(subscription))
+	}
+	w, err := NewWebPush(WebPushConfig{PrivateKey: newTestVAPIDKey(t), Subject: "mailto:ops@example.com"}, server.Client(), store)
+	require.NoError(t, err)
+	progress := NewProgress(nil)
+	ctx := WithProgress(context.Background(), progress)
+	message := Message{Body: "Hello", Recipients: []string{"alice"}}
+
+	err = w.Send(ctx, message)
+	assert.Error(t, err)
+	assert.False(t, IsPermanent(err))
+	assert.Equal(t, []string{server.URL + "/laptop"}, progress.Targets())
+
+	// The retry skips the subscription that already got the message
+	assert.NoError(t, w.Send(ctx, message))
+	assert.Equal(t, []string{"/laptop", "/phone", "/phone"}, sent)
+}
+
+func TestWebPush_SendWithoutSubscriptions(t *testing.T) {
+	w, err := NewWebPush(WebPushConfig{PrivateKey: newTestVAPIDKey(t), Subject: "mailto:ops@example.com"}, http.DefaultClient, NewMemorySubscriptionStore())
+	require.NoError(t, err)
+
+	// Messages need users with subscriptions
+	err = w.Send(context.Background(), Message{Body: "Hello"})
+	assert.ErrorIs(t, err, ErrNoSubscriptions)
+	assert.True(t, IsPermanent(err))
+	err = w.Send(context.Background(), Message{Body: "Hello", Recipients: []string{"bob"}})
+	assert.EqualError(t, err, "bob: no push subscriptions")
+	assert.True(t, IsPermanent(err))
+}
+
+func TestWebPushPayload(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{
+			name: "Long body is shortened",
+			body: strings.Repeat("a", 5000),
+		},
+		{
+			name: "Long body with escaped characters is shortened",
+			body: strings.Repeat("\"ü", 3000),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := webPushPayload(Message{Title: "Log", Body: tt.body})
+			assert.NoError(t, err)
+			assert.LessOrEqual(t, len(payload), webPushMaxPayload)
+
+			var decoded WebPushPayload
+			assert.NoError(t, json.Unmarshal(payload, &decoded))
+			assert.True(t, strings.HasSuffix(decoded.Body, "…"))
+			assert.True(t, utf8.ValidString(decoded.Body))
+		})
+	}
+}
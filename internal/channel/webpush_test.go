@@ -0,0 +1,200 @@
+package channel
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptWebPush(t *testing.T) {
+	// Example of RFC 8291, appendix A.
+	decode := func(s string) []byte {
+		b, err := decodeBase64URL(s)
+		require.NoError(t, err)
+		return b
+	}
+	ephemeral, err := ecdh.P256().NewPrivateKey(decode("yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"))
+	require.NoError(t, err)
+	subscription := PushSubscription{Keys: PushSubscriptionKeys{
+		P256dh: "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4",
+		Auth:   "BTBZMqHH6r4Tts7J_aSIgg",
+	}}
+	keys, err := subscription.keys()
+	require.NoError(t, err)
+
+	body, err := encryptWebPushWith(keys, []byte("When I grow up, I want to be a watermelon"), ephemeral, decode("DGv6ra1nlYgDCS1FRnbzlw"))
+	assert.NoError(t, err)
+	assert.Equal(t, "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN",
+		base64.RawURLEncoding.EncodeToString(body))
+
+	// The browser decrypts the payload with its private key
+	browser, err := ecdh.P256().NewPrivateKey(decode("q1dXpw3UpT5VOmu_cf_v6ih07Aems3njxI-JWgLcM94"))
+	require.NoError(t, err)
+	plaintext, err := decryptWebPush(browser, keys.auth, body)
+	assert.NoError(t, err)
+	assert.Equal(t, "When I grow up, I want to be a watermelon", string(plaintext))
+
+	// Payloads larger than a record are rejected
+	_, err = encryptWebPush(keys, make([]byte, webPushMaxPayload+1))
+	assert.Error(t, err)
+}
+
+func TestPushSubscription_Validate(t *testing.T) {
+	_, subscription := newTestSubscription(t, "alice", "https://push.example.com/send/1")
+
+	tests := []struct {
+		name      string
+		change    func(s *PushSubscription)
+		expectErr bool
+	}{
+		{
+			name:   "Subscription from a browser is valid",
+			change: func(s *PushSubscription) {},
+		},
+		{
+			name:   "Padded keys are valid",
+			change: func(s *PushSubscription) { s.Keys.Auth += "==" },
+		},
+		{
+			name:      "Subscription without user is invalid",
+			change:    func(s *PushSubscription) { s.UserID = "" },
+			expectErr: true,
+		},
+		{
+			name:      "Plain HTTP endpoint is invalid",
+			change:    func(s *PushSubscription) { s.Endpoint = "http://push.example.com/send/1" },
+			expectErr: true,
+		},
+		{
+			name:      "Public key that is not on the curve is invalid",
+			change:    func(s *PushSubscription) { s.Keys.P256dh = base64.RawURLEncoding.EncodeToString(make([]byte, 65)) },
+			expectErr: true,
+		},
+		{
+			name:      "Short authentication secret is invalid",
+			change:    func(s *PushSubscription) { s.Keys.Auth = "c2hvcnQ" },
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := subscription
+			tt.change(&s)
+			err := s.Validate()
+			if tt.expectErr {
+				assert.ErrorIs(t, err, ErrInvalidSubscription)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMemorySubscriptionStore(t *testing.T) {
+	store := NewMemorySubscriptionStore()
+	assert.NoError(t, store.Add(PushSubscription{UserID: "alice", Endpoint: "https://push.example.com/2"}))
+	assert.NoError(t, store.Add(PushSubscription{UserID: "alice", Endpoint: "https://push.example.com/1"}))
+	assert.NoError(t, store.Add(PushSubscription{UserID: "alicia", Endpoint: "https://push.example.com/3"}))
+
+	// List returns the subscriptions of the user only, ordered by endpoint
+	subscriptions, err := store.List("alice")
+	assert.NoError(t, err)
+	require.Len(t, subscriptions, 2)
+	assert.Equal(t, "https://push.example.com/1", subscriptions[0].Endpoint)
+	assert.Equal(t, "https://push.example.com/2", subscriptions[1].Endpoint)
+
+	assert.NoError(t, store.Remove("alice", "https://push.example.com/1"))
+	assert.Equal(t, ErrSubscriptionNotFound, store.Remove("alice", "https://push.example.com/1"))
+	subscriptions, err = store.List("alice")
+	assert.NoError(t, err)
+	assert.Len(t, subscriptions, 1)
+}
+
+func TestVAPIDAuthorization(t *testing.T) {
+	key, err := parseVAPIDKey(newTestVAPIDKey(t))
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+
+	header, err := vapidAuthorization(key, "mailto:ops@example.com", "https://push.example.com/send/1?x=1", now)
+	assert.NoError(t, err)
+
+	// The token is signed for the origin of the push service and carries the public key
+	token, publicKey, found := strings.Cut(strings.TrimPrefix(header, "vapid t="), ", k=")
+	require.True(t, found)
+	assert.Equal(t, vapidPublicKey(key), publicKey)
+	_, claims := decodeJWT(t, token)
+	assert.Equal(t, "https://push.example.com", claims["aud"])
+	assert.Equal(t, "mailto:ops@example.com", claims["sub"])
+	assert.Equal(t, float64(now.Add(12*time.Hour).Unix()), claims["exp"])
+	assert.True(t, verifyJWT(t, key.Public(), token))
+}
+
+func TestParseVAPIDKey(t *testing.T) {
+	_, err := parseVAPIDKey("not a key")
+	assert.Error(t, err)
+	_, err = parseVAPIDKey(base64.RawURLEncoding.EncodeToString(make([]byte, 32)))
+	assert.Error(t, err)
+}
+
+// newTestVAPIDKey returns a new base64url encoded VAPID private key.
+func newTestVAPIDKey(t *testing.T) string {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(key.Bytes())
+}
+
+// newTestSubscription returns the private key of a browser and its subscription.
+func newTestSubscription(t *testing.T, userID, endpoint string) (*ecdh.PrivateKey, PushSubscription) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	auth := make([]byte, 16)
+	_, err = rand.Read(auth)
+	require.NoError(t, err)
+	return key, PushSubscription{
+		UserID:   userID,
+		Endpoint: endpoint,
+		Keys: PushSubscriptionKeys{
+			P256dh: base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+			Auth:   base64.RawURLEncoding.EncodeToString(auth),
+		},
+	}
+}
+
+// decryptWebPush decrypts a single record payload as a browser does.
+func decryptWebPush(key *ecdh.PrivateKey, auth, body []byte) ([]byte, error) {
+	salt, keyLength := body[:16], int(body[20])
+	serverKey, err := ecdh.P256().NewPublicKey(body[21 : 21+keyLength])
+	if err != nil {
+		return nil, err
+	}
+	secret, err := key.ECDH(serverKey)
+	if err != nil {
+		return nil, err
+	}
+	keyInfo := append([]byte("WebPush: info\x00"), key.PublicKey().Bytes()...)
+	keyInfo = append(keyInfo, serverKey.Bytes()...)
+	ikm := hkdf(auth, secret, keyInfo, 32)
+	block, err := aes.NewCipher(hkdf(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16))
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, hkdf(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12), body[21+keyLength:], nil)
+	if err != nil {
+		return nil, err
+	}
+	// Strip the padding delimiter of the last record.
+	return plaintext[:len(plaintext)-1], nil
+}
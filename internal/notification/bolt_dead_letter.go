@@ -0,0 +1,93 @@
+package notification
+
+import (
+	"encoding/json"
+	"sort"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	deadLetterBucket = []byte("dead_letters") // Notifications that failed permanently.
+)
+
+// BoltDeadLetterStore is a DeadLetterStore kept in the same BoltDB file as a BoltQueue.
+type BoltDeadLetterStore struct {
+	db *bolt.DB
+}
+
+// DeadLetters returns a DeadLetterStore persisted alongside the queue.
+func (q *BoltQueue) DeadLetters() *BoltDeadLetterStore {
+	return &BoltDeadLetterStore{db: q.db}
+}
+
+// Add stores a dead letter.
+func (s *BoltDeadLetterStore) Add(letter DeadLetter) error {
+	value, err := json.Marshal(letter)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(deadLetterBucket).Put([]byte(letter.ID), value)
+	})
+}
+
+// List returns all dead letters, oldest first.
+func (s *BoltDeadLetterStore) List() ([]DeadLetter, error) {
+	letters := []DeadLetter{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(deadLetterBucket).ForEach(func(_, v []byte) error {
+			var letter DeadLetter
+			if err := json.Unmarshal(v, &letter); err != nil {
+				return err
+			}
+			letters = append(letters, letter)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(letters, func(i, j int) bool {
+		return letters[i].FailedAt.Before(letters[j].FailedAt)
+	})
+	return letters, nil
+}
+
+// Get returns a dead letter by its ID.
+func (s *BoltDeadLetterStore) Get(id string) (DeadLetter, error) {
+	var letter DeadLetter
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(deadLetterBucket).Get([]byte(id))
+		if v == nil {
+			return ErrDeadLetterNotFound
+		}
+		return json.Unmarshal(v, &letter)
+	})
+	return letter, err
+}
+
+// Delete removes a dead letter by its ID.
+func (s *BoltDeadLetterStore) Delete(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(deadLetterBucket)
+		if bucket.Get([]byte(id)) == nil {
+			return ErrDeadLetterNotFound
+		}
+		return bucket.Delete([]byte(id))
+	})
+}
+
+// Purge removes all dead letters.
+func (s *BoltDeadLetterStore) Purge() (int, error) {
+	var count int
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		count = tx.Bucket(deadLetterBucket).Stats().KeyN
+		if err := tx.DeleteBucket(deadLetterBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucket(deadLetterBucket)
+		return err
+	})
+	return count, err
+}
@@ -0,0 +1,114 @@
+package notification
+
+import (
+	"encoding/json"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	inboxBucket      = []byte("inbox") // Nested bucket of each user's inbox.
+	inboxItemsBucket = []byte("items") // Items of a user keyed by sequence, oldest first.
+	inboxIDsBucket   = []byte("ids")   // Sequence keys of a user's items by item ID.
+)
+
+// BoltInboxStore is a channel.InboxStore kept in the same BoltDB file as a BoltQueue.
+type BoltInboxStore struct {
+	db *bolt.DB
+}
+
+// Inbox returns an InboxStore persisted alongside the queue.
+func (q *BoltQueue) Inbox() *BoltInboxStore {
+	return &BoltInboxStore{db: q.db}
+}
+
+// Add stores a new item unless the user already has an item with the ID.
+func (s *BoltInboxStore) Add(item channel.InboxItem) (bool, error) {
+	value, err := json.Marshal(item)
+	if err != nil {
+		return false, err
+	}
+	added := false
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		user, err := tx.Bucket(inboxBucket).CreateBucketIfNotExists([]byte(item.UserID))
+		if err != nil {
+			return err
+		}
+		items, err := user.CreateBucketIfNotExists(inboxItemsBucket)
+		if err != nil {
+			return err
+		}
+		ids, err := user.CreateBucketIfNotExists(inboxIDsBucket)
+		if err != nil {
+			return err
+		}
+		if ids.Get([]byte(item.ID)) != nil {
+			return nil
+		}
+
+		seq, err := items.NextSequence()
+		if err != nil {
+			return err
+		}
+		if err := items.Put(itob(seq), value); err != nil {
+			return err
+		}
+		added = true
+		return ids.Put([]byte(item.ID), itob(seq))
+	})
+	return added, err
+}
+
+// List returns the items of a user matching the filter.
+func (s *BoltInboxStore) List(userID string, filter channel.InboxFilter) (channel.InboxPage, error) {
+	var all []channel.InboxItem
+	err := s.db.View(func(tx *bolt.Tx) error {
+		user := tx.Bucket(inboxBucket).Bucket([]byte(userID))
+		if user == nil {
+			return nil
+		}
+		// Keys are iterated in byte order, which is the order the items were added in.
+		return user.Bucket(inboxItemsBucket).ForEach(func(_, v []byte) error {
+			var item channel.InboxItem
+			if err := json.Unmarshal(v, &item); err != nil {
+				return err
+			}
+			all = append(all, item)
+			return nil
+		})
+	})
+	if err != nil {
+		return channel.InboxPage{}, err
+	}
+	return channel.FilterInbox(all, filter), nil
+}
+
+// Update changes an item of a user.
+func (s *BoltInboxStore) Update(userID, id string, update func(item *channel.InboxItem)) (channel.InboxItem, error) {
+	var item channel.InboxItem
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		user := tx.Bucket(inboxBucket).Bucket([]byte(userID))
+		if user == nil {
+			return channel.ErrInboxItemNotFound
+		}
+		key := user.Bucket(inboxIDsBucket).Get([]byte(id))
+		if key == nil {
+			return channel.ErrInboxItemNotFound
+		}
+		items := user.Bucket(inboxItemsBucket)
+		if err := json.Unmarshal(items.Get(key), &item); err != nil {
+			return err
+		}
+		update(&item)
+		value, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		return items.Put(key, value)
+	})
+	if err != nil {
+		return channel.InboxItem{}, err
+	}
+	return item, nil
+}
@@ -0,0 +1,224 @@
+package notification
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	pendingBucket  = []byte("pending")  // Notifications waiting to be processed.
+	inflightBucket = []byte("inflight") // Notifications handed out but not yet acknowledged.
+)
+
+// BoltQueue is a disk-backed Queue stored in a BoltDB file.
+// Notifications are persisted before Enqueue returns and stay in the file until
+// they are acknowledged, so unfinished work is resumed after a restart or crash.
+type BoltQueue struct {
+	db        *bolt.DB
+	ready     chan struct{} // Signalled when notifications may be available.
+	closed    chan struct{} // Closed when the queue is closed.
+	closeOnce sync.Once
+}
+
+// NewBoltQueue opens or creates the queue file at path. Notifications that were
+// dequeued but never acknowledged by a previous process are made available again.
+func NewBoltQueue(path string) (*BoltQueue, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		pending, err := tx.CreateBucketIfNotExists(pendingBucket)
+		if err != nil {
+			return err
+		}
+		inflight, err := tx.CreateBucketIfNotExists(inflightBucket)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(deadLetterBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(templateBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(tokenBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(subscriptionBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(inboxBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(statusBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(statusOrderBucket); err != nil {
+			return err
+		}
+
+		// Move unfinished notifications back to pending, keeping their original order.
+		var keys [][]byte
+		err = inflight.ForEach(func(k, v []byte) error {
+			keys = append(keys, k)
+			return pending.Put(k, v)
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if err := inflight.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	q := &BoltQueue{
+		db:     db,
+		ready:  make(chan struct{}, 1),
+		closed: make(chan struct{}),
+	}
+	q.signal()
+	return q, nil
+}
+
+// Enqueue persists the notifications in a single transaction.
+func (q *BoltQueue) Enqueue(notifications []Notification) error {
+	if q.isClosed() {
+		return ErrQueueClosed
+	}
+	err := q.db.Update(func(tx *bolt.Tx) error {
+		pending := tx.Bucket(pendingBucket)
+		for _, notification := range notifications {
+			seq, err := pending.NextSequence()
+			if err != nil {
+				return err
+			}
+			value, err := json.Marshal(notification)
+			if err != nil {
+				return err
+			}
+			if err := pending.Put(itob(seq), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	q.signal()
+	return nil
+}
+
+// Dequeue moves the oldest pending notification to the in-flight bucket and returns it,
+// blocking until one is available, ctx is done or the queue is closed. Pending
+// notifications are not drained once ctx is done as they are kept on disk.
+func (q *BoltQueue) Dequeue(ctx context.Context) (QueuedNotification, error) {
+	for {
+		if q.isClosed() {
+			return QueuedNotification{}, ErrQueueClosed
+		}
+		if err := ctx.Err(); err != nil {
+			return QueuedNotification{}, err
+		}
+
+		item, found, err := q.pop()
+		if err != nil {
+			if q.isClosed() {
+				return QueuedNotification{}, ErrQueueClosed
+			}
+			return QueuedNotification{}, err
+		}
+		if found {
+			// Wake up another worker in case more notifications are pending.
+			q.signal()
+			return item, nil
+		}
+
+		select {
+		case <-q.ready:
+		case <-q.closed:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// Ack removes a processed notification from the in-flight bucket.
+func (q *BoltQueue) Ack(item QueuedNotification) error {
+	if q.isClosed() {
+		return ErrQueueClosed
+	}
+	return q.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(inflightBucket).Delete(itob(item.Receipt))
+	})
+}
+
+// Close stops handing out notifications and closes the underlying file.
+// Pending and unacknowledged notifications remain stored for the next start.
+func (q *BoltQueue) Close() error {
+	var err error
+	q.closeOnce.Do(func() {
+		close(q.closed)
+		err = q.db.Close()
+	})
+	return err
+}
+
+// pop moves the first pending notification to the in-flight bucket.
+func (q *BoltQueue) pop() (item QueuedNotification, found bool, err error) {
+	err = q.db.Update(func(tx *bolt.Tx) error {
+		pending := tx.Bucket(pendingBucket)
+		k, v := pending.Cursor().First()
+		if k == nil {
+			return nil
+		}
+		if err := json.Unmarshal(v, &item.Notification); err != nil {
+			return err
+		}
+		if err := tx.Bucket(inflightBucket).Put(k, v); err != nil {
+			return err
+		}
+		item.Receipt = binary.BigEndian.Uint64(k)
+		found = true
+		return pending.Delete(k)
+	})
+	return item, found, err
+}
+
+// signal wakes up a waiting Dequeue call without blocking.
+func (q *BoltQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// isClosed reports whether Close has been called.
+func (q *BoltQueue) isClosed() bool {
+	select {
+	case <-q.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// itob encodes a sequence number as a big-endian key so keys sort in insertion order.
+func itob(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
@@ -0,0 +1,124 @@
+package notification
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoltQueue_Order(t *testing.T) {
+	q, err := NewBoltQueue(filepath.Join(t.TempDir(), "queue.db"))
+	require.NoError(t, err)
+	defer q.Close()
+
+	notifications := []Notification{
+		{Channel: "foo", Message: channel.Message{Body: "first"}},
+		{Channel: "bar", Message: channel.Message{Body: "second"}},
+		{Channel: "baz", Message: channel.Message{Body: "third"}},
+	}
+	require.NoError(t, q.Enqueue(notifications))
+
+	// Notifications are handed out in the order they were enqueued
+	for _, expected := range notifications {
+		item, err := q.Dequeue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, expected, item.Notification)
+		assert.NoError(t, q.Ack(item))
+	}
+}
+
+func TestBoltQueue_ResumesUnfinishedWork(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.db")
+
+	// Enqueue three notifications, finish one and leave one in flight
+	q, err := NewBoltQueue(path)
+	require.NoError(t, err)
+	require.NoError(t, q.Enqueue([]Notification{
+		{Channel: "foo", Message: channel.Message{Body: "acked"}},
+		{Channel: "foo", Message: channel.Message{Body: "in flight"}},
+		{Channel: "foo", Message: channel.Message{Body: "pending"}},
+	}))
+	acked, err := q.Dequeue(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, q.Ack(acked))
+	_, err = q.Dequeue(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, q.Close())
+
+	// Reopen the queue as if the process restarted
+	q, err = NewBoltQueue(path)
+	require.NoError(t, err)
+	defer q.Close()
+
+	var messages []string
+	for i := 0; i < 2; i++ {
+		item, err := q.Dequeue(context.Background())
+		require.NoError(t, err)
+		messages = append(messages, item.Message.Body)
+	}
+	assert.Equal(t, []string{"in flight", "pending"}, messages)
+}
+
+func TestBoltQueue_DequeueBlocksUntilEnqueue(t *testing.T) {
+	q, err := NewBoltQueue(filepath.Join(t.TempDir(), "queue.db"))
+	require.NoError(t, err)
+	defer q.Close()
+
+	result := make(chan QueuedNotification)
+	go func() {
+		item, err := q.Dequeue(context.Background())
+		if err == nil {
+			result <- item
+		}
+	}()
+
+	// Enqueue a notification after the worker started waiting
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, q.Enqueue([]Notification{{Channel: "foo", Message: channel.Message{Body: "hello"}}}))
+
+	select {
+	case item := <-result:
+		assert.Equal(t, "hello", item.Message.Body)
+	case <-time.After(time.Second):
+		t.Fatal("dequeue did not return after enqueue")
+	}
+}
+
+func TestBoltQueue_Close(t *testing.T) {
+	q, err := NewBoltQueue(filepath.Join(t.TempDir(), "queue.db"))
+	require.NoError(t, err)
+
+	errs := make(chan error)
+	go func() {
+		_, err := q.Dequeue(context.Background())
+		errs <- err
+	}()
+
+	// Closing the queue releases waiting workers
+	require.NoError(t, q.Close())
+	select {
+	case err := <-errs:
+		assert.Equal(t, ErrQueueClosed, err)
+	case <-time.After(time.Second):
+		t.Fatal("dequeue did not return after close")
+	}
+	assert.Equal(t, ErrQueueClosed, q.Enqueue([]Notification{{Channel: "foo"}}))
+}
+
+func TestBoltQueue_DequeueStopsWhenContextIsDone(t *testing.T) {
+	q, err := NewBoltQueue(filepath.Join(t.TempDir(), "queue.db"))
+	require.NoError(t, err)
+	defer q.Close()
+
+	// Pending notifications stay on disk instead of being drained
+	require.NoError(t, q.Enqueue([]Notification{{Channel: "foo", Message: channel.Message{Body: "hello"}}}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = q.Dequeue(ctx)
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,118 @@
+package notification
+
+import (
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	statusBucket      = []byte("statuses")     // Notification statuses keyed by ID, its sequence counts them.
+	statusOrderBucket = []byte("status_order") // Notification IDs keyed by creation sequence.
+)
+
+// BoltStatusStore is a StatusStore kept in the same BoltDB file as a BoltQueue,
+// keeping the most recent notifications.
+type BoltStatusStore struct {
+	db    *bolt.DB
+	limit int
+}
+
+// Statuses returns a StatusStore persisted alongside the queue remembering up to limit
+// notifications, so that the status of notifications resumed after a restart can still
+// be followed.
+func (q *BoltQueue) Statuses(limit int) *BoltStatusStore {
+	return &BoltStatusStore{db: q.db, limit: limit}
+}
+
+// Create stores the initial status of a notification, evicting the oldest ones when full.
+func (s *BoltStatusStore) Create(status NotificationStatus) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return s.put(tx, status)
+	})
+}
+
+// Update applies fn to the status of a notification on a channel.
+func (s *BoltStatusStore) Update(id, channel string, fn func(status *ChannelStatus)) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		// Statuses of notifications may have been evicted while they were queued.
+		status := NotificationStatus{ID: id, CreatedAt: time.Now()}
+		if v := tx.Bucket(statusBucket).Get([]byte(id)); v != nil {
+			if err := json.Unmarshal(v, &status); err != nil {
+				return err
+			}
+		}
+		status.update(channel, fn)
+		return s.put(tx, status)
+	})
+}
+
+// Get returns the status of a notification.
+func (s *BoltStatusStore) Get(id string) (NotificationStatus, error) {
+	var status NotificationStatus
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(statusBucket).Get([]byte(id))
+		if v == nil {
+			return ErrNotificationNotFound
+		}
+		return json.Unmarshal(v, &status)
+	})
+	return status, err
+}
+
+// Delete removes the status of a notification. Its entry in the creation order is
+// left to the eviction.
+func (s *BoltStatusStore) Delete(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return deleteStatus(tx.Bucket(statusBucket), []byte(id))
+	})
+}
+
+// put stores a status, recording the creation order of new ones and evicting the
+// oldest ones above the limit.
+func (s *BoltStatusStore) put(tx *bolt.Tx, status NotificationStatus) error {
+	value, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	statuses, order := tx.Bucket(statusBucket), tx.Bucket(statusOrderBucket)
+	if statuses.Get([]byte(status.ID)) == nil {
+		seq, err := order.NextSequence()
+		if err != nil {
+			return err
+		}
+		if err := order.Put(itob(seq), []byte(status.ID)); err != nil {
+			return err
+		}
+		if err := statuses.SetSequence(statuses.Sequence() + 1); err != nil {
+			return err
+		}
+	}
+	if err := statuses.Put([]byte(status.ID), value); err != nil {
+		return err
+	}
+
+	// Entries of deleted statuses are skipped, they no longer count towards the limit.
+	cursor := order.Cursor()
+	for k, id := cursor.First(); k != nil && s.limit > 0 && statuses.Sequence() > uint64(s.limit); k, id = cursor.First() {
+		if err := deleteStatus(statuses, id); err != nil {
+			return err
+		}
+		if err := order.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteStatus removes a status from the statuses bucket, keeping count of them.
+func deleteStatus(statuses *bolt.Bucket, id []byte) error {
+	if statuses.Get(id) == nil {
+		return nil
+	}
+	if err := statuses.Delete(id); err != nil {
+		return err
+	}
+	return statuses.SetSequence(statuses.Sequence() - 1)
+}
@@ -0,0 +1,70 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	subscriptionBucket = []byte("push_subscriptions") // Web Push subscriptions keyed by user and endpoint.
+)
+
+// BoltSubscriptionStore is a channel.SubscriptionStore kept in the same BoltDB file as a BoltQueue.
+type BoltSubscriptionStore struct {
+	db *bolt.DB
+}
+
+// Subscriptions returns a SubscriptionStore persisted alongside the queue, so that
+// browsers stay subscribed after a restart.
+func (q *BoltQueue) Subscriptions() *BoltSubscriptionStore {
+	return &BoltSubscriptionStore{db: q.db}
+}
+
+// Add registers a subscription, replacing one of the user with the same endpoint.
+func (s *BoltSubscriptionStore) Add(subscription channel.PushSubscription) error {
+	value, err := json.Marshal(subscription)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		key := channel.SubscriptionKey(subscription.UserID, subscription.Endpoint)
+		return tx.Bucket(subscriptionBucket).Put([]byte(key), value)
+	})
+}
+
+// List returns the subscriptions of a user ordered by endpoint.
+func (s *BoltSubscriptionStore) List(userID string) ([]channel.PushSubscription, error) {
+	subscriptions := []channel.PushSubscription{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		// Keys start with the user ID, so the user's subscriptions follow each other.
+		prefix := []byte(channel.SubscriptionKey(userID, ""))
+		c := tx.Bucket(subscriptionBucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var subscription channel.PushSubscription
+			if err := json.Unmarshal(v, &subscription); err != nil {
+				return err
+			}
+			subscriptions = append(subscriptions, subscription)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// Remove unregisters a subscription.
+func (s *BoltSubscriptionStore) Remove(userID, endpoint string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(subscriptionBucket)
+		key := []byte(channel.SubscriptionKey(userID, endpoint))
+		if bucket.Get(key) == nil {
+			return channel.ErrSubscriptionNotFound
+		}
+		return bucket.Delete(key)
+	})
+}
@@ -0,0 +1,96 @@
+package notification
+
+import (
+	"encoding/json"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	templateBucket = []byte("templates") // Message templates keyed by name.
+)
+
+// BoltTemplateStore is a TemplateStore kept in the same BoltDB file as a BoltQueue.
+type BoltTemplateStore struct {
+	db *bolt.DB
+}
+
+// Templates returns a TemplateStore persisted alongside the queue, so that templated
+// notifications still waiting in the queue can be rendered after a restart.
+func (q *BoltQueue) Templates() *BoltTemplateStore {
+	return &BoltTemplateStore{db: q.db}
+}
+
+// Create stores a new template.
+func (s *BoltTemplateStore) Create(t Template) error {
+	value, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(templateBucket)
+		if bucket.Get([]byte(t.Name)) != nil {
+			return ErrTemplateExists
+		}
+		return bucket.Put([]byte(t.Name), value)
+	})
+}
+
+// Update replaces an existing template.
+func (s *BoltTemplateStore) Update(t Template) error {
+	value, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(templateBucket)
+		if bucket.Get([]byte(t.Name)) == nil {
+			return ErrTemplateNotFound
+		}
+		return bucket.Put([]byte(t.Name), value)
+	})
+}
+
+// Get returns a template by its name.
+func (s *BoltTemplateStore) Get(name string) (Template, error) {
+	var t Template
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(templateBucket).Get([]byte(name))
+		if v == nil {
+			return ErrTemplateNotFound
+		}
+		return json.Unmarshal(v, &t)
+	})
+	return t, err
+}
+
+// List returns all templates ordered by name.
+func (s *BoltTemplateStore) List() ([]Template, error) {
+	templates := []Template{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		// Keys are iterated in byte order, which is the order of the names.
+		return tx.Bucket(templateBucket).ForEach(func(_, v []byte) error {
+			var t Template
+			if err := json.Unmarshal(v, &t); err != nil {
+				return err
+			}
+			templates = append(templates, t)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// Delete removes a template by its name.
+func (s *BoltTemplateStore) Delete(name string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(templateBucket)
+		if bucket.Get([]byte(name)) == nil {
+			return ErrTemplateNotFound
+		}
+		return bucket.Delete([]byte(name))
+	})
+}
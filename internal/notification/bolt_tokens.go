@@ -0,0 +1,71 @@
+package notification
+
+import (
+	"encoding/json"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	tokenBucket = []byte("invalid_tokens") // Invalidated device tokens keyed by channel and token.
+)
+
+// BoltTokenStore is a channel.TokenStore kept in the same BoltDB file as a BoltQueue.
+type BoltTokenStore struct {
+	db *bolt.DB
+}
+
+// Tokens returns a TokenStore persisted alongside the queue, so that device tokens
+// stay invalidated after a restart.
+func (q *BoltQueue) Tokens() *BoltTokenStore {
+	return &BoltTokenStore{db: q.db}
+}
+
+// Invalidate records a device token as invalid.
+func (s *BoltTokenStore) Invalidate(token channel.InvalidToken) error {
+	value, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(tokenBucket).Put([]byte(channel.TokenKey(token.Channel, token.Token)), value)
+	})
+}
+
+// IsInvalid reports whether a device token of a channel was invalidated.
+func (s *BoltTokenStore) IsInvalid(channelName, token string) (bool, error) {
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		found = tx.Bucket(tokenBucket).Get([]byte(channel.TokenKey(channelName, token))) != nil
+		return nil
+	})
+	return found, err
+}
+
+// List returns all invalidated tokens ordered by channel and token.
+func (s *BoltTokenStore) List() ([]channel.InvalidToken, error) {
+	tokens := []channel.InvalidToken{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		// Keys are iterated in byte order, which is the order of the channels and tokens.
+		return tx.Bucket(tokenBucket).ForEach(func(_, v []byte) error {
+			var token channel.InvalidToken
+			if err := json.Unmarshal(v, &token); err != nil {
+				return err
+			}
+			tokens = append(tokens, token)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// Delete forgets an invalidated token.
+func (s *BoltTokenStore) Delete(channelName, token string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(tokenBucket).Delete([]byte(channel.TokenKey(channelName, token)))
+	})
+}
@@ -0,0 +1,206 @@
+package notification
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+)
+
+// DeadLetter is a notification that could not be delivered after all attempts.
+type DeadLetter struct {
+	ID           string       `json:"id"`
+	Notification Notification `json:"notification"`
+	Attempts     int          `json:"attempts"`
+	Error        string       `json:"error"`
+	FailedAt     time.Time    `json:"failed_at"`
+}
+
+// DeadLetterStore keeps notifications that failed permanently so they can be inspected and replayed.
+type DeadLetterStore interface {
+	// Add stores a dead letter.
+	Add(letter DeadLetter) error
+	// List returns all dead letters, oldest first.
+	List() ([]DeadLetter, error)
+	// Get returns a dead letter or ErrDeadLetterNotFound.
+	Get(id string) (DeadLetter, error)
+	// Delete removes a dead letter or returns ErrDeadLetterNotFound.
+	Delete(id string) error
+	// Purge removes all dead letters and returns how many were removed.
+	Purge() (int, error)
+}
+
+// MemoryDeadLetterStore is an in-memory DeadLetterStore. Its contents are lost when the process exits.
+type MemoryDeadLetterStore struct {
+	mu      sync.RWMutex
+	letters map[string]DeadLetter
+}
+
+// NewMemoryDeadLetterStore creates a new MemoryDeadLetterStore.
+func NewMemoryDeadLetterStore() *MemoryDeadLetterStore {
+	return &MemoryDeadLetterStore{
+		letters: make(map[string]DeadLetter),
+	}
+}
+
+// Add stores a dead letter.
+func (s *MemoryDeadLetterStore) Add(letter DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.letters[letter.ID] = letter
+	return nil
+}
+
+// List returns all dead letters, oldest first.
+func (s *MemoryDeadLetterStore) List() ([]DeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	letters := make([]DeadLetter, 0, len(s.letters))
+	for _, letter := range s.letters {
+		letters = append(letters, letter)
+	}
+	sort.Slice(letters, func(i, j int) bool {
+		return letters[i].FailedAt.Before(letters[j].FailedAt)
+	})
+	return letters, nil
+}
+
+// Get returns a dead letter by its ID.
+func (s *MemoryDeadLetterStore) Get(id string) (DeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	letter, found := s.letters[id]
+	if !found {
+		return DeadLetter{}, ErrDeadLetterNotFound
+	}
+	return letter, nil
+}
+
+// Delete removes a dead letter by its ID.
+func (s *MemoryDeadLetterStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.letters[id]; !found {
+		return ErrDeadLetterNotFound
+	}
+	delete(s.letters, id)
+	return nil
+}
+
+// Purge removes all dead letters.
+func (s *MemoryDeadLetterStore) Purge() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := len(s.letters)
+	s.letters = make(map[string]DeadLetter)
+	return count, nil
+}
+
+// ListDeadLetters returns all notifications that failed permanently.
+func (n *Notifier) ListDeadLetters() ([]DeadLetter, error) {
+	return n.deadLetters.List()
+}
+
+// GetDeadLetter returns a dead letter by its ID.
+func (n *Notifier) GetDeadLetter(id string) (DeadLetter, error) {
+	return n.deadLetters.Get(id)
+}
+
+// DeleteDeadLetter removes a dead letter without replaying it.
+func (n *Notifier) DeleteDeadLetter(id string) error {
+	return n.deadLetters.Delete(id)
+}
+
+// PurgeDeadLetters removes all dead letters and returns how many were removed.
+func (n *Notifier) PurgeDeadLetters() (int, error) {
+	return n.deadLetters.Purge()
+}
+
+// ReplayDeadLetters enqueues dead letters again and removes them from the store.
+// When ids is empty all dead letters are replayed. When channel is not empty the
+// notifications are sent to that channel instead of their original one. The deadline
+// replaces the one of the notifications, which may have passed; zero removes it.
+// It returns the IDs of the replayed dead letters.
+func (n *Notifier) ReplayDeadLetters(ids []string, channel string, deadline time.Time) ([]string, error) {
+	done, err := n.startEnqueue()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	if channel != "" {
+		if _, err := n.getChannelSender(channel); err != nil {
+			return nil, err
+		}
+	}
+
+	var letters []DeadLetter
+	if len(ids) == 0 {
+		all, err := n.deadLetters.List()
+		if err != nil {
+			return nil, err
+		}
+		letters = all
+	}
+	for _, id := range ids {
+		letter, err := n.deadLetters.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		letters = append(letters, letter)
+	}
+
+	replayed := []string{}
+	for _, letter := range letters {
+		// Start over with a fresh attempt count and age.
+		notification := letter.Notification
+		notification.Attempts = 0
+		notification.CreatedAt = time.Now()
+		notification.Deadline = deadline
+		if channel != "" && channel != notification.Channel {
+			// Targets delivered to belong to the original channel.
+			notification.Channel = channel
+			notification.Delivered = nil
+		}
+
+		// Keep the original notification ID so its status can be followed again. The
+		// status of the original channel points to the one the notification moved to.
+		if notification.Channel != letter.Notification.Channel {
+			n.updateReplayStatus(notification.ID, letter.Notification.Channel, func(status *ChannelStatus) {
+				status.Status = StatusMoved
+				status.NextAttemptAt = nil
+			})
+		}
+		n.updateReplayStatus(notification.ID, notification.Channel, func(status *ChannelStatus) {
+			status.Status = StatusQueued
+			status.Attempts = 0
+			status.LastError = ""
+			status.NextAttemptAt = nil
+		})
+		if err := n.queue.Enqueue([]Notification{notification}); err != nil {
+			return replayed, err
+		}
+		if err := n.deadLetters.Delete(letter.ID); err != nil {
+			return replayed, err
+		}
+		replayed = append(replayed, letter.ID)
+	}
+	return replayed, nil
+}
+
+// updateReplayStatus updates the status of a replayed notification. The replay goes
+// ahead when this fails, for example because the status was evicted.
+func (n *Notifier) updateReplayStatus(id, channelName string, fn func(status *ChannelStatus)) {
+	if err := n.statuses.Update(id, channelName, fn); err != nil {
+		log.Printf("error: failed to update status of replayed notification %s: %v\n", id, err)
+	}
+}
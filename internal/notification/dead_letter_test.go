@@ -0,0 +1,199 @@
+package notification
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetterStores(t *testing.T) {
+	boltQueue, err := NewBoltQueue(filepath.Join(t.TempDir(), "queue.db"))
+	require.NoError(t, err)
+	defer boltQueue.Close()
+
+	// Define test cases
+	testCases := []struct {
+		name  string
+		store DeadLetterStore
+	}{
+		{
+			name:  "Memory store",
+			store: NewMemoryDeadLetterStore(),
+		},
+		{
+			name:  "Bolt store",
+			store: boltQueue.DeadLetters(),
+		},
+	}
+
+	// Iterate through test cases
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Now()
+			first := DeadLetter{ID: "1", Notification: Notification{ID: "n1", Channel: "foo"}, Attempts: 3, Error: "boom", FailedAt: now}
+			second := DeadLetter{ID: "2", Notification: Notification{ID: "n2", Channel: "bar"}, Attempts: 3, Error: "boom", FailedAt: now.Add(time.Second)}
+
+			// Add dead letters out of order
+			require.NoError(t, tc.store.Add(second))
+			require.NoError(t, tc.store.Add(first))
+
+			// List returns the oldest first
+			letters, err := tc.store.List()
+			require.NoError(t, err)
+			require.Len(t, letters, 2)
+			assert.Equal(t, "1", letters[0].ID)
+			assert.Equal(t, "2", letters[1].ID)
+
+			// Get and delete a single dead letter
+			letter, err := tc.store.Get("1")
+			require.NoError(t, err)
+			assert.Equal(t, "foo", letter.Notification.Channel)
+			assert.NoError(t, tc.store.Delete("1"))
+			_, err = tc.store.Get("1")
+			assert.Equal(t, ErrDeadLetterNotFound, err)
+			assert.Equal(t, ErrDeadLetterNotFound, tc.store.Delete("1"))
+
+			// Purge removes the rest
+			count, err := tc.store.Purge()
+			require.NoError(t, err)
+			assert.Equal(t, 1, count)
+			letters, err = tc.store.List()
+			require.NoError(t, err)
+			assert.Empty(t, letters)
+		})
+	}
+}
+
+func TestReplayDeadLetters(t *testing.T) {
+	// Define test cases
+	testCases := []struct {
+		name             string
+		ids              []string
+		channel          string
+		expectedReplayed []string
+		expectedChannels []string
+		expectedError    error
+	}{
+		{
+			name:             "Replay all",
+			expectedReplayed: []string{"1", "2"},
+			expectedChannels: []string{"foo", "bar"},
+		},
+		{
+			name:             "Replay one to a different channel",
+			ids:              []string{"2"},
+			channel:          "foo",
+			expectedReplayed: []string{"2"},
+			expectedChannels: []string{"foo"},
+		},
+		{
+			name:          "Replay to unknown channel",
+			channel:       "baz",
+			expectedError: ErrChannelNotFound,
+		},
+		{
+			name:          "Replay unknown dead letter",
+			ids:           []string{"3"},
+			expectedError: ErrDeadLetterNotFound,
+		},
+	}
+
+	// Iterate through test cases
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a notifier with two dead letters
+			notifier := NewNotifier(0)
+			_ = notifier.AddChannelSender(mockWithName("foo"))
+			_ = notifier.AddChannelSender(mockWithName("bar"))
+			now := time.Now()
+			_ = notifier.deadLetters.Add(DeadLetter{ID: "1", Notification: Notification{ID: "n1", Channel: "foo"}, FailedAt: now})
+			_ = notifier.deadLetters.Add(DeadLetter{ID: "2", Notification: Notification{ID: "n2", Channel: "bar"}, FailedAt: now.Add(time.Second)})
+
+			// Replay and check the result
+			replayed, err := notifier.ReplayDeadLetters(tc.ids, tc.channel, time.Time{})
+			assert.Equal(t, tc.expectedError, err)
+			if tc.expectedError != nil {
+				return
+			}
+			assert.Equal(t, tc.expectedReplayed, replayed)
+
+			// Replayed notifications are queued again and removed from the dead letters
+			queue := notifier.queue.(*MemoryQueue)
+			assert.Equal(t, len(tc.expectedChannels), queue.Len())
+			for _, expectedChannel := range tc.expectedChannels {
+				item, err := queue.Dequeue(context.Background())
+				require.NoError(t, err)
+				assert.Equal(t, expectedChannel, item.Channel)
+			}
+			for _, id := range tc.expectedReplayed {
+				_, err := notifier.GetDeadLetter(id)
+				assert.Equal(t, ErrDeadLetterNotFound, err)
+			}
+		})
+	}
+}
+
+func TestReplayDeadLetters_Status(t *testing.T) {
+	// Create a notifier with a notification that failed on bar
+	notifier := NewNotifier(0)
+	_ = notifier.AddChannelSender(mockWithName("foo"))
+	_ = notifier.AddChannelSender(mockWithName("bar"))
+	_ = notifier.statuses.Create(NotificationStatus{ID: "n1", Channels: []ChannelStatus{
+		{Channel: "bar", Status: StatusFailed, Attempts: 3, LastError: "boom"},
+	}})
+	_ = notifier.deadLetters.Add(DeadLetter{ID: "1", Notification: Notification{ID: "n1", Channel: "bar", Attempts: 3}})
+
+	// Replay it to foo
+	_, err := notifier.ReplayDeadLetters(nil, "foo", time.Time{})
+	require.NoError(t, err)
+
+	// The status of bar shows that the notification moved to foo
+	status, err := notifier.GetStatus("n1")
+	require.NoError(t, err)
+	require.Len(t, status.Channels, 2)
+	assert.Equal(t, "bar", status.Channels[0].Channel)
+	assert.Equal(t, StatusMoved, status.Channels[0].Status)
+	assert.Equal(t, "foo", status.Channels[1].Channel)
+	assert.Equal(t, StatusQueued, status.Channels[1].Status)
+	assert.Equal(t, 0, status.Channels[1].Attempts)
+}
+
+func TestReplayDeadLetters_Deadline(t *testing.T) {
+	// Define test cases
+	testCases := []struct {
+		name             string
+		deadline         time.Time
+		expectedDeadline time.Time
+	}{
+		{
+			name: "Passed deadline is removed",
+		},
+		{
+			name:             "New deadline replaces the passed one",
+			deadline:         time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+			expectedDeadline: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	// Iterate through test cases
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a notifier with a notification dead-lettered for passing its deadline
+			notifier := NewNotifier(0)
+			_ = notifier.AddChannelSender(mockWithName("foo"))
+			passed := time.Now().Add(-time.Hour)
+			_ = notifier.deadLetters.Add(DeadLetter{ID: "1", Notification: Notification{ID: "n1", Channel: "foo", Deadline: passed}})
+
+			// Replay it and check the deadline of the queued notification
+			_, err := notifier.ReplayDeadLetters(nil, "", tc.deadline)
+			require.NoError(t, err)
+			item, err := notifier.queue.Dequeue(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedDeadline, item.Deadline)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package notification
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// DelayQueue holds notifications waiting for a retry and releases them once they are due.
+// It uses a single timer set to the earliest due notification, so waiting retries do not
+// occupy any worker.
+type DelayQueue struct {
+	mu      sync.Mutex
+	items   delayHeap
+	timer   *time.Timer
+	release func(item QueuedNotification)
+	stopped bool
+}
+
+// NewDelayQueue creates a DelayQueue calling release for every notification that becomes due.
+func NewDelayQueue(release func(item QueuedNotification)) *DelayQueue {
+	return &DelayQueue{
+		release: release,
+	}
+}
+
+// Schedule adds a notification to be released at the given time.
+// It returns false if the delay queue was already stopped.
+func (d *DelayQueue) Schedule(item QueuedNotification, at time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		return false
+	}
+
+	heap.Push(&d.items, delayedNotification{item: item, at: at})
+	if d.items[0].at.Equal(at) {
+		d.resetTimer()
+	}
+	return true
+}
+
+// Len returns the number of notifications waiting in the delay queue.
+func (d *DelayQueue) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.items)
+}
+
+// Stop stops releasing notifications and returns those that were still waiting.
+func (d *DelayQueue) Stop() []QueuedNotification {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.stopped = true
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	pending := make([]QueuedNotification, 0, len(d.items))
+	for _, delayed := range d.items {
+		pending = append(pending, delayed.item)
+	}
+	d.items = nil
+	return pending
+}
+
+// fire releases all due notifications and re-arms the timer for the next one.
+func (d *DelayQueue) fire() {
+	d.mu.Lock()
+	var due []QueuedNotification
+	now := time.Now()
+	for len(d.items) > 0 && !d.items[0].at.After(now) {
+		due = append(due, heap.Pop(&d.items).(delayedNotification).item)
+	}
+	if len(d.items) > 0 && !d.stopped {
+		d.resetTimer()
+	}
+	d.mu.Unlock()
+
+	// Release outside the lock as handing notifications back may block.
+	for _, item := range due {
+		d.release(item)
+	}
+}
+
+// resetTimer arms the timer for the earliest notification. The caller must hold the lock.
+func (d *DelayQueue) resetTimer() {
+	delay := time.Until(d.items[0].at)
+	if d.timer == nil {
+		d.timer = time.AfterFunc(delay, d.fire)
+		return
+	}
+	d.timer.Stop()
+	d.timer.Reset(delay)
+}
+
+// delayedNotification is a notification waiting in the DelayQueue.
+type delayedNotification struct {
+	item QueuedNotification
+	at   time.Time
+}
+
+// delayHeap is a min-heap of delayed notifications ordered by due time.
+type delayHeap []delayedNotification
+
+func (h delayHeap) Len() int           { return len(h) }
+func (h delayHeap) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+func (h delayHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *delayHeap) Push(x any) {
+	*h = append(*h, x.(delayedNotification))
+}
+
+func (h *delayHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
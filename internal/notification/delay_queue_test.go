@@ -0,0 +1,41 @@
+package notification
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelayQueue(t *testing.T) {
+	var mu sync.Mutex
+	var released []string
+	d := NewDelayQueue(func(item QueuedNotification) {
+		mu.Lock()
+		defer mu.Unlock()
+		released = append(released, item.Message.Body)
+	})
+
+	// Schedule notifications out of order
+	now := time.Now()
+	d.Schedule(QueuedNotification{Notification: Notification{Message: channel.Message{Body: "second"}}}, now.Add(40*time.Millisecond))
+	d.Schedule(QueuedNotification{Notification: Notification{Message: channel.Message{Body: "first"}}}, now.Add(20*time.Millisecond))
+	d.Schedule(QueuedNotification{Notification: Notification{Message: channel.Message{Body: "later"}}}, now.Add(time.Hour))
+	assert.Equal(t, 3, d.Len())
+
+	// The due notifications are released in order
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(released) == 2
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"first", "second"}, released)
+
+	// Stopping returns the notifications that are still waiting
+	pending := d.Stop()
+	assert.Len(t, pending, 1)
+	assert.Equal(t, "later", pending[0].Message.Body)
+	assert.Equal(t, 0, d.Len())
+}
@@ -0,0 +1,50 @@
+package notification
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+)
+
+// CallbackHandler returns the sender of a channel that handles delivery callbacks of
+// its provider. It returns ErrChannelNotFound if the channel does not take callbacks.
+func (n *Notifier) CallbackHandler(channelName string) (channel.CallbackHandler, error) {
+	channelSender, err := n.getChannelSender(channelName)
+	if err != nil {
+		return nil, err
+	}
+	handler, ok := channelSender.(channel.CallbackHandler)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s does not take callbacks", ErrChannelNotFound, channelName)
+	}
+	return handler, nil
+}
+
+// RecordDelivery records a delivery report on the status of its notification. Providers
+// may report out of order, so a final status is never replaced by an earlier one.
+func (n *Notifier) RecordDelivery(channelName string, report channel.DeliveryReport) error {
+	if _, err := n.statuses.Get(report.NotificationID); err != nil {
+		return err
+	}
+	return n.statuses.Update(report.NotificationID, channelName, func(status *ChannelStatus) {
+		delivery := Delivery{
+			MessageID: report.MessageID,
+			Recipient: report.Recipient,
+			Status:    report.Status,
+			Final:     report.Final,
+			Error:     report.Error,
+			UpdatedAt: time.Now(),
+		}
+		for i, existing := range status.Deliveries {
+			if existing.MessageID != report.MessageID {
+				continue
+			}
+			if !existing.Final || report.Final {
+				status.Deliveries[i] = delivery
+			}
+			return
+		}
+		status.Deliveries = append(status.Deliveries, delivery)
+	})
+}
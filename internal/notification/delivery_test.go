@@ -0,0 +1,72 @@
+package notification
+
+import (
+	"context"
+	"testing"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+	"github.com/phgermanov/notification-service/internal/channel/channelfakes"
+	"github.com/stretchr/testify/assert"
+)
+
+// callbackSender is a sender whose provider reports deliveries by callback.
+type callbackSender struct {
+	*channelfakes.FakeSender
+	*channelfakes.FakeCallbackHandler
+}
+
+func TestCallbackHandler(t *testing.T) {
+	n := NewNotifier(0)
+	sender := callbackSender{mockWithName("SMS").(*channelfakes.FakeSender), new(channelfakes.FakeCallbackHandler)}
+	assert.NoError(t, n.AddChannelSender(sender))
+	assert.NoError(t, n.AddChannelSender(mockWithName("Slack")))
+
+	// Only senders handling callbacks are returned
+	handler, err := n.CallbackHandler("SMS")
+	assert.NoError(t, err)
+	assert.Equal(t, sender, handler)
+	_, err = n.CallbackHandler("Slack")
+	assert.ErrorIs(t, err, ErrChannelNotFound)
+	_, err = n.CallbackHandler("Unknown")
+	assert.ErrorIs(t, err, ErrChannelNotFound)
+}
+
+func TestRecordDelivery(t *testing.T) {
+	n := NewNotifier(0)
+	id, err := n.EnqueueNotifications([]Notification{{Channel: "SMS", Message: channel.Message{Body: "Hello"}}})
+	assert.NoError(t, err)
+
+	// Reports of unknown notifications are rejected
+	err = n.RecordDelivery("SMS", channel.DeliveryReport{NotificationID: "unknown", MessageID: "SM1", Status: "sent"})
+	assert.ErrorIs(t, err, ErrNotificationNotFound)
+
+	// Reports of each message are recorded, and final ones are not replaced by late earlier ones
+	reports := []channel.DeliveryReport{
+		{NotificationID: id, MessageID: "SM1", Recipient: "+14155550100", Status: "sent"},
+		{NotificationID: id, MessageID: "SM2", Recipient: "+14155550101", Status: "undelivered", Final: true, Error: "twilio error 30003"},
+		{NotificationID: id, MessageID: "SM1", Recipient: "+14155550100", Status: "delivered", Final: true},
+		{NotificationID: id, MessageID: "SM2", Recipient: "+14155550101", Status: "sent"},
+	}
+	for _, report := range reports {
+		assert.NoError(t, n.RecordDelivery("SMS", report))
+	}
+
+	status, err := n.GetStatus(id)
+	assert.NoError(t, err)
+	deliveries := status.Channels[0].Deliveries
+	assert.Len(t, deliveries, 2)
+	assert.Equal(t, "delivered", deliveries[0].Status)
+	assert.True(t, deliveries[0].Final)
+	assert.Equal(t, "undelivered", deliveries[1].Status)
+	assert.Equal(t, "twilio error 30003", deliveries[1].Error)
+}
+
+func TestSendContext_NotificationID(t *testing.T) {
+	nw := NewNotifierWorker(NewNotifier(0), 0)
+
+	// Senders can refer to the notification being sent
+	ctx, cancel := nw.sendContext(Notification{ID: "n-1", Channel: "SMS"})
+	defer cancel()
+	assert.Equal(t, "n-1", channel.NotificationID(ctx))
+	assert.Equal(t, "", channel.NotificationID(context.Background()))
+}
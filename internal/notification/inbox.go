@@ -0,0 +1,40 @@
+package notification
+
+import (
+	"github.com/phgermanov/notification-service/internal/channel"
+)
+
+// Inbox returns the in-app inbox, which the InApp sender should be created with.
+func (n *Notifier) Inbox() *channel.Inbox {
+	return n.inbox
+}
+
+// ListInbox returns the items of a user's in-app inbox matching the filter.
+func (n *Notifier) ListInbox(userID string, filter channel.InboxFilter) (channel.InboxPage, error) {
+	return n.inbox.List(userID, filter)
+}
+
+// MarkInboxItemRead marks an item of a user's inbox as read or unread.
+func (n *Notifier) MarkInboxItemRead(userID, id string, read bool) (channel.InboxItem, error) {
+	return n.inbox.Update(userID, id, func(item *channel.InboxItem) {
+		item.Read = read
+	})
+}
+
+// ArchiveInboxItem moves an item of a user's inbox to or from the archive.
+func (n *Notifier) ArchiveInboxItem(userID, id string, archived bool) (channel.InboxItem, error) {
+	return n.inbox.Update(userID, id, func(item *channel.InboxItem) {
+		item.Archived = archived
+	})
+}
+
+// SubscribeInbox opens a stream of the changes to a user's inbox, which is closed by
+// calling the returned function or by CloseInboxStreams.
+func (n *Notifier) SubscribeInbox(userID string) (<-chan channel.InboxEvent, func()) {
+	return n.inbox.Subscribe(userID)
+}
+
+// CloseInboxStreams closes all inbox streams, so that the API server can shut down.
+func (n *Notifier) CloseInboxStreams() {
+	n.inbox.Close()
+}
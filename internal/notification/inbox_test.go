@@ -0,0 +1,83 @@
+package notification
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInboxStores(t *testing.T) {
+	boltQueue, err := NewBoltQueue(filepath.Join(t.TempDir(), "queue.db"))
+	require.NoError(t, err)
+	defer boltQueue.Close()
+
+	// Define test cases
+	testCases := []struct {
+		name  string
+		store channel.InboxStore
+	}{
+		{
+			name:  "Memory store",
+			store: channel.NewMemoryInboxStore(),
+		},
+		{
+			name:  "Bolt store",
+			store: boltQueue.Inbox(),
+		},
+	}
+
+	// Iterate through test cases
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			n := NewNotifier(0, WithInboxStore(tc.store))
+			events, cancel := n.SubscribeInbox("alice")
+			defer cancel()
+
+			// Add items once per user
+			for _, item := range []channel.InboxItem{
+				{ID: "1", UserID: "alice", Message: channel.Message{Body: "first"}},
+				{ID: "2", UserID: "alice", Message: channel.Message{Body: "second"}},
+				{ID: "2", UserID: "alice", Message: channel.Message{Body: "again"}},
+				{ID: "1", UserID: "bob", Message: channel.Message{Body: "other"}},
+			} {
+				require.NoError(t, n.Inbox().Add(item))
+			}
+			assert.Len(t, events, 2)
+
+			// List returns the newest items first
+			page, err := n.ListInbox("alice", channel.InboxFilter{})
+			require.NoError(t, err)
+			require.Len(t, page.Items, 2)
+			assert.Equal(t, "second", page.Items[0].Message.Body)
+			assert.Equal(t, "first", page.Items[1].Message.Body)
+			assert.Equal(t, 2, page.Unread)
+
+			// Read and archived items are filtered
+			_, err = n.MarkInboxItemRead("alice", "1", true)
+			require.NoError(t, err)
+			_, err = n.ArchiveInboxItem("alice", "2", true)
+			require.NoError(t, err)
+			page, err = n.ListInbox("alice", channel.InboxFilter{UnreadOnly: true})
+			require.NoError(t, err)
+			assert.Empty(t, page.Items)
+			assert.Equal(t, 0, page.Unread)
+			page, err = n.ListInbox("alice", channel.InboxFilter{Archived: true})
+			require.NoError(t, err)
+			require.Len(t, page.Items, 1)
+			assert.Equal(t, "2", page.Items[0].ID)
+			assert.Len(t, events, 4)
+
+			// Unknown items and users are not found
+			_, err = n.MarkInboxItemRead("alice", "3", true)
+			assert.Equal(t, channel.ErrInboxItemNotFound, err)
+			_, err = n.ArchiveInboxItem("carol", "1", true)
+			assert.Equal(t, channel.ErrInboxItemNotFound, err)
+			page, err = n.ListInbox("carol", channel.InboxFilter{})
+			require.NoError(t, err)
+			assert.Empty(t, page.Items)
+		})
+	}
+}
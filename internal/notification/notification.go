@@ -1,7 +1,12 @@
 package notification
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
 	"sync"
 	"time"
 
@@ -10,45 +15,325 @@ import (
 
 var (
 	ErrAlreadyExists = errors.New("channel already exists")
+	ErrShuttingDown  = errors.New("notifier is shutting down")
 )
 
+// DefaultSendTimeout is how long a single send may take unless configured otherwise.
+const DefaultSendTimeout = 30 * time.Second
+
+// shutdownGrace is how long Shutdown waits for workers after cancelling their sends.
+const shutdownGrace = time.Second
+
 // Notification represents a message to be sent to multiple channels.
 type Notification struct {
-	Channel string
-	Message string
+	ID        string          `json:"id"`
+	Channel   string          `json:"channel"`
+	Message   channel.Message `json:"message"`
+	Template  string          `json:"template,omitempty"`  // Optional template rendered into Message when sending.
+	Data      map[string]any  `json:"data,omitempty"`      // Data the template is rendered with.
+	Attempts  int             `json:"attempts"`            // Number of send attempts made so far.
+	CreatedAt time.Time       `json:"created_at"`          // Time the notification was accepted.
+	Deadline  time.Time       `json:"deadline,omitempty"`  // Optional time after which sending is abandoned.
+	Delivered []string        `json:"delivered,omitempty"` // Targets the channel delivered to in earlier attempts.
 }
 
 // Notifier manages the sending of notifications to different channels.
 type Notifier struct {
-	channelSenders   map[string]channel.Sender
-	notificationPool chan Notification
-	wg               sync.WaitGroup
-	retryDuration    time.Duration
+	channelSenders map[string]channel.Sender
+	channelTypes   map[string]string // Types of the channels by lower-cased name, if known.
+	queue          Queue
+	statuses       StatusStore
+	deadLetters    DeadLetterStore
+	templates      TemplateStore
+	tokens         channel.TokenStore
+	subscriptions  channel.SubscriptionStore
+	inbox          *channel.Inbox
+	delays         *DelayQueue
+	wg             sync.WaitGroup
+	defaultPolicy  RetryPolicy
+	retryPolicies  map[string]RetryPolicy
+	defaultTimeout time.Duration
+	sendTimeouts   map[string]time.Duration
+
+	mu          sync.RWMutex       // Guards closing against enqueues starting concurrently.
+	closing     bool               // Set once Shutdown was called.
+	enqueues    sync.WaitGroup     // Enqueues in progress, which may block on a full queue.
+	workerCtx   context.Context    // Cancelled to stop workers from taking new work.
+	stopWorkers context.CancelFunc // Cancels workerCtx.
+	sendCtx     context.Context    // Cancelled to abort sends in flight.
+	cancelSends context.CancelFunc // Cancels sendCtx.
+}
+
+// Option configures optional Notifier behaviour.
+type Option func(*Notifier)
+
+// WithQueue sets the queue backend used to store notifications waiting to be processed.
+func WithQueue(queue Queue) Option {
+	return func(n *Notifier) {
+		n.queue = queue
+	}
+}
+
+// WithStatusStore sets the store used to track the delivery status of notifications.
+func WithStatusStore(statuses StatusStore) Option {
+	return func(n *Notifier) {
+		n.statuses = statuses
+	}
+}
+
+// WithDeadLetterStore sets the store receiving notifications that failed permanently.
+func WithDeadLetterStore(deadLetters DeadLetterStore) Option {
+	return func(n *Notifier) {
+		n.deadLetters = deadLetters
+	}
+}
+
+// WithTemplateStore sets the store holding the message templates.
+func WithTemplateStore(templates TemplateStore) Option {
+	return func(n *Notifier) {
+		n.templates = templates
+	}
+}
+
+// WithTokenStore sets the store of device tokens invalidated by the push channels.
+func WithTokenStore(tokens channel.TokenStore) Option {
+	return func(n *Notifier) {
+		n.tokens = tokens
+	}
+}
+
+// WithSubscriptionStore sets the store of the users' Web Push subscriptions.
+func WithSubscriptionStore(subscriptions channel.SubscriptionStore) Option {
+	return func(n *Notifier) {
+		n.subscriptions = subscriptions
+	}
 }
 
-// NewNotifier creates a new Notifier instance.
-func NewNotifier(retryDuration time.Duration) *Notifier {
-	return &Notifier{
-		channelSenders:   make(map[string]channel.Sender),
-		notificationPool: make(chan Notification, 100),
-		retryDuration:    retryDuration,
+// WithInboxStore sets the store of the users' in-app inboxes.
+func WithInboxStore(store channel.InboxStore) Option {
+	return func(n *Notifier) {
+		n.inbox = channel.NewInbox(store)
 	}
 }
 
+// WithDefaultRetryPolicy sets the retry policy for channels without their own policy.
+func WithDefaultRetryPolicy(policy RetryPolicy) Option {
+	return func(n *Notifier) {
+		n.defaultPolicy = policy
+	}
+}
+
+// WithRetryPolicy sets the retry policy for a channel, or for the channels of a type added
+// with AddChannelSenderWithType. Names are matched case-insensitively.
+func WithRetryPolicy(channelName string, policy RetryPolicy) Option {
+	return func(n *Notifier) {
+		n.retryPolicies[strings.ToLower(channelName)] = policy
+	}
+}
+
+// WithDefaultSendTimeout sets how long a single send may take on channels without their own timeout.
+func WithDefaultSendTimeout(timeout time.Duration) Option {
+	return func(n *Notifier) {
+		n.defaultTimeout = timeout
+	}
+}
+
+// WithSendTimeout sets how long a single send may take on a channel, or on the channels of
+// a type added with AddChannelSenderWithType. Names are matched case-insensitively.
+func WithSendTimeout(channelName string, timeout time.Duration) Option {
+	return func(n *Notifier) {
+		n.sendTimeouts[strings.ToLower(channelName)] = timeout
+	}
+}
+
+// NewNotifier creates a new Notifier instance. Without options notifications are
+// queued in memory, retried with DefaultRetryPolicy(retryDuration) and each send
+// times out after DefaultSendTimeout.
+func NewNotifier(retryDuration time.Duration, opts ...Option) *Notifier {
+	n := &Notifier{
+		channelSenders: make(map[string]channel.Sender),
+		channelTypes:   make(map[string]string),
+		defaultPolicy:  DefaultRetryPolicy(retryDuration),
+		retryPolicies:  make(map[string]RetryPolicy),
+		defaultTimeout: DefaultSendTimeout,
+		sendTimeouts:   make(map[string]time.Duration),
+	}
+	n.delays = NewDelayQueue(n.requeue)
+	n.workerCtx, n.stopWorkers = context.WithCancel(context.Background())
+	n.sendCtx, n.cancelSends = context.WithCancel(context.Background())
+	for _, opt := range opts {
+		opt(n)
+	}
+	if n.queue == nil {
+		n.queue = NewMemoryQueue(100)
+	}
+	if n.statuses == nil {
+		n.statuses = NewMemoryStatusStore(10000)
+	}
+	if n.deadLetters == nil {
+		n.deadLetters = NewMemoryDeadLetterStore()
+	}
+	if n.templates == nil {
+		n.templates = NewMemoryTemplateStore()
+	}
+	if n.tokens == nil {
+		n.tokens = channel.NewMemoryTokenStore()
+	}
+	if n.subscriptions == nil {
+		n.subscriptions = channel.NewMemorySubscriptionStore()
+	}
+	if n.inbox == nil {
+		n.inbox = channel.NewInbox(channel.NewMemoryInboxStore())
+	}
+	return n
+}
+
 // StartWorkers starts a specified number of worker goroutines for processing notifications.
 func (n *Notifier) StartWorkers(numWorkers int) {
 	for i := 0; i < numWorkers; i++ {
 		worker := NewNotifierWorker(n, i)
-		go worker.Start()
 		n.wg.Add(1)
+		go worker.Start()
 	}
 }
 
-// EnqueueNotification adds a notification to the processing queue.
-func (n *Notifier) EnqueueNotifications(notifications []Notification) {
+// Shutdown stops accepting new notifications and waits for the workers to finish.
+// Retries waiting in the delay queue are handed back to the queue; a durable queue
+// keeps them for the next start while an in-memory queue is drained by the workers.
+// If ctx expires before the workers finish, sends in flight are cancelled, Shutdown
+// returns ctx.Err() and any unacknowledged notifications are left to the queue backend.
+func (n *Notifier) Shutdown(ctx context.Context) error {
+	n.mu.Lock()
+	if n.closing {
+		n.mu.Unlock()
+		return ErrShuttingDown
+	}
+	n.closing = true
+	n.mu.Unlock()
+
+	// Let enqueues in progress finish while the workers still take notifications off a
+	// full queue. Those still blocked when ctx is done fail once the queue is closed.
+	enqueued := make(chan struct{})
+	go func() {
+		n.enqueues.Wait()
+		close(enqueued)
+	}()
+	select {
+	case <-enqueued:
+	case <-ctx.Done():
+	}
+
+	// Hand waiting retries back to the queue and let the workers finish.
+	for _, item := range n.delays.Stop() {
+		n.requeue(item)
+	}
+	n.stopWorkers()
+
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+
+		// Abort the sends in flight and give the workers a moment to return.
+		n.cancelSends()
+		select {
+		case <-done:
+		case <-time.After(shutdownGrace):
+		}
+	}
+	n.cancelSends()
+
+	// Close the connections of senders keeping them open, such as message brokers.
+	for _, name := range n.GetChannels() {
+		if closer, ok := n.channelSenders[name].(io.Closer); ok {
+			if closeErr := closer.Close(); closeErr != nil {
+				log.Printf("error: failed to close channel %s: %v\n", name, closeErr)
+			}
+		}
+	}
+
+	if closeErr := n.queue.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// EnqueueNotifications assigns a new ID to the notifications and adds them to the
+// processing queue. It returns the ID once the queue backend has accepted them.
+// Notifications using a template are rendered once up front, so that unknown
+// templates and missing data are reported as ErrInvalidNotification.
+func (n *Notifier) EnqueueNotifications(notifications []Notification) (string, error) {
+	done, err := n.startEnqueue()
+	if err != nil {
+		return "", err
+	}
+	defer done()
+
+	// Check that templated messages render, and that their action has the correlation
+	// key it needs, which may come from the template.
 	for _, notification := range notifications {
-		n.notificationPool <- notification
+		if notification.Template == "" {
+			continue
+		}
+		message, err := n.renderMessage(notification)
+		if err == nil {
+			err = message.Validate()
+		}
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrInvalidNotification, err)
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return "", err
+	}
+
+	// Record the notification as queued on each of its channels.
+	status := NotificationStatus{ID: id, CreatedAt: time.Now()}
+	for i := range notifications {
+		notifications[i].ID = id
+		notifications[i].CreatedAt = status.CreatedAt
+		status.Channels = append(status.Channels, ChannelStatus{
+			Channel:   notifications[i].Channel,
+			Status:    StatusQueued,
+			UpdatedAt: status.CreatedAt,
+		})
+	}
+	if err := n.statuses.Create(status); err != nil {
+		return "", err
+	}
+
+	if err := n.queue.Enqueue(notifications); err != nil {
+		_ = n.statuses.Delete(id)
+		return "", err
 	}
+	return id, nil
+}
+
+// startEnqueue tracks an enqueue until the returned function is called, unless the
+// notifier is shutting down. The lock is not held during the enqueue, which may block
+// on a full queue, so Shutdown can start meanwhile and wait for it.
+func (n *Notifier) startEnqueue() (func(), error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.closing {
+		return nil, ErrShuttingDown
+	}
+	n.enqueues.Add(1)
+	return n.enqueues.Done, nil
+}
+
+// GetStatus returns the delivery status of a notification.
+func (n *Notifier) GetStatus(id string) (NotificationStatus, error) {
+	return n.statuses.Get(id)
 }
 
 // AddChannelSender adds a channel sender to the Notifier.
@@ -60,6 +345,22 @@ func (n *Notifier) AddChannelSender(channelSender channel.Sender) error {
 	return nil
 }
 
+// AddChannelSenderWithType adds a channel sender of a channel type, such as slack. Retry
+// policies, send timeouts and template variants configured for the type apply to the
+// channel unless there are ones for its name.
+func (n *Notifier) AddChannelSenderWithType(channelSender channel.Sender, channelType string) error {
+	if err := n.AddChannelSender(channelSender); err != nil {
+		return err
+	}
+	n.channelTypes[strings.ToLower(channelSender.GetName())] = strings.ToLower(channelType)
+	return nil
+}
+
+// channelType returns the type of a channel, or "" if unknown.
+func (n *Notifier) channelType(channelName string) string {
+	return n.channelTypes[strings.ToLower(channelName)]
+}
+
 // GetChannels returns a list of available channel names.
 func (n *Notifier) GetChannels() []string {
 	keys := make([]string, 0, len(n.channelSenders))
@@ -69,6 +370,42 @@ func (n *Notifier) GetChannels() []string {
 	return keys
 }
 
+// retryPolicy returns the retry policy for a channel, falling back to the one of its type.
+func (n *Notifier) retryPolicy(channelName string) RetryPolicy {
+	if policy, found := n.retryPolicies[strings.ToLower(channelName)]; found {
+		return policy
+	}
+	if policy, found := n.retryPolicies[n.channelType(channelName)]; found {
+		return policy
+	}
+	return n.defaultPolicy
+}
+
+// sendTimeout returns how long a single send may take on a channel, falling back to the
+// timeout of its type.
+func (n *Notifier) sendTimeout(channelName string) time.Duration {
+	if timeout, found := n.sendTimeouts[strings.ToLower(channelName)]; found {
+		return timeout
+	}
+	if timeout, found := n.sendTimeouts[n.channelType(channelName)]; found {
+		return timeout
+	}
+	return n.defaultTimeout
+}
+
+// requeue puts a notification released by the delay queue back on the queue and
+// acknowledges the attempt it replaces. If this fails, a durable queue still holds
+// the original notification and will hand it out again after a restart.
+func (n *Notifier) requeue(item QueuedNotification) {
+	if err := n.queue.Enqueue([]Notification{item.Notification}); err != nil {
+		log.Printf("error: failed to requeue notification %s: %v\n", item.ID, err)
+		return
+	}
+	if err := n.queue.Ack(item); err != nil {
+		log.Printf("error: failed to acknowledge notification %s: %v\n", item.ID, err)
+	}
+}
+
 // getChannelSender retrieves a channel sender by its name.
 func (n *Notifier) getChannelSender(name string) (channel.Sender, error) {
 	channelSender, found := n.channelSenders[name]
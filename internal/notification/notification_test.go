@@ -1,8 +1,10 @@
 package notification
 
 import (
+	"context"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/phgermanov/notification-service/internal/channel"
 	"github.com/phgermanov/notification-service/internal/channel/channelfakes"
@@ -53,6 +55,44 @@ func TestAddChannelSender(t *testing.T) {
 	}
 }
 
+func TestAddChannelSenderWithType(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 7}
+	notifier := NewNotifier(time.Second,
+		WithRetryPolicy("slack", policy),
+		WithSendTimeout("Slack", time.Minute),
+		WithSendTimeout("slack-dev", time.Second),
+	)
+	assert.NoError(t, notifier.AddChannelSenderWithType(mockWithName("slack-ops"), "Slack"))
+	assert.NoError(t, notifier.AddChannelSenderWithType(mockWithName("slack-dev"), "slack"))
+	assert.ErrorIs(t, notifier.AddChannelSenderWithType(mockWithName("slack-ops"), "slack"), ErrAlreadyExists)
+	_, err := notifier.CreateTemplate(Template{
+		Name:     "alerts",
+		Default:  TemplateVariant{Body: "default"},
+		Channels: map[string]TemplateVariant{"slack": {Body: "slack"}, "slack-dev": {Body: "slack-dev"}},
+	})
+	assert.NoError(t, err)
+
+	// Channels without settings of their own use the ones of their type
+	assert.Equal(t, policy, notifier.retryPolicy("slack-ops"))
+	assert.Equal(t, time.Minute, notifier.sendTimeout("slack-ops"))
+	message, err := notifier.RenderTemplate("alerts", "slack-ops", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "slack", message.Body)
+
+	// Settings of the channel take precedence
+	assert.Equal(t, time.Second, notifier.sendTimeout("slack-dev"))
+	message, err = notifier.RenderTemplate("alerts", "slack-dev", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "slack-dev", message.Body)
+
+	// Channels of unknown type use the defaults
+	assert.NoError(t, notifier.AddChannelSender(mockWithName("other")))
+	assert.Equal(t, DefaultRetryPolicy(time.Second), notifier.retryPolicy("other"))
+	message, err = notifier.RenderTemplate("alerts", "other", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "default", message.Body)
+}
+
 func TestGetChannels(t *testing.T) {
 	// Create mock channel senders
 	mock1 := mockWithName("mock1")
@@ -184,7 +224,7 @@ func TestEnqueueNotifications(t *testing.T) {
 			notifications: []Notification{
 				{
 					Channel: "foo",
-					Message: "hello",
+					Message: channel.Message{Body: "hello"},
 				},
 			},
 		},
@@ -193,15 +233,15 @@ func TestEnqueueNotifications(t *testing.T) {
 			notifications: []Notification{
 				{
 					Channel: "foo",
-					Message: "hello",
+					Message: channel.Message{Body: "hello"},
 				},
 				{
 					Channel: "bar",
-					Message: "hello",
+					Message: channel.Message{Body: "hello"},
 				},
 				{
 					Channel: "baz",
-					Message: "hello",
+					Message: channel.Message{Body: "hello"},
 				},
 			},
 		},
@@ -214,10 +254,21 @@ func TestEnqueueNotifications(t *testing.T) {
 			notifier := NewNotifier(0)
 
 			// Enqueue notifications
-			notifier.EnqueueNotifications(tc.notifications)
+			id, err := notifier.EnqueueNotifications(tc.notifications)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, id)
+
+			// Check if the length of the queue increased by the expected amount
+			assert.Equal(t, len(tc.notifications), notifier.queue.(*MemoryQueue).Len())
 
-			// Check if the length of the notification pool increased by the expected amount
-			assert.Equal(t, len(tc.notifications), len(notifier.notificationPool))
+			// Check that every channel is reported as queued under the returned ID
+			status, err := notifier.GetStatus(id)
+			assert.NoError(t, err)
+			assert.Len(t, status.Channels, len(tc.notifications))
+			for i, channelStatus := range status.Channels {
+				assert.Equal(t, tc.notifications[i].Channel, channelStatus.Channel)
+				assert.Equal(t, StatusQueued, channelStatus.Status)
+			}
 		})
 	}
 }
@@ -228,3 +279,126 @@ func mockWithName(name string) channel.Sender {
 	mock.GetNameReturns(name)
 	return mock
 }
+
+func TestShutdown(t *testing.T) {
+	// Define test cases
+	testCases := []struct {
+		name              string
+		sendStub          func(context.Context, channel.Message) error
+		timeout           time.Duration
+		expectedError     error
+		expectedCallCount int
+	}{
+		{
+			name:              "Drains queued notifications",
+			timeout:           time.Second,
+			expectedError:     nil,
+			expectedCallCount: 3,
+		},
+		{
+			name: "Cancels sends in flight when the deadline expires",
+			sendStub: func(ctx context.Context, _ channel.Message) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			timeout:       10 * time.Millisecond,
+			expectedError: context.DeadlineExceeded,
+		},
+	}
+
+	// Iterate through test cases
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a notifier with queued notifications and a running worker
+			notifier := NewNotifier(0)
+			mock := new(channelfakes.FakeSender)
+			mock.GetNameReturns("mock")
+			mock.SendStub = tc.sendStub
+			_ = notifier.AddChannelSender(mock)
+			_, err := notifier.EnqueueNotifications([]Notification{
+				{Channel: "mock", Message: channel.Message{Body: "one"}},
+				{Channel: "mock", Message: channel.Message{Body: "two"}},
+				{Channel: "mock", Message: channel.Message{Body: "three"}},
+			})
+			assert.NoError(t, err)
+			notifier.StartWorkers(1)
+
+			// Shut down and check the result
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			assert.Equal(t, tc.expectedError, notifier.Shutdown(ctx))
+			if tc.expectedCallCount > 0 {
+				assert.Equal(t, tc.expectedCallCount, mock.SendCallCount())
+			}
+
+			// New notifications are rejected after shutdown
+			_, err = notifier.EnqueueNotifications([]Notification{{Channel: "mock", Message: channel.Message{Body: "late"}}})
+			assert.Equal(t, ErrShuttingDown, err)
+		})
+	}
+}
+
+func TestShutdownWithFullQueue(t *testing.T) {
+	// Create a notifier with a full queue and no workers to drain it
+	notifier := NewNotifier(0, WithQueue(NewMemoryQueue(1)))
+	_ = notifier.AddChannelSender(mockWithName("mock"))
+	_, err := notifier.EnqueueNotifications([]Notification{{Channel: "mock", Message: channel.Message{Body: "one"}}})
+	assert.NoError(t, err)
+
+	// An enqueue blocked on the full queue does not hold up the shutdown
+	result := make(chan error)
+	go func() {
+		_, err := notifier.EnqueueNotifications([]Notification{{Channel: "mock", Message: channel.Message{Body: "two"}}})
+		result <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	shutdown := make(chan error)
+	go func() {
+		shutdown <- notifier.Shutdown(ctx)
+	}()
+	select {
+	case <-shutdown:
+	case <-time.After(time.Second):
+		t.Fatal("shutdown blocked by a full queue")
+	}
+
+	// The blocked enqueue fails once the queue is closed
+	select {
+	case err := <-result:
+		assert.ErrorIs(t, err, ErrQueueClosed)
+	case <-time.After(time.Second):
+		t.Fatal("enqueue still blocked after shutdown")
+	}
+}
+
+// closingSender is a sender keeping a connection open until it is closed.
+type closingSender struct {
+	*channelfakes.FakeSender
+	sendsAtClose int // Number of sends when the sender was closed, -1 while open.
+}
+
+func (s *closingSender) Close() error {
+	s.sendsAtClose = s.SendCallCount()
+	return nil
+}
+
+func TestShutdownClosesSenders(t *testing.T) {
+	// Create a notifier with a sender keeping a connection open
+	notifier := NewNotifier(0)
+	sender := &closingSender{FakeSender: new(channelfakes.FakeSender), sendsAtClose: -1}
+	sender.GetNameReturns("broker")
+	_ = notifier.AddChannelSender(sender)
+	_ = notifier.AddChannelSender(mockWithName("mock"))
+	_, err := notifier.EnqueueNotifications([]Notification{
+		{Channel: "broker", Message: channel.Message{Body: "one"}},
+		{Channel: "broker", Message: channel.Message{Body: "two"}},
+	})
+	assert.NoError(t, err)
+	notifier.StartWorkers(1)
+
+	// The sender is closed once the queued notifications are sent
+	assert.NoError(t, notifier.Shutdown(context.Background()))
+	assert.Equal(t, 2, sender.sendsAtClose)
+}
@@ -0,0 +1,116 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	ErrQueueClosed = errors.New("queue is closed")
+)
+
+// Queue stores notifications waiting to be processed by the workers.
+type Queue interface {
+	// Enqueue stores the notifications, returning only once the queue has accepted them.
+	Enqueue(notifications []Notification) error
+	// Dequeue blocks until a notification is available, ctx is done or the queue is closed.
+	// Backends that lose their contents on exit keep handing out queued notifications
+	// after ctx is done, so they can be drained during shutdown.
+	Dequeue(ctx context.Context) (QueuedNotification, error)
+	// Ack removes a dequeued notification from the queue once it has been processed.
+	Ack(item QueuedNotification) error
+	// Close stops the queue and releases its resources.
+	Close() error
+}
+
+// QueuedNotification is a notification handed out by a Queue, awaiting acknowledgement.
+type QueuedNotification struct {
+	Notification
+	Receipt uint64 // Queue specific identifier used to acknowledge the notification.
+}
+
+// MemoryQueue is a bounded in-memory Queue. Its contents are lost when the process exits.
+type MemoryQueue struct {
+	items     chan QueuedNotification
+	done      chan struct{} // Closed on Close, releasing enqueues blocked on a full queue.
+	closeOnce sync.Once
+	mu        sync.RWMutex // Guards items against being closed during an enqueue.
+	closed    bool
+}
+
+// NewMemoryQueue creates a new MemoryQueue holding up to size notifications.
+func NewMemoryQueue(size int) *MemoryQueue {
+	return &MemoryQueue{
+		items: make(chan QueuedNotification, size),
+		done:  make(chan struct{}),
+	}
+}
+
+// Enqueue adds notifications to the queue, blocking while the queue is full. It fails
+// with ErrQueueClosed if the queue is closed, even while blocked.
+func (q *MemoryQueue) Enqueue(notifications []Notification) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	for _, notification := range notifications {
+		select {
+		case q.items <- QueuedNotification{Notification: notification}:
+		case <-q.done:
+			return ErrQueueClosed
+		}
+	}
+	return nil
+}
+
+// Dequeue returns the next notification. Queued notifications are handed out even
+// after ctx is done or the queue is closed, as they would otherwise be lost.
+func (q *MemoryQueue) Dequeue(ctx context.Context) (QueuedNotification, error) {
+	select {
+	case item, ok := <-q.items:
+		return q.received(item, ok)
+	default:
+	}
+
+	select {
+	case item, ok := <-q.items:
+		return q.received(item, ok)
+	case <-ctx.Done():
+		return QueuedNotification{}, ctx.Err()
+	}
+}
+
+// received converts a receive from the items channel into a Dequeue result.
+func (q *MemoryQueue) received(item QueuedNotification, ok bool) (QueuedNotification, error) {
+	if !ok {
+		return QueuedNotification{}, ErrQueueClosed
+	}
+	return item, nil
+}
+
+// Ack is a no-op as dequeued notifications are no longer held by the queue.
+func (q *MemoryQueue) Ack(QueuedNotification) error {
+	return nil
+}
+
+// Close stops the queue from accepting new notifications.
+func (q *MemoryQueue) Close() error {
+	// Release blocked enqueues first, as they hold the lock.
+	q.closeOnce.Do(func() {
+		close(q.done)
+	})
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		close(q.items)
+	}
+	return nil
+}
+
+// Len returns the number of notifications waiting in the queue.
+func (q *MemoryQueue) Len() int {
+	return len(q.items)
+}
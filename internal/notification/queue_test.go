@@ -0,0 +1,89 @@
+package notification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryQueue(t *testing.T) {
+	// Define test cases
+	testCases := []struct {
+		name          string
+		notifications []Notification
+	}{
+		{
+			name:          "Empty queue",
+			notifications: []Notification{},
+		},
+		{
+			name: "Drains notifications after close",
+			notifications: []Notification{
+				{Channel: "foo", Message: channel.Message{Body: "hello"}},
+				{Channel: "bar", Message: channel.Message{Body: "world"}},
+			},
+		},
+	}
+
+	// Iterate through test cases
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewMemoryQueue(10)
+
+			// Enqueue the notifications and close the queue
+			assert.NoError(t, q.Enqueue(tc.notifications))
+			assert.NoError(t, q.Close())
+
+			// Enqueueing after close fails
+			assert.Equal(t, ErrQueueClosed, q.Enqueue(tc.notifications))
+
+			// Queued notifications are still handed out in order
+			for _, expected := range tc.notifications {
+				item, err := q.Dequeue(context.Background())
+				assert.NoError(t, err)
+				assert.Equal(t, expected, item.Notification)
+			}
+			_, err := q.Dequeue(context.Background())
+			assert.Equal(t, ErrQueueClosed, err)
+		})
+	}
+}
+
+func TestMemoryQueue_DrainsAfterContextIsDone(t *testing.T) {
+	q := NewMemoryQueue(10)
+	assert.NoError(t, q.Enqueue([]Notification{{Channel: "foo", Message: channel.Message{Body: "hello"}}}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Queued notifications are still handed out as they would otherwise be lost
+	item, err := q.Dequeue(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", item.Message.Body)
+
+	// Once empty the context error is returned
+	_, err = q.Dequeue(ctx)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestMemoryQueue_CloseReleasesBlockedEnqueue(t *testing.T) {
+	q := NewMemoryQueue(1)
+	assert.NoError(t, q.Enqueue([]Notification{{Channel: "foo", Message: channel.Message{Body: "one"}}}))
+
+	// An enqueue blocked on the full queue fails once the queue is closed
+	result := make(chan error)
+	go func() {
+		result <- q.Enqueue([]Notification{{Channel: "foo", Message: channel.Message{Body: "two"}}})
+	}()
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, q.Close())
+	select {
+	case err := <-result:
+		assert.Equal(t, ErrQueueClosed, err)
+	case <-time.After(time.Second):
+		t.Fatal("enqueue still blocked after close")
+	}
+}
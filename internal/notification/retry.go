@@ -0,0 +1,85 @@
+package notification
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+)
+
+// RetryPolicy controls how often and how quickly failed notifications are retried.
+type RetryPolicy struct {
+	MaxAttempts    int           // Maximum number of send attempts, including the first one.
+	InitialBackoff time.Duration // Delay before the first retry.
+	MaxBackoff     time.Duration // Upper bound for the delay between attempts, 0 means unbounded.
+	Multiplier     float64       // Factor the delay grows by after each attempt.
+	Jitter         float64       // Fraction of the delay that is randomised, between 0 and 1.
+	MaxAge         time.Duration // Give up once a notification is older than this, 0 means no limit.
+}
+
+// DefaultRetryPolicy returns the policy used for channels without their own policy.
+func DefaultRetryPolicy(initialBackoff time.Duration) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: initialBackoff,
+		MaxBackoff:     5 * time.Minute,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// Backoff returns the delay before the next attempt after the given number of failed attempts.
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	backoff := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempts-1))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+
+	// Spread the delay evenly within +/- Jitter to avoid retrying in lockstep.
+	if p.Jitter > 0 {
+		backoff += backoff * p.Jitter * (2*rand.Float64() - 1)
+	}
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	return time.Duration(backoff)
+}
+
+// NextAttempt reports whether a notification that just failed with err should be retried
+// and after which delay. Permanent errors are never retried and the delay requested by a
+// rate limited channel takes precedence over the backoff.
+func (p RetryPolicy) NextAttempt(notification Notification, err error, now time.Time) (time.Duration, bool) {
+	if IsPermanent(err) || notification.Attempts >= p.MaxAttempts {
+		return 0, false
+	}
+	delay := p.Backoff(notification.Attempts)
+	if retryAfter, ok := channel.RetryAfter(err); ok {
+		delay = retryAfter
+	}
+	if p.MaxAge > 0 && !notification.CreatedAt.IsZero() && now.Add(delay).Sub(notification.CreatedAt) > p.MaxAge {
+		return 0, false
+	}
+	if !notification.Deadline.IsZero() && now.Add(delay).After(notification.Deadline) {
+		return 0, false
+	}
+	return delay, true
+}
+
+// IsPermanent reports whether a send error can never succeed when retried.
+func IsPermanent(err error) bool {
+	return channel.IsPermanent(err) ||
+		errors.Is(err, ErrChannelNotFound) ||
+		errors.Is(err, ErrDeadlineExceeded) ||
+		errors.Is(err, ErrTemplateNotFound) ||
+		errors.Is(err, ErrInvalidTemplate)
+}
@@ -0,0 +1,140 @@
+package notification
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	// Define test cases
+	testCases := []struct {
+		name        string
+		policy      RetryPolicy
+		attempts    int
+		expectedMin time.Duration
+		expectedMax time.Duration
+	}{
+		{
+			name:        "First retry uses the initial backoff",
+			policy:      RetryPolicy{InitialBackoff: time.Second, Multiplier: 2},
+			attempts:    1,
+			expectedMin: time.Second,
+			expectedMax: time.Second,
+		},
+		{
+			name:        "Backoff grows exponentially",
+			policy:      RetryPolicy{InitialBackoff: time.Second, Multiplier: 2},
+			attempts:    4,
+			expectedMin: 8 * time.Second,
+			expectedMax: 8 * time.Second,
+		},
+		{
+			name:        "Backoff is capped",
+			policy:      RetryPolicy{InitialBackoff: time.Second, Multiplier: 2, MaxBackoff: 5 * time.Second},
+			attempts:    10,
+			expectedMin: 5 * time.Second,
+			expectedMax: 5 * time.Second,
+		},
+		{
+			name:        "Jitter stays within bounds",
+			policy:      RetryPolicy{InitialBackoff: 10 * time.Second, Multiplier: 1, Jitter: 0.5},
+			attempts:    3,
+			expectedMin: 5 * time.Second,
+			expectedMax: 15 * time.Second,
+		},
+	}
+
+	// Iterate through test cases
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				backoff := tc.policy.Backoff(tc.attempts)
+				assert.GreaterOrEqual(t, backoff, tc.expectedMin)
+				assert.LessOrEqual(t, backoff, tc.expectedMax)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_NextAttempt(t *testing.T) {
+	now := time.Now()
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Minute, Multiplier: 1, MaxAge: 10 * time.Minute}
+
+	// Define test cases
+	testCases := []struct {
+		name          string
+		notification  Notification
+		err           error
+		expectedRetry bool
+		expectedDelay time.Duration
+	}{
+		{
+			name:          "Retries while attempts are left",
+			notification:  Notification{Attempts: 1, CreatedAt: now},
+			err:           channel.Retryable(errors.New("server error")),
+			expectedRetry: true,
+			expectedDelay: time.Minute,
+		},
+		{
+			name:          "Retries unclassified errors",
+			notification:  Notification{Attempts: 1, CreatedAt: now},
+			err:           errors.New("some error"),
+			expectedRetry: true,
+		},
+		{
+			name:          "Honours the delay requested by a rate limited channel",
+			notification:  Notification{Attempts: 1, CreatedAt: now},
+			err:           channel.RateLimited(errors.New("slow down"), 3*time.Minute),
+			expectedRetry: true,
+			expectedDelay: 3 * time.Minute,
+		},
+		{
+			name:          "Does not retry permanent errors",
+			notification:  Notification{Attempts: 1, CreatedAt: now},
+			err:           channel.Permanent(errors.New("invalid token")),
+			expectedRetry: false,
+		},
+		{
+			name:          "Does not retry unknown channels",
+			notification:  Notification{Attempts: 1, CreatedAt: now},
+			err:           ErrChannelNotFound,
+			expectedRetry: false,
+		},
+		{
+			name:          "Stops after the maximum attempts",
+			notification:  Notification{Attempts: 3, CreatedAt: now},
+			expectedRetry: false,
+		},
+		{
+			name:          "Stops when the next attempt would exceed the maximum age",
+			notification:  Notification{Attempts: 1, CreatedAt: now.Add(-9*time.Minute - 30*time.Second)},
+			expectedRetry: false,
+		},
+		{
+			name:          "Stops when the next attempt would miss the deadline",
+			notification:  Notification{Attempts: 1, CreatedAt: now, Deadline: now.Add(30 * time.Second)},
+			expectedRetry: false,
+		},
+		{
+			name:          "Does not retry expired notifications",
+			notification:  Notification{Attempts: 1, CreatedAt: now},
+			err:           ErrDeadlineExceeded,
+			expectedRetry: false,
+		},
+	}
+
+	// Iterate through test cases
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			delay, retry := policy.NextAttempt(tc.notification, tc.err, now)
+			assert.Equal(t, tc.expectedRetry, retry)
+			if tc.expectedDelay > 0 {
+				assert.Equal(t, tc.expectedDelay, delay)
+			}
+		})
+	}
+}
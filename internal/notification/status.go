@@ -0,0 +1,176 @@
+package notification
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+)
+
+// Status is the delivery state of a notification on a single channel.
+type Status string
+
+const (
+	StatusQueued    Status = "queued"    // Waiting in the queue for a worker.
+	StatusSending   Status = "sending"   // A worker is currently sending it.
+	StatusRetrying  Status = "retrying"  // The last attempt failed and it will be retried.
+	StatusDelivered Status = "delivered" // The channel accepted the notification.
+	StatusFailed    Status = "failed"    // All attempts failed, no further retries.
+	StatusMoved     Status = "moved"     // Replayed from the dead letters to a different channel.
+)
+
+// ChannelStatus describes the delivery state of a notification on one channel.
+type ChannelStatus struct {
+	Channel       string     `json:"channel"`
+	Status        Status     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// Deliveries holds the delivery status of the sent messages as reported by the provider.
+	Deliveries []Delivery `json:"deliveries,omitempty"`
+}
+
+// Delivery is the delivery status of a single message reported by the provider after the channel accepted it.
+type Delivery struct {
+	MessageID string    `json:"message_id"`
+	Recipient string    `json:"recipient,omitempty"`
+	Status    string    `json:"status"`
+	Final     bool      `json:"final"`
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NotificationStatus describes the delivery state of a notification on all its channels.
+type NotificationStatus struct {
+	ID        string          `json:"id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Channels  []ChannelStatus `json:"channels"`
+}
+
+// StatusStore keeps track of the delivery status of notifications.
+type StatusStore interface {
+	// Create stores the initial status of a notification.
+	Create(status NotificationStatus) error
+	// Update applies fn to the status of a notification on a channel, creating it if needed.
+	Update(id, channel string, fn func(status *ChannelStatus)) error
+	// Get returns the status of a notification or ErrNotificationNotFound.
+	Get(id string) (NotificationStatus, error)
+	// Delete removes the status of a notification.
+	Delete(id string) error
+}
+
+// MemoryStatusStore is an in-memory StatusStore keeping the most recent notifications.
+type MemoryStatusStore struct {
+	mu       sync.RWMutex
+	statuses map[string]*NotificationStatus
+	order    []string // Notification IDs in creation order, used for eviction.
+	limit    int
+}
+
+// NewMemoryStatusStore creates a MemoryStatusStore remembering up to limit notifications.
+func NewMemoryStatusStore(limit int) *MemoryStatusStore {
+	return &MemoryStatusStore{
+		statuses: make(map[string]*NotificationStatus),
+		limit:    limit,
+	}
+}
+
+// Create stores the initial status of a notification, evicting the oldest one when full.
+func (s *MemoryStatusStore) Create(status NotificationStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.add(&status)
+	return nil
+}
+
+// Update applies fn to the status of a notification on a channel.
+func (s *MemoryStatusStore) Update(id, channel string, fn func(status *ChannelStatus)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Notifications resumed from a durable queue may not be known after a restart.
+	status, found := s.statuses[id]
+	if !found {
+		status = &NotificationStatus{ID: id, CreatedAt: time.Now()}
+		s.add(status)
+	}
+	status.update(channel, fn)
+	return nil
+}
+
+// update applies fn to the status of a channel, adding it if needed.
+func (s *NotificationStatus) update(channel string, fn func(status *ChannelStatus)) {
+	for i := range s.Channels {
+		if s.Channels[i].Channel == channel {
+			fn(&s.Channels[i])
+			s.Channels[i].UpdatedAt = time.Now()
+			return
+		}
+	}
+	channelStatus := ChannelStatus{Channel: channel, Status: StatusQueued}
+	fn(&channelStatus)
+	channelStatus.UpdatedAt = time.Now()
+	s.Channels = append(s.Channels, channelStatus)
+}
+
+// Get returns a copy of the status of a notification.
+func (s *MemoryStatusStore) Get(id string) (NotificationStatus, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status, found := s.statuses[id]
+	if !found {
+		return NotificationStatus{}, ErrNotificationNotFound
+	}
+	result := *status
+	result.Channels = append([]ChannelStatus(nil), status.Channels...)
+	for i := range result.Channels {
+		result.Channels[i].Deliveries = append([]Delivery(nil), result.Channels[i].Deliveries...)
+	}
+	return result, nil
+}
+
+// Delete removes the status of a notification.
+func (s *MemoryStatusStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.statuses, id)
+	for i, existing := range s.order {
+		if existing == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// add inserts a status and evicts the oldest ones above the limit. The caller must hold the lock.
+func (s *MemoryStatusStore) add(status *NotificationStatus) {
+	if _, exists := s.statuses[status.ID]; !exists {
+		s.order = append(s.order, status.ID)
+	}
+	s.statuses[status.ID] = status
+	for s.limit > 0 && len(s.order) > s.limit {
+		delete(s.statuses, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+// newID returns a random RFC 4122 version 4 UUID.
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
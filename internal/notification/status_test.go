@@ -0,0 +1,126 @@
+package notification
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStatusStore(t *testing.T) {
+	// Define test cases
+	testCases := []struct {
+		name          string
+		limit         int
+		created       []string
+		expectedFound []string
+		expectedGone  []string
+	}{
+		{
+			name:          "Keeps all notifications below the limit",
+			limit:         3,
+			created:       []string{"a", "b"},
+			expectedFound: []string{"a", "b"},
+		},
+		{
+			name:          "Evicts the oldest notifications above the limit",
+			limit:         2,
+			created:       []string{"a", "b", "c"},
+			expectedFound: []string{"b", "c"},
+			expectedGone:  []string{"a"},
+		},
+	}
+
+	// Iterate through test cases
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := NewMemoryStatusStore(tc.limit)
+			for _, id := range tc.created {
+				assert.NoError(t, store.Create(NotificationStatus{ID: id}))
+			}
+
+			for _, id := range tc.expectedFound {
+				_, err := store.Get(id)
+				assert.NoError(t, err)
+			}
+			for _, id := range tc.expectedGone {
+				_, err := store.Get(id)
+				assert.Equal(t, ErrNotificationNotFound, err)
+			}
+		})
+	}
+}
+
+func TestMemoryStatusStore_Update(t *testing.T) {
+	store := NewMemoryStatusStore(10)
+
+	// Updating an unknown notification creates it
+	err := store.Update("id", "Slack", func(status *ChannelStatus) {
+		status.Status = StatusRetrying
+		status.Attempts++
+		status.LastError = "boom"
+	})
+	assert.NoError(t, err)
+
+	// Updating the same channel again changes the existing entry
+	err = store.Update("id", "Slack", func(status *ChannelStatus) {
+		status.Status = StatusDelivered
+		status.Attempts++
+	})
+	assert.NoError(t, err)
+
+	status, err := store.Get("id")
+	assert.NoError(t, err)
+	assert.Len(t, status.Channels, 1)
+	assert.Equal(t, StatusDelivered, status.Channels[0].Status)
+	assert.Equal(t, 2, status.Channels[0].Attempts)
+	assert.Equal(t, "boom", status.Channels[0].LastError)
+}
+
+func TestBoltStatusStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.db")
+	queue, err := NewBoltQueue(path)
+	require.NoError(t, err)
+	store := queue.Statuses(2)
+
+	// Statuses are created and updated per channel
+	require.NoError(t, store.Create(NotificationStatus{ID: "a", Channels: []ChannelStatus{{Channel: "Slack", Status: StatusQueued}}}))
+	require.NoError(t, store.Update("a", "Slack", func(status *ChannelStatus) {
+		status.Status = StatusDelivered
+		status.Attempts++
+	}))
+	require.NoError(t, store.Update("a", "Email", func(status *ChannelStatus) {
+		status.Status = StatusRetrying
+	}))
+	status, err := store.Get("a")
+	require.NoError(t, err)
+	require.Len(t, status.Channels, 2)
+	assert.Equal(t, StatusDelivered, status.Channels[0].Status)
+	assert.Equal(t, 1, status.Channels[0].Attempts)
+	assert.Equal(t, StatusRetrying, status.Channels[1].Status)
+
+	// Deleted statuses are gone and do not count towards the limit
+	require.NoError(t, store.Create(NotificationStatus{ID: "b"}))
+	require.NoError(t, store.Delete("b"))
+	_, err = store.Get("b")
+	assert.Equal(t, ErrNotificationNotFound, err)
+	require.NoError(t, store.Create(NotificationStatus{ID: "c"}))
+	_, err = store.Get("a")
+	assert.NoError(t, err)
+
+	// The oldest statuses are evicted above the limit
+	require.NoError(t, store.Update("d", "Slack", func(status *ChannelStatus) {}))
+	_, err = store.Get("a")
+	assert.Equal(t, ErrNotificationNotFound, err)
+
+	// Statuses are kept across restarts
+	require.NoError(t, queue.Close())
+	queue, err = NewBoltQueue(path)
+	require.NoError(t, err)
+	defer queue.Close()
+	for _, id := range []string{"c", "d"} {
+		_, err = queue.Statuses(2).Get(id)
+		assert.NoError(t, err, id)
+	}
+}
@@ -0,0 +1,42 @@
+package notification
+
+import (
+	"time"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+)
+
+// SubscriptionStore returns the store of Web Push subscriptions, which the Web Push
+// sender should be created with.
+func (n *Notifier) SubscriptionStore() channel.SubscriptionStore {
+	return n.subscriptions
+}
+
+// AddSubscription validates and registers a Web Push subscription of a user.
+func (n *Notifier) AddSubscription(subscription channel.PushSubscription) (channel.PushSubscription, error) {
+	if err := subscription.Validate(); err != nil {
+		return channel.PushSubscription{}, err
+	}
+	subscription.CreatedAt = time.Now()
+	if err := n.subscriptions.Add(subscription); err != nil {
+		return channel.PushSubscription{}, err
+	}
+	return subscription, nil
+}
+
+// ListSubscriptions returns the Web Push subscriptions of a user.
+func (n *Notifier) ListSubscriptions(userID string) ([]channel.PushSubscription, error) {
+	subscriptions, err := n.subscriptions.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	if subscriptions == nil {
+		subscriptions = []channel.PushSubscription{}
+	}
+	return subscriptions, nil
+}
+
+// RemoveSubscription unregisters a Web Push subscription of a user.
+func (n *Notifier) RemoveSubscription(userID, endpoint string) error {
+	return n.subscriptions.Remove(userID, endpoint)
+}
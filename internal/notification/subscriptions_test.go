@@ -0,0 +1,88 @@
+package notification
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"path/filepath"
+	"testing"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionStores(t *testing.T) {
+	boltQueue, err := NewBoltQueue(filepath.Join(t.TempDir(), "queue.db"))
+	require.NoError(t, err)
+	defer boltQueue.Close()
+
+	// Define test cases
+	testCases := []struct {
+		name  string
+		store channel.SubscriptionStore
+	}{
+		{
+			name:  "Memory store",
+			store: channel.NewMemorySubscriptionStore(),
+		},
+		{
+			name:  "Bolt store",
+			store: boltQueue.Subscriptions(),
+		},
+	}
+
+	// Iterate through test cases
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			n := NewNotifier(0, WithSubscriptionStore(tc.store))
+
+			// Invalid subscriptions are rejected
+			_, err := n.AddSubscription(channel.PushSubscription{UserID: "alice", Endpoint: "https://push.example.com/1"})
+			assert.ErrorIs(t, err, channel.ErrInvalidSubscription)
+
+			// Register subscriptions out of order, replacing duplicates
+			for _, subscription := range []channel.PushSubscription{
+				newSubscription(t, "alice", "https://push.example.com/2"),
+				newSubscription(t, "alice", "https://push.example.com/1"),
+				newSubscription(t, "alice", "https://push.example.com/1"),
+				newSubscription(t, "alicia", "https://push.example.com/3"),
+			} {
+				added, err := n.AddSubscription(subscription)
+				require.NoError(t, err)
+				assert.False(t, added.CreatedAt.IsZero())
+			}
+
+			// List returns the subscriptions of the user ordered by endpoint
+			subscriptions, err := n.ListSubscriptions("alice")
+			require.NoError(t, err)
+			require.Len(t, subscriptions, 2)
+			assert.Equal(t, "https://push.example.com/1", subscriptions[0].Endpoint)
+			assert.Equal(t, "https://push.example.com/2", subscriptions[1].Endpoint)
+
+			// Remove unregisters a subscription
+			assert.NoError(t, n.RemoveSubscription("alice", "https://push.example.com/1"))
+			assert.Equal(t, channel.ErrSubscriptionNotFound, n.RemoveSubscription("alice", "https://push.example.com/1"))
+			subscriptions, err = n.ListSubscriptions("bob")
+			require.NoError(t, err)
+			assert.Empty(t, subscriptions)
+		})
+	}
+}
+
+// newSubscription returns a valid Web Push subscription with new keys.
+func newSubscription(t *testing.T, userID, endpoint string) channel.PushSubscription {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	auth := make([]byte, 16)
+	_, err = rand.Read(auth)
+	require.NoError(t, err)
+	return channel.PushSubscription{
+		UserID:   userID,
+		Endpoint: endpoint,
+		Keys: channel.PushSubscriptionKeys{
+			P256dh: base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+			Auth:   base64.RawURLEncoding.EncodeToString(auth),
+		},
+	}
+}
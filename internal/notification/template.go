@@ -0,0 +1,383 @@
+package notification
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+)
+
+var (
+	ErrTemplateNotFound    = errors.New("template not found")
+	ErrTemplateExists      = errors.New("template already exists")
+	ErrInvalidTemplate     = errors.New("invalid template")
+	ErrInvalidNotification = errors.New("invalid notification")
+)
+
+// templateNamePattern restricts template names to characters that are safe in URLs.
+var templateNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,127}$`)
+
+// Template is a named message template. The Default variant is used for channels
+// without a variant of their own in Channels.
+type Template struct {
+	Name        string                     `json:"name"`
+	Description string                     `json:"description,omitempty"`
+	Default     TemplateVariant            `json:"default"`
+	Channels    map[string]TemplateVariant `json:"channels,omitempty"` // Variants keyed by channel name.
+	CreatedAt   time.Time                  `json:"created_at"`
+	UpdatedAt   time.Time                  `json:"updated_at"`
+}
+
+// TemplateVariant holds the templates for the fields of a message. HTML is rendered with
+// html/template, all other fields with text/template. Fields left empty in a channel
+// variant fall back to the Default variant.
+type TemplateVariant struct {
+	Title    string           `json:"title,omitempty"`
+	Body     string           `json:"body,omitempty"`
+	Markdown string           `json:"markdown,omitempty"`
+	HTML     string           `json:"html,omitempty"`
+	Severity channel.Severity `json:"severity,omitempty"`
+	Tags     []string         `json:"tags,omitempty"`
+
+	// CorrelationKey groups related messages, for example "host-down-{{.host}}".
+	CorrelationKey string `json:"correlation_key,omitempty"`
+}
+
+// TemplateStore keeps the message templates.
+type TemplateStore interface {
+	// Create stores a new template or returns ErrTemplateExists.
+	Create(t Template) error
+	// Update replaces an existing template or returns ErrTemplateNotFound.
+	Update(t Template) error
+	// Get returns a template or ErrTemplateNotFound.
+	Get(name string) (Template, error)
+	// List returns all templates ordered by name.
+	List() ([]Template, error)
+	// Delete removes a template or returns ErrTemplateNotFound.
+	Delete(name string) error
+}
+
+// Validate checks the template name and that all variants parse and have content.
+func (t Template) Validate() error {
+	t.normalize()
+	if !templateNamePattern.MatchString(t.Name) {
+		return fmt.Errorf("%w: name must be 1-128 letters, digits, '.', '_' or '-'", ErrInvalidTemplate)
+	}
+	if err := t.Default.parse("default"); err != nil {
+		return err
+	}
+	for channelName := range t.Channels {
+		variant := t.variant(channelName, "")
+		if err := variant.parse(channelName); err != nil {
+			return err
+		}
+		if variant.empty() {
+			return fmt.Errorf("%w: %s variant has no content", ErrInvalidTemplate, channelName)
+		}
+	}
+	if t.Default.empty() && len(t.Channels) == 0 {
+		return fmt.Errorf("%w: template has no content", ErrInvalidTemplate)
+	}
+	return nil
+}
+
+// Render renders the variant for a channel with the given data. Referencing data
+// that is missing is an error rather than rendering "<no value>".
+func (t Template) Render(channelName string, data map[string]any) (channel.Message, error) {
+	return t.render(channelName, "", data)
+}
+
+// render renders the variant for a channel, or the one of its type when the channel
+// has no variant of its own.
+func (t Template) render(channelName, channelType string, data map[string]any) (channel.Message, error) {
+	variant := t.variant(channelName, channelType)
+	if variant.empty() {
+		return channel.Message{}, fmt.Errorf("%w: %s has no variant for channel %s", ErrInvalidTemplate, t.Name, channelName)
+	}
+
+	message := channel.Message{
+		Severity: variant.Severity,
+		Tags:     variant.Tags,
+	}
+	var err error
+	if message.Title, err = renderText("title", variant.Title, data); err != nil {
+		return channel.Message{}, err
+	}
+	if message.Body, err = renderText("body", variant.Body, data); err != nil {
+		return channel.Message{}, err
+	}
+	if message.Markdown, err = renderText("markdown", variant.Markdown, data); err != nil {
+		return channel.Message{}, err
+	}
+	if message.HTML, err = renderHTML("html", variant.HTML, data); err != nil {
+		return channel.Message{}, err
+	}
+	if message.CorrelationKey, err = renderText("correlation_key", variant.CorrelationKey, data); err != nil {
+		return channel.Message{}, err
+	}
+	if err := message.Validate(); err != nil {
+		return channel.Message{}, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	return message, nil
+}
+
+// normalize lower-cases the channel names of the variants, as channels are matched case-insensitively.
+func (t *Template) normalize() {
+	if len(t.Channels) == 0 {
+		t.Channels = nil
+		return
+	}
+	channels := make(map[string]TemplateVariant, len(t.Channels))
+	for channelName, variant := range t.Channels {
+		channels[strings.ToLower(channelName)] = variant
+	}
+	t.Channels = channels
+}
+
+// variant returns the variant for a channel, or else for its type, with empty fields
+// taken from the Default variant.
+func (t Template) variant(channelName, channelType string) TemplateVariant {
+	variant, found := t.Channels[strings.ToLower(channelName)]
+	if !found && channelType != "" {
+		variant, found = t.Channels[strings.ToLower(channelType)]
+	}
+	if !found {
+		return t.Default
+	}
+	if variant.Title == "" {
+		variant.Title = t.Default.Title
+	}
+	if variant.Body == "" {
+		variant.Body = t.Default.Body
+	}
+	if variant.Markdown == "" {
+		variant.Markdown = t.Default.Markdown
+	}
+	if variant.HTML == "" {
+		variant.HTML = t.Default.HTML
+	}
+	if variant.Severity == "" {
+		variant.Severity = t.Default.Severity
+	}
+	if variant.Tags == nil {
+		variant.Tags = t.Default.Tags
+	}
+	if variant.CorrelationKey == "" {
+		variant.CorrelationKey = t.Default.CorrelationKey
+	}
+	return variant
+}
+
+// empty reports whether the variant has no content to render.
+func (v TemplateVariant) empty() bool {
+	return v.Title == "" && v.Body == "" && v.Markdown == "" && v.HTML == ""
+}
+
+// parse checks that all fields of the variant are valid templates.
+func (v TemplateVariant) parse(variantName string) error {
+	for field, text := range map[string]string{"title": v.Title, "body": v.Body, "markdown": v.Markdown, "correlation_key": v.CorrelationKey} {
+		if _, err := template.New(field).Parse(text); err != nil {
+			return fmt.Errorf("%w: %s variant: %v", ErrInvalidTemplate, variantName, err)
+		}
+	}
+	if _, err := htmltemplate.New("html").Parse(v.HTML); err != nil {
+		return fmt.Errorf("%w: %s variant: %v", ErrInvalidTemplate, variantName, err)
+	}
+	if err := (channel.Message{Body: "-", Severity: v.Severity}).Validate(); err != nil {
+		return fmt.Errorf("%w: %s variant: %v", ErrInvalidTemplate, variantName, err)
+	}
+	return nil
+}
+
+// renderText renders a text/template, returning an empty string for an empty template.
+func renderText(name, text string, data map[string]any) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	return buf.String(), nil
+}
+
+// renderHTML renders an html/template, escaping the data for use in HTML.
+func renderHTML(name, text string, data map[string]any) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+	tmpl, err := htmltemplate.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	return buf.String(), nil
+}
+
+// MemoryTemplateStore is an in-memory TemplateStore. Its contents are lost when the process exits.
+type MemoryTemplateStore struct {
+	mu        sync.RWMutex
+	templates map[string]Template
+}
+
+// NewMemoryTemplateStore creates a new MemoryTemplateStore.
+func NewMemoryTemplateStore() *MemoryTemplateStore {
+	return &MemoryTemplateStore{
+		templates: make(map[string]Template),
+	}
+}
+
+// Create stores a new template.
+func (s *MemoryTemplateStore) Create(t Template) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.templates[t.Name]; found {
+		return ErrTemplateExists
+	}
+	s.templates[t.Name] = t
+	return nil
+}
+
+// Update replaces an existing template.
+func (s *MemoryTemplateStore) Update(t Template) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.templates[t.Name]; !found {
+		return ErrTemplateNotFound
+	}
+	s.templates[t.Name] = t
+	return nil
+}
+
+// Get returns a template by its name.
+func (s *MemoryTemplateStore) Get(name string) (Template, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, found := s.templates[name]
+	if !found {
+		return Template{}, ErrTemplateNotFound
+	}
+	return t, nil
+}
+
+// List returns all templates ordered by name.
+func (s *MemoryTemplateStore) List() ([]Template, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	templates := make([]Template, 0, len(s.templates))
+	for _, t := range s.templates {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates, nil
+}
+
+// Delete removes a template by its name.
+func (s *MemoryTemplateStore) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.templates[name]; !found {
+		return ErrTemplateNotFound
+	}
+	delete(s.templates, name)
+	return nil
+}
+
+// ListTemplates returns all message templates.
+func (n *Notifier) ListTemplates() ([]Template, error) {
+	return n.templates.List()
+}
+
+// GetTemplate returns a message template by its name.
+func (n *Notifier) GetTemplate(name string) (Template, error) {
+	return n.templates.Get(name)
+}
+
+// CreateTemplate validates and stores a new message template.
+func (n *Notifier) CreateTemplate(t Template) (Template, error) {
+	if err := t.Validate(); err != nil {
+		return Template{}, err
+	}
+	t.normalize()
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = t.CreatedAt
+	if err := n.templates.Create(t); err != nil {
+		return Template{}, err
+	}
+	return t, nil
+}
+
+// UpdateTemplate validates and replaces an existing message template.
+func (n *Notifier) UpdateTemplate(t Template) (Template, error) {
+	existing, err := n.templates.Get(t.Name)
+	if err != nil {
+		return Template{}, err
+	}
+	if err := t.Validate(); err != nil {
+		return Template{}, err
+	}
+	t.normalize()
+	t.CreatedAt = existing.CreatedAt
+	t.UpdatedAt = time.Now()
+	if err := n.templates.Update(t); err != nil {
+		return Template{}, err
+	}
+	return t, nil
+}
+
+// DeleteTemplate removes a message template. Queued notifications using it fail permanently.
+func (n *Notifier) DeleteTemplate(name string) error {
+	return n.templates.Delete(name)
+}
+
+// RenderTemplate renders a template for a channel with the given data, using the variant
+// of the channel's type when the channel has none of its own.
+func (n *Notifier) RenderTemplate(name, channelName string, data map[string]any) (channel.Message, error) {
+	t, err := n.templates.Get(name)
+	if err != nil {
+		return channel.Message{}, err
+	}
+	return t.render(channelName, n.channelType(channelName), data)
+}
+
+// renderMessage returns the message of a notification, rendering its template as it is
+// now. The recipients, action and push options of the request are kept, as is its
+// correlation key unless the template sets one.
+func (n *Notifier) renderMessage(notification Notification) (channel.Message, error) {
+	if notification.Template == "" {
+		return notification.Message, nil
+	}
+	message, err := n.RenderTemplate(notification.Template, notification.Channel, notification.Data)
+	if err != nil {
+		return channel.Message{}, err
+	}
+	message.Recipients = notification.Message.Recipients
+	message.Action = notification.Message.Action
+	message.Push = notification.Message.Push
+	if message.CorrelationKey == "" {
+		message.CorrelationKey = notification.Message.CorrelationKey
+	}
+	return message, nil
+}
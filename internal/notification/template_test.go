@@ -0,0 +1,243 @@
+package notification
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateStores(t *testing.T) {
+	boltQueue, err := NewBoltQueue(filepath.Join(t.TempDir(), "queue.db"))
+	require.NoError(t, err)
+	defer boltQueue.Close()
+
+	// Define test cases
+	testCases := []struct {
+		name  string
+		store TemplateStore
+	}{
+		{
+			name:  "Memory store",
+			store: NewMemoryTemplateStore(),
+		},
+		{
+			name:  "Bolt store",
+			store: boltQueue.Templates(),
+		},
+	}
+
+	// Iterate through test cases
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			alerts := Template{Name: "alerts", Default: TemplateVariant{Body: "{{.text}}"}}
+			deploys := Template{Name: "deploys", Default: TemplateVariant{Body: "deployed"}}
+
+			// Create templates out of order, rejecting duplicates
+			require.NoError(t, tc.store.Create(deploys))
+			require.NoError(t, tc.store.Create(alerts))
+			assert.Equal(t, ErrTemplateExists, tc.store.Create(alerts))
+
+			// List returns the templates ordered by name
+			templates, err := tc.store.List()
+			require.NoError(t, err)
+			require.Len(t, templates, 2)
+			assert.Equal(t, "alerts", templates[0].Name)
+			assert.Equal(t, "deploys", templates[1].Name)
+
+			// Update replaces existing templates only
+			alerts.Default.Body = "changed"
+			require.NoError(t, tc.store.Update(alerts))
+			stored, err := tc.store.Get("alerts")
+			require.NoError(t, err)
+			assert.Equal(t, "changed", stored.Default.Body)
+			assert.Equal(t, ErrTemplateNotFound, tc.store.Update(Template{Name: "unknown"}))
+
+			// Delete removes a template
+			assert.NoError(t, tc.store.Delete("alerts"))
+			_, err = tc.store.Get("alerts")
+			assert.Equal(t, ErrTemplateNotFound, err)
+			assert.Equal(t, ErrTemplateNotFound, tc.store.Delete("alerts"))
+		})
+	}
+}
+
+func TestTemplate_Validate(t *testing.T) {
+	// Define test cases
+	testCases := []struct {
+		name        string
+		template    Template
+		expectedErr error
+	}{
+		{
+			name:     "Default variant only",
+			template: Template{Name: "alerts", Default: TemplateVariant{Body: "{{.text}}"}},
+		},
+		{
+			name:     "Channel variants only",
+			template: Template{Name: "alerts", Channels: map[string]TemplateVariant{"Slack": {Markdown: "*{{.text}}*"}}},
+		},
+		{
+			name:        "Invalid name",
+			template:    Template{Name: "alerts/prod", Default: TemplateVariant{Body: "text"}},
+			expectedErr: ErrInvalidTemplate,
+		},
+		{
+			name:        "Syntax error",
+			template:    Template{Name: "alerts", Default: TemplateVariant{Body: "{{.text"}},
+			expectedErr: ErrInvalidTemplate,
+		},
+		{
+			name:        "Syntax error in HTML variant",
+			template:    Template{Name: "alerts", Default: TemplateVariant{Body: "text"}, Channels: map[string]TemplateVariant{"email": {HTML: "{{if}}"}}},
+			expectedErr: ErrInvalidTemplate,
+		},
+		{
+			name:        "Unknown severity",
+			template:    Template{Name: "alerts", Default: TemplateVariant{Body: "text", Severity: "panic"}},
+			expectedErr: ErrInvalidTemplate,
+		},
+		{
+			name:        "No content",
+			template:    Template{Name: "alerts"},
+			expectedErr: ErrInvalidTemplate,
+		},
+	}
+
+	// Iterate through test cases
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.template.Validate()
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestTemplate_Render(t *testing.T) {
+	tmpl := Template{
+		Name: "alerts",
+		Default: TemplateVariant{
+			Title:          "{{.host}} is down",
+			Body:           "{{.host}} stopped responding",
+			Severity:       channel.SeverityCritical,
+			CorrelationKey: "down-{{.host}}",
+		},
+		Channels: map[string]TemplateVariant{
+			"slack": {Markdown: "*{{.host}}* stopped responding"},
+			"email": {HTML: "<b>{{.host}}</b> stopped responding"},
+			"sms":   {Title: "-", Body: "DOWN {{.host}}"},
+		},
+	}
+	data := map[string]any{"host": "<db-1>"}
+
+	// Define test cases
+	testCases := []struct {
+		name        string
+		channel     string
+		data        map[string]any
+		expected    channel.Message
+		expectedErr error
+	}{
+		{
+			name:    "Default variant",
+			channel: "Discord",
+			data:    data,
+			expected: channel.Message{
+				Title:          "<db-1> is down",
+				Body:           "<db-1> stopped responding",
+				Severity:       channel.SeverityCritical,
+				CorrelationKey: "down-<db-1>",
+			},
+		},
+		{
+			name:    "Channel variant falls back to the default fields",
+			channel: "Slack",
+			data:    data,
+			expected: channel.Message{
+				Title:          "<db-1> is down",
+				Body:           "<db-1> stopped responding",
+				Markdown:       "*<db-1>* stopped responding",
+				Severity:       channel.SeverityCritical,
+				CorrelationKey: "down-<db-1>",
+			},
+		},
+		{
+			name:    "HTML is escaped",
+			channel: "email",
+			data:    data,
+			expected: channel.Message{
+				Title:          "<db-1> is down",
+				Body:           "<db-1> stopped responding",
+				HTML:           "<b>&lt;db-1&gt;</b> stopped responding",
+				Severity:       channel.SeverityCritical,
+				CorrelationKey: "down-<db-1>",
+			},
+		},
+		{
+			name:        "Missing data",
+			channel:     "sms",
+			data:        map[string]any{},
+			expectedErr: ErrInvalidTemplate,
+		},
+	}
+
+	// Iterate through test cases
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			message, err := tmpl.Render(tc.channel, tc.data)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, message)
+		})
+	}
+}
+
+func TestEnqueueTemplatedNotifications(t *testing.T) {
+	n := NewNotifier(0)
+	_, err := n.CreateTemplate(Template{Name: "alerts", Default: TemplateVariant{Body: "{{.host}} is down"}})
+	require.NoError(t, err)
+
+	// Define test cases
+	testCases := []struct {
+		name         string
+		notification Notification
+		expectedErr  error
+	}{
+		{
+			name:         "Known template with data",
+			notification: Notification{Channel: "foo", Template: "alerts", Data: map[string]any{"host": "db-1"}},
+		},
+		{
+			name:         "Unknown template",
+			notification: Notification{Channel: "foo", Template: "unknown"},
+			expectedErr:  ErrTemplateNotFound,
+		},
+		{
+			name:         "Missing data",
+			notification: Notification{Channel: "foo", Template: "alerts"},
+			expectedErr:  ErrInvalidTemplate,
+		},
+	}
+
+	// Iterate through test cases
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := n.EnqueueNotifications([]Notification{tc.notification})
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidNotification)
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package notification
+
+import (
+	"errors"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+)
+
+var (
+	ErrTokenNotFound = errors.New("invalid token not found")
+)
+
+// TokenStore returns the store of device tokens invalidated by the push channels, which
+// push senders should be created with.
+func (n *Notifier) TokenStore() channel.TokenStore {
+	return n.tokens
+}
+
+// ListInvalidTokens returns the device tokens push providers reported as no longer valid.
+func (n *Notifier) ListInvalidTokens() ([]channel.InvalidToken, error) {
+	tokens, err := n.tokens.List()
+	if err != nil {
+		return nil, err
+	}
+	if tokens == nil {
+		tokens = []channel.InvalidToken{}
+	}
+	return tokens, nil
+}
+
+// RestoreToken forgets that a device token was invalidated, so it is sent to again.
+func (n *Notifier) RestoreToken(channelName, token string) error {
+	invalid, err := n.tokens.IsInvalid(channelName, token)
+	if err != nil {
+		return err
+	}
+	if !invalid {
+		return ErrTokenNotFound
+	}
+	return n.tokens.Delete(channelName, token)
+}
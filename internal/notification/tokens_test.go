@@ -0,0 +1,59 @@
+package notification
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/phgermanov/notification-service/internal/channel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenStores(t *testing.T) {
+	boltQueue, err := NewBoltQueue(filepath.Join(t.TempDir(), "queue.db"))
+	require.NoError(t, err)
+	defer boltQueue.Close()
+
+	// Define test cases
+	testCases := []struct {
+		name  string
+		store channel.TokenStore
+	}{
+		{
+			name:  "Memory store",
+			store: channel.NewMemoryTokenStore(),
+		},
+		{
+			name:  "Bolt store",
+			store: boltQueue.Tokens(),
+		},
+	}
+
+	// Iterate through test cases
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			n := NewNotifier(0, WithTokenStore(tc.store))
+			fcm := channel.InvalidToken{Channel: "FCM", Token: "device-2", Reason: "UNREGISTERED"}
+			apns := channel.InvalidToken{Channel: "APNs", Token: "device-1", Reason: "Unregistered"}
+
+			// Nothing is invalidated yet
+			tokens, err := n.ListInvalidTokens()
+			require.NoError(t, err)
+			assert.Empty(t, tokens)
+
+			// List returns the tokens ordered by channel and token
+			require.NoError(t, tc.store.Invalidate(fcm))
+			require.NoError(t, tc.store.Invalidate(apns))
+			tokens, err = n.ListInvalidTokens()
+			require.NoError(t, err)
+			assert.Equal(t, []string{"device-1", "device-2"}, []string{tokens[0].Token, tokens[1].Token})
+
+			// Restoring a token makes it valid again, with channels matched case-insensitively
+			assert.NoError(t, n.RestoreToken("fcm", "device-2"))
+			invalid, err := tc.store.IsInvalid("FCM", "device-2")
+			require.NoError(t, err)
+			assert.False(t, invalid)
+			assert.Equal(t, ErrTokenNotFound, n.RestoreToken("FCM", "device-2"))
+		})
+	}
+}
@@ -1,14 +1,19 @@
 package notification
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log"
 	"time"
+
+	"github.com/phgermanov/notification-service/internal/channel"
 )
 
 var (
-	ErrRetryFailed     = errors.New("failed to send notification after multiple retries")
-	ErrChannelNotFound = errors.New("channel not found")
+	ErrRetryFailed      = errors.New("failed to send notification after multiple retries")
+	ErrChannelNotFound  = errors.New("channel not found")
+	ErrDeadlineExceeded = errors.New("notification deadline exceeded")
 )
 
 // Worker is an interface that defines the behavior of a notification worker.
@@ -27,41 +32,160 @@ func NewNotifierWorker(notifier *Notifier, workerID int) *NotifierWorker {
 	return &NotifierWorker{Notifier: notifier, WorkerID: workerID}
 }
 
-// Start starts the worker to process notifications.
+// Start starts the worker to process notifications until the notifier shuts down.
 func (w *NotifierWorker) Start() {
 	defer w.wg.Done()
-	for notification := range w.notificationPool {
-		w.handleNotification(notification)
+	for {
+		item, err := w.queue.Dequeue(w.workerCtx)
+		if errors.Is(err, ErrQueueClosed) || errors.Is(err, context.Canceled) {
+			return
+		}
+		if err != nil {
+			log.Printf("error: worker %d failed to dequeue notification: %v\n", w.WorkerID, err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		w.handleNotification(item)
 	}
 }
 
-// handleNotification processes a single notification.
-func (w *NotifierWorker) handleNotification(notification Notification) {
+// handleNotification makes one attempt to send a notification. Failed notifications are
+// scheduled on the delay queue according to the channel's retry policy, so the worker can
+// move on to other notifications while waiting. Notifications that are done are acknowledged.
+func (w *NotifierWorker) handleNotification(item QueuedNotification) {
+	notification := item.Notification
 	log.Printf("worker %d processing notification: %+v\n", w.WorkerID, notification)
-	if err := w.trySendNotification(notification, 3); err != nil {
-		log.Printf("error: Failed to send notification after multiple tries: %v\n", err)
+
+	notification.Attempts++
+	w.updateStatus(notification, func(status *ChannelStatus) {
+		status.Status = StatusSending
+		status.Attempts = notification.Attempts
+		status.NextAttemptAt = nil
+	})
+
+	// Track the targets delivered to, so retries skip them.
+	progress := channel.NewProgress(notification.Delivered)
+	ctx, cancel := w.sendContext(notification)
+	err := w.sendNotification(channel.WithProgress(ctx, progress), notification)
+	cancel()
+	notification.Delivered = progress.Targets()
+	if err == nil {
+		w.updateStatus(notification, func(status *ChannelStatus) {
+			status.Status = StatusDelivered
+			status.LastError = ""
+		})
+		w.ack(item)
+		return
+	}
+
+	// Leave the notification to the queue backend when the send was aborted by shutdown.
+	if w.sendCtx.Err() != nil {
+		log.Printf("worker %d abandoned notification %s during shutdown: %v\n", w.WorkerID, notification.ID, err)
+		return
+	}
+
+	// Give up on permanent errors or once the retry policy is exhausted.
+	delay, retry := w.retryPolicy(notification.Channel).NextAttempt(notification, err, time.Now())
+	if !retry {
+		if !IsPermanent(err) {
+			err = fmt.Errorf("%w: %v", ErrRetryFailed, err)
+		}
+		log.Printf("error: Failed to send notification after %d attempts: %v\n", notification.Attempts, err)
+		w.updateStatus(notification, func(status *ChannelStatus) {
+			status.Status = StatusFailed
+			status.LastError = err.Error()
+		})
+		w.deadLetter(notification, err)
+		w.ack(item)
+		return
+	}
+
+	nextAttemptAt := time.Now().Add(delay)
+	w.updateStatus(notification, func(status *ChannelStatus) {
+		status.Status = StatusRetrying
+		status.LastError = err.Error()
+		status.NextAttemptAt = &nextAttemptAt
+	})
+	log.Printf("retrying notification %s on %s in %v: %v\n", notification.ID, notification.Channel, delay, err)
+	item.Notification = notification
+	if !w.delays.Schedule(item, nextAttemptAt) {
+		log.Printf("notification %s not rescheduled as the notifier is shutting down\n", notification.ID)
+	}
+}
+
+// ack acknowledges a notification so it is not processed again after a restart.
+func (w *NotifierWorker) ack(item QueuedNotification) {
+	if err := w.queue.Ack(item); err != nil {
+		log.Printf("error: worker %d failed to acknowledge notification: %v\n", w.WorkerID, err)
 	}
 }
 
-// trySendNotification tries to send a notification multiple times with retries.
-func (w *NotifierWorker) trySendNotification(notification Notification, maxRetries int) error {
-	for retries := 0; retries < maxRetries; retries++ {
-		if err := w.sendNotification(notification); err == nil {
-			return nil
+// deadLetter moves a notification that failed permanently to the dead-letter store.
+func (w *NotifierWorker) deadLetter(notification Notification, err error) {
+	id, idErr := newID()
+	if idErr != nil {
+		log.Printf("error: failed to dead-letter notification %s: %v\n", notification.ID, idErr)
+		return
+	}
+	letter := DeadLetter{
+		ID:           id,
+		Notification: notification,
+		Attempts:     notification.Attempts,
+		Error:        err.Error(),
+		FailedAt:     time.Now(),
+	}
+	if err := w.deadLetters.Add(letter); err != nil {
+		log.Printf("error: failed to dead-letter notification %s: %v\n", notification.ID, err)
+	}
+}
+
+// updateStatus records a change in the delivery status of a notification.
+func (w *NotifierWorker) updateStatus(notification Notification, fn func(status *ChannelStatus)) {
+	if err := w.statuses.Update(notification.ID, notification.Channel, fn); err != nil {
+		log.Printf("error: failed to update status of notification %s: %v\n", notification.ID, err)
+	}
+}
+
+// sendContext returns the context for a single send, bounded by the channel's send
+// timeout and the notification's deadline and cancelled when shutdown gives up waiting.
+// It carries the notification ID for senders that refer to it.
+func (w *NotifierWorker) sendContext(notification Notification) (context.Context, context.CancelFunc) {
+	ctx, cancel := channel.WithNotificationID(w.sendCtx, notification.ID), context.CancelFunc(func() {})
+	if timeout := w.sendTimeout(notification.Channel); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	if !notification.Deadline.IsZero() {
+		deadlineCtx, deadlineCancel := context.WithDeadline(ctx, notification.Deadline)
+		timeoutCancel := cancel
+		ctx, cancel = deadlineCtx, func() {
+			deadlineCancel()
+			timeoutCancel()
 		}
-		log.Printf("retrying in %v\n", w.retryDuration)
-		time.Sleep(w.retryDuration)
 	}
-	return ErrRetryFailed
+	return ctx, cancel
 }
 
 // sendNotification sends the notification to the specified channels.
-func (w *NotifierWorker) sendNotification(notification Notification) error {
+func (w *NotifierWorker) sendNotification(ctx context.Context, notification Notification) error {
+	if !notification.Deadline.IsZero() && !time.Now().Before(notification.Deadline) {
+		return ErrDeadlineExceeded
+	}
 	channelSender, err := w.getChannelSender(notification.Channel)
 	if err != nil {
 		return err
 	}
-	if err := channelSender.Send(notification.Message); err != nil {
+
+	// Render templated notifications with the template as it is now.
+	message, err := w.renderMessage(notification)
+	if err != nil {
+		return err
+	}
+	if err := channelSender.Send(ctx, message); err != nil {
+		// Report a send cut short by the notification's deadline as such.
+		if !notification.Deadline.IsZero() && !time.Now().Before(notification.Deadline) {
+			return fmt.Errorf("%w: %v", ErrDeadlineExceeded, err)
+		}
 		return err
 	}
 	return nil
@@ -1,14 +1,17 @@
 package notification
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/phgermanov/notification-service/internal/channel"
 	"github.com/phgermanov/notification-service/internal/channel/channelfakes"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestSendWithRetries(t *testing.T) {
+func TestRetries(t *testing.T) {
 	mockName := "mock"
 
 	// Define test cases
@@ -16,8 +19,8 @@ func TestSendWithRetries(t *testing.T) {
 		name              string
 		mockSender        *channelfakes.FakeSender
 		setup             func(m *channelfakes.FakeSender)
-		expectedErr       error
 		expectedCallCount int
+		expectedStatus    Status
 	}{
 		{
 			name: "SuccessWithZeroRetries",
@@ -25,8 +28,8 @@ func TestSendWithRetries(t *testing.T) {
 				mockSender.GetNameReturns(mockName)
 			},
 			mockSender:        new(channelfakes.FakeSender),
-			expectedErr:       nil,
 			expectedCallCount: 1,
+			expectedStatus:    StatusDelivered,
 		},
 		{
 			name: "SuccessAfterOneRetry",
@@ -36,8 +39,8 @@ func TestSendWithRetries(t *testing.T) {
 				mockSender.SendReturnsOnCall(1, nil)
 			},
 			mockSender:        new(channelfakes.FakeSender),
-			expectedErr:       nil,
 			expectedCallCount: 2,
+			expectedStatus:    StatusDelivered,
 		},
 		{
 			name: "FailedRetries",
@@ -46,17 +49,17 @@ func TestSendWithRetries(t *testing.T) {
 				mockSender.SendReturns(errors.New("some error"))
 			},
 			mockSender:        new(channelfakes.FakeSender),
-			expectedErr:       ErrRetryFailed,
 			expectedCallCount: 3,
+			expectedStatus:    StatusFailed,
 		},
 	}
 
 	// Iterate through test cases
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Create a new Notifier and NotifierWorker
-			n := NewNotifier(0)
-			nw := NewNotifierWorker(n, 0)
+			// Create a new Notifier retrying quickly with a single worker
+			n := NewNotifier(time.Millisecond)
+			n.StartWorkers(1)
 
 			// Perform setup if provided
 			if tt.setup != nil {
@@ -64,22 +67,23 @@ func TestSendWithRetries(t *testing.T) {
 			}
 			n.AddChannelSender(tt.mockSender)
 
-			// Create a test notification
-			notification := Notification{
+			// Enqueue a test notification
+			id, err := n.EnqueueNotifications([]Notification{{
 				Channel: tt.mockSender.GetName(),
-				Message: "test message",
-			}
+				Message: channel.Message{Body: "test message"},
+			}})
+			assert.NoError(t, err)
 
-			// Call trySendNotification and assert results
-			err := nw.trySendNotification(notification, 3)
+			// Wait until the notification reaches its final status
+			assert.Eventually(t, func() bool {
+				status, err := n.GetStatus(id)
+				return err == nil && status.Channels[0].Status == tt.expectedStatus
+			}, time.Second, time.Millisecond)
 
-			// Compare actual and expected errors
-			if err != tt.expectedErr {
-				assert.EqualError(t, err, tt.expectedErr.Error())
-			}
-
-			// Check the number of Send calls made on the mockSender
-			assert.Equal(t, tt.mockSender.SendCallCount(), tt.expectedCallCount)
+			// Check the number of Send calls made on the mockSender and the recorded attempts
+			assert.Equal(t, tt.expectedCallCount, tt.mockSender.SendCallCount())
+			status, _ := n.GetStatus(id)
+			assert.Equal(t, tt.expectedCallCount, status.Channels[0].Attempts)
 		})
 	}
 }
@@ -89,70 +93,155 @@ func TestHandleNotification(t *testing.T) {
 
 	// Define test cases
 	tests := []struct {
-		name              string
-		mockSender        *channelfakes.FakeSender
-		setup             func(m *channelfakes.FakeSender)
-		expectedErr       error
-		expectedCallCount int
+		name                string
+		setup               func(m *channelfakes.FakeSender)
+		notification        Notification
+		policy              RetryPolicy
+		sendTimeout         time.Duration
+		expectedStatus      Status
+		expectedDelayed     int
+		expectedDeadLetters int
 	}{
 		{
-			name: "SuccessWithZeroRetries",
+			name: "Delivered",
 			setup: func(mockSender *channelfakes.FakeSender) {
 				mockSender.GetNameReturns(mockName)
 			},
-			mockSender:        new(channelfakes.FakeSender),
-			expectedErr:       nil,
-			expectedCallCount: 1,
+			notification:   Notification{Channel: mockName, CreatedAt: time.Now()},
+			policy:         RetryPolicy{MaxAttempts: 3},
+			expectedStatus: StatusDelivered,
 		},
 		{
-			name: "SuccessAfterOneRetry",
+			name: "Failure is scheduled for a retry",
 			setup: func(mockSender *channelfakes.FakeSender) {
 				mockSender.GetNameReturns(mockName)
 				mockSender.SendReturns(errors.New("some error"))
-				mockSender.SendReturnsOnCall(1, nil)
 			},
-			mockSender:        new(channelfakes.FakeSender),
-			expectedErr:       nil,
-			expectedCallCount: 2,
+			notification:    Notification{Channel: mockName, CreatedAt: time.Now()},
+			policy:          RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour},
+			expectedStatus:  StatusRetrying,
+			expectedDelayed: 1,
 		},
 		{
-			name: "FailedRetries",
+			name: "Failure on the last attempt is dead-lettered",
 			setup: func(mockSender *channelfakes.FakeSender) {
 				mockSender.GetNameReturns(mockName)
 				mockSender.SendReturns(errors.New("some error"))
 			},
-			mockSender:        new(channelfakes.FakeSender),
-			expectedErr:       ErrRetryFailed,
-			expectedCallCount: 3,
+			notification:        Notification{Channel: mockName, Attempts: 2, CreatedAt: time.Now()},
+			policy:              RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour},
+			expectedStatus:      StatusFailed,
+			expectedDeadLetters: 1,
+		},
+		{
+			name: "Permanent failure is dead-lettered without retries",
+			setup: func(mockSender *channelfakes.FakeSender) {
+				mockSender.GetNameReturns(mockName)
+				mockSender.SendReturns(channel.Permanent(errors.New("invalid token")))
+			},
+			notification:        Notification{Channel: mockName, CreatedAt: time.Now()},
+			policy:              RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour},
+			expectedStatus:      StatusFailed,
+			expectedDeadLetters: 1,
+		},
+		{
+			name: "Failure past the maximum age is dead-lettered",
+			setup: func(mockSender *channelfakes.FakeSender) {
+				mockSender.GetNameReturns(mockName)
+				mockSender.SendReturns(errors.New("some error"))
+			},
+			notification:        Notification{Channel: mockName, CreatedAt: time.Now().Add(-time.Hour)},
+			policy:              RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, MaxAge: time.Minute},
+			expectedStatus:      StatusFailed,
+			expectedDeadLetters: 1,
+		},
+		{
+			name: "Send timing out is scheduled for a retry",
+			setup: func(mockSender *channelfakes.FakeSender) {
+				mockSender.GetNameReturns(mockName)
+				mockSender.SendCalls(func(ctx context.Context, _ channel.Message) error {
+					<-ctx.Done()
+					return ctx.Err()
+				})
+			},
+			notification:    Notification{Channel: mockName, CreatedAt: time.Now()},
+			policy:          RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour},
+			sendTimeout:     10 * time.Millisecond,
+			expectedStatus:  StatusRetrying,
+			expectedDelayed: 1,
+		},
+		{
+			name: "Send cut short by the deadline is dead-lettered",
+			setup: func(mockSender *channelfakes.FakeSender) {
+				mockSender.GetNameReturns(mockName)
+				mockSender.SendCalls(func(ctx context.Context, _ channel.Message) error {
+					<-ctx.Done()
+					return ctx.Err()
+				})
+			},
+			notification:        Notification{Channel: mockName, CreatedAt: time.Now(), Deadline: time.Now().Add(100 * time.Millisecond)},
+			policy:              RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+			expectedStatus:      StatusFailed,
+			expectedDeadLetters: 1,
 		},
 	}
 
 	// Iterate through test cases
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Create a new Notifier
-			n := NewNotifier(0)
+			// Create a new Notifier with the retry policy and send timeout for the mock channel
+			n := NewNotifier(0, WithRetryPolicy(mockName, tt.policy), WithSendTimeout(mockName, tt.sendTimeout))
 			nw := NewNotifierWorker(n, 0)
+			defer n.delays.Stop()
 
 			// Perform setup if provided
+			mockSender := new(channelfakes.FakeSender)
 			if tt.setup != nil {
-				tt.setup(tt.mockSender)
-			}
-			n.AddChannelSender(tt.mockSender)
-
-			// Create a test notification
-			notification := Notification{
-				Channel: tt.mockSender.GetName(),
-				Message: "test message",
+				tt.setup(mockSender)
 			}
+			n.AddChannelSender(mockSender)
 
 			// Call handleNotification and assert results
-			nw.handleNotification(notification)
-			assert.Equal(t, tt.mockSender.SendCallCount(), tt.expectedCallCount)
+			tt.notification.ID = "id"
+			nw.handleNotification(QueuedNotification{Notification: tt.notification})
+			assert.Equal(t, 1, mockSender.SendCallCount())
+
+			status, err := n.GetStatus("id")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, status.Channels[0].Status)
+			assert.Equal(t, tt.notification.Attempts+1, status.Channels[0].Attempts)
+			assert.Equal(t, tt.expectedDelayed, n.delays.Len())
+
+			// Check that notifications failing permanently are dead-lettered
+			letters, err := n.ListDeadLetters()
+			assert.NoError(t, err)
+			assert.Len(t, letters, tt.expectedDeadLetters)
 		})
 	}
 }
 
+func TestHandleNotification_Progress(t *testing.T) {
+	n := NewNotifier(0, WithRetryPolicy("mock", RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour}))
+	nw := NewNotifierWorker(n, 0)
+
+	// The sender delivers to one of two targets
+	mockSender := new(channelfakes.FakeSender)
+	mockSender.GetNameReturns("mock")
+	mockSender.SendCalls(func(ctx context.Context, _ channel.Message) error {
+		progress := channel.ProgressFrom(ctx)
+		assert.True(t, progress.Done("a"))
+		progress.Mark("b")
+		return errors.New("c: some error")
+	})
+	n.AddChannelSender(mockSender)
+
+	// The retry carries the targets delivered in both attempts
+	nw.handleNotification(QueuedNotification{Notification: Notification{ID: "id", Channel: "mock", Delivered: []string{"a"}, CreatedAt: time.Now()}})
+	pending := n.delays.Stop()
+	assert.Len(t, pending, 1)
+	assert.Equal(t, []string{"a", "b"}, pending[0].Notification.Delivered)
+}
+
 func TestSendNotification(t *testing.T) {
 	mockName := "mock"
 
@@ -170,7 +259,7 @@ func TestSendNotification(t *testing.T) {
 			},
 			notification: Notification{
 				Channel: mockName,
-				Message: "Test message",
+				Message: channel.Message{Body: "Test message"},
 			},
 			expectedError: nil,
 		},
@@ -178,10 +267,33 @@ func TestSendNotification(t *testing.T) {
 			name: "Channel not found",
 			notification: Notification{
 				Channel: mockName,
-				Message: "Test message",
+				Message: channel.Message{Body: "Test message"},
 			},
 			expectedError: ErrChannelNotFound,
 		},
+		{
+			name: "Template removed before sending",
+			setup: func(notifier *Notifier) {
+				notifier.AddChannelSender(mockWithName(mockName))
+			},
+			notification: Notification{
+				Channel:  mockName,
+				Template: "removed",
+			},
+			expectedError: ErrTemplateNotFound,
+		},
+		{
+			name: "Deadline already passed",
+			setup: func(notifier *Notifier) {
+				notifier.AddChannelSender(mockWithName(mockName))
+			},
+			notification: Notification{
+				Channel:  mockName,
+				Message:  channel.Message{Body: "Test message"},
+				Deadline: time.Now().Add(-time.Second),
+			},
+			expectedError: ErrDeadlineExceeded,
+		},
 	}
 
 	// Iterate through test cases
@@ -197,8 +309,47 @@ func TestSendNotification(t *testing.T) {
 			}
 
 			// Call sendNotification and assert results
-			err := nw.sendNotification(tc.notification)
+			err := nw.sendNotification(context.Background(), tc.notification)
 			assert.Equal(t, tc.expectedError, err)
 		})
 	}
 }
+
+func TestSendNotification_RendersTemplate(t *testing.T) {
+	n := NewNotifier(0)
+	nw := NewNotifierWorker(n, 0)
+	mockSender := new(channelfakes.FakeSender)
+	mockSender.GetNameReturns("mock")
+	assert.NoError(t, n.AddChannelSender(mockSender))
+	_, err := n.CreateTemplate(Template{Name: "alerts", Default: TemplateVariant{Body: "{{.host}} is down"}})
+	assert.NoError(t, err)
+
+	// Send a templated notification with recipients, an action and push options
+	push := &channel.PushOptions{Sound: "default"}
+	err = nw.sendNotification(context.Background(), Notification{
+		Channel:  "mock",
+		Message:  channel.Message{Recipients: []string{"alice@example.com"}, Action: channel.ActionAcknowledge, Push: push},
+		Template: "alerts",
+		Data:     map[string]any{"host": "db-1"},
+	})
+	assert.NoError(t, err)
+
+	// The sender receives the rendered message for the recipients
+	_, message := mockSender.SendArgsForCall(0)
+	assert.Equal(t, channel.Message{Body: "db-1 is down", Recipients: []string{"alice@example.com"}, Action: channel.ActionAcknowledge, Push: push}, message)
+
+	// The correlation key of the request is kept unless the template sets one
+	_, err = n.CreateTemplate(Template{Name: "incidents", Default: TemplateVariant{Body: "{{.host}} is down", CorrelationKey: "host-{{.host}}"}})
+	assert.NoError(t, err)
+	for i, name := range []string{"alerts", "incidents"} {
+		err = nw.sendNotification(context.Background(), Notification{
+			Channel:  "mock",
+			Message:  channel.Message{CorrelationKey: "incident-1"},
+			Template: name,
+			Data:     map[string]any{"host": "db-1"},
+		})
+		assert.NoError(t, err)
+		_, message = mockSender.SendArgsForCall(i + 1)
+		assert.Equal(t, []string{"incident-1", "host-db-1"}[i], message.CorrelationKey)
+	}
+}
@@ -0,0 +1,137 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	signature := Sign([]byte("secret"), time.Unix(1700000000, 0), []byte(`{"body":"Hello"}`))
+	assert.Equal(t, "v1=cf1d9b59cf8b66f6bc2ea4f33ec09fddef6b51b45e2d2dff602310c151e8d758", signature)
+}
+
+func TestVerify(t *testing.T) {
+	secret := []byte("secret")
+	body := []byte(`{"body":"Hello"}`)
+	signedAt := time.Unix(1700000000, 0)
+	signature := Sign(secret, signedAt, body)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		body      string
+		now       time.Time
+		expectErr error
+	}{
+		{
+			name:      "Valid signature",
+			timestamp: "1700000000",
+			signature: signature,
+			body:      string(body),
+			now:       signedAt.Add(time.Minute),
+		},
+		{
+			name:      "One of several signatures is valid",
+			timestamp: "1700000000",
+			signature: "v1=00ff, " + signature,
+			body:      string(body),
+			now:       signedAt,
+		},
+		{
+			name:      "Missing signature",
+			timestamp: "1700000000",
+			body:      string(body),
+			now:       signedAt,
+			expectErr: ErrMissingSignature,
+		},
+		{
+			name:      "Malformed timestamp",
+			timestamp: "yesterday",
+			signature: signature,
+			body:      string(body),
+			now:       signedAt,
+			expectErr: ErrInvalidTimestamp,
+		},
+		{
+			name:      "Replayed request",
+			timestamp: "1700000000",
+			signature: signature,
+			body:      string(body),
+			now:       signedAt.Add(DefaultTolerance + time.Second),
+			expectErr: ErrTimestampExpired,
+		},
+		{
+			name:      "Timestamp too far in the future",
+			timestamp: "1700000000",
+			signature: signature,
+			body:      string(body),
+			now:       signedAt.Add(-DefaultTolerance - time.Second),
+			expectErr: ErrTimestampExpired,
+		},
+		{
+			name:      "Tampered body",
+			timestamp: "1700000000",
+			signature: signature,
+			body:      `{"body":"Goodbye"}`,
+			now:       signedAt,
+			expectErr: ErrInvalidSignature,
+		},
+		{
+			name:      "Tampered timestamp",
+			timestamp: "1700000001",
+			signature: signature,
+			body:      string(body),
+			now:       signedAt,
+			expectErr: ErrInvalidSignature,
+		},
+		{
+			name:      "Unknown signature version",
+			timestamp: "1700000000",
+			signature: strings.Replace(signature, "v1=", "v0=", 1),
+			body:      string(body),
+			now:       signedAt,
+			expectErr: ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set(TimestampHeader, tt.timestamp)
+			header.Set(SignatureHeader, tt.signature)
+
+			err := Verify(secret, header, []byte(tt.body), 0, tt.now)
+			if tt.expectErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expectErr)
+		})
+	}
+}
+
+func TestVerifyRequest(t *testing.T) {
+	secret := []byte("secret")
+	req := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(`{"body":"Hello"}`))
+	SignRequest(req, secret, time.Now(), []byte(`{"body":"Hello"}`))
+
+	// A signed request is verified and its body can be read again
+	body, err := VerifyRequest(req, secret, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"body":"Hello"}`, string(body))
+	again, _ := io.ReadAll(req.Body)
+	assert.Equal(t, body, again)
+
+	// A request signed with another secret is rejected
+	req = httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(`{"body":"Hello"}`))
+	SignRequest(req, []byte("other"), time.Now(), []byte(`{"body":"Hello"}`))
+	_, err = VerifyRequest(req, secret, 0)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}